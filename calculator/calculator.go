@@ -8,6 +8,11 @@ import (
 
 type Calculator interface {
 	Reset() Calculator
+	// SetMode sets the Mode of the calculator, replacing any existing Mode.
+	// Unlike the stack the mode is not changed by Reset.
+	SetMode(mode Mode) Calculator
+	// Mode returns the current Mode
+	Mode() Mode
 	// Push a value onto the stack
 	Push(v interface{}) Calculator
 	// Pop a value from the stack. Return an error if the stack is empty
@@ -64,6 +69,9 @@ type Calculator interface {
 	// Dump returns the current stack as a string.
 	// Used for debugging
 	Dump() string
+	// Stack returns a copy of the values on the stack of the current calculation, the top value last.
+	// Used for debugging
+	Stack() []interface{}
 }
 
 type Task func() error
@@ -87,6 +95,7 @@ type state struct {
 
 type calculator struct {
 	state
+	mode Mode
 }
 
 func (c *calculator) Reset() Calculator {
@@ -94,6 +103,15 @@ func (c *calculator) Reset() Calculator {
 	return c
 }
 
+func (c *calculator) SetMode(mode Mode) Calculator {
+	c.mode = mode
+	return c
+}
+
+func (c *calculator) Mode() Mode {
+	return c.mode
+}
+
 func (c *calculator) Push(v interface{}) Calculator {
 	c.stack = append(c.stack, v)
 	return c
@@ -175,18 +193,33 @@ func (c *calculator) Drop() error {
 	return err
 }
 
-func (c *calculator) Op1(op string) error {
-	operation, exists := monoOperations[op]
-	if !exists {
-		return fmt.Errorf("operation %q undefined", op)
-	}
-
+func (c *calculator) Op1(op string) (err error) {
 	a, err := c.Pop()
 	if err != nil {
 		return err
 	}
 
-	v, err := operation.MonoCalculate(a)
+	// Operations should return errors but in case one doesn't, e.g. a go method, don't panic
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = fmt.Errorf("operation \"%s %v\" failed: %v", op, a, err1)
+		}
+	}()
+
+	var v interface{}
+	if f, ok := overloads.monoOp(op, a); ok {
+		v, err = f()
+	} else if operation, exists := monoOperations[op]; exists {
+		v, err = operation.MonoCalculate(a)
+		if err == nil && c.mode.Is(CheckOverflow) {
+			err = checkMonoOverflow(op, a, v)
+		}
+		if err == nil && c.mode.Is(StrictFloat) {
+			err = checkFloat(op, a, nil, v)
+		}
+	} else {
+		return fmt.Errorf("operation %q undefined", op)
+	}
 	if err != nil {
 		if errors.Is(err, invalidOperation) {
 			return fmt.Errorf("operation \"%s %T\" unsupported", op, a)
@@ -198,18 +231,33 @@ func (c *calculator) Op1(op string) error {
 	return nil
 }
 
-func (c *calculator) Op2(op string) error {
-	operation, exists := biOperations[op]
-	if !exists {
-		return fmt.Errorf("operation %q undefined", op)
-	}
-
+func (c *calculator) Op2(op string) (err error) {
 	a, b, err := c.Pop2()
 	if err != nil {
 		return err
 	}
 
-	v, err := operation.BiCalculate(a, b)
+	// Operations should return errors but in case one doesn't, e.g. a go method, don't panic
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = fmt.Errorf("operation \"%v %s %v\" failed: %v", a, op, b, err1)
+		}
+	}()
+
+	var v interface{}
+	if f, ok := overloads.biOp(op, a, b); ok {
+		v, err = f()
+	} else if operation, exists := biOperations[op]; exists {
+		v, err = operation.BiCalculate(a, b)
+		if err == nil && c.mode.Is(CheckOverflow) {
+			err = checkOverflow(op, a, b, v)
+		}
+		if err == nil && c.mode.Is(StrictFloat) {
+			err = checkFloat(op, a, b, v)
+		}
+	} else {
+		return fmt.Errorf("operation %q undefined", op)
+	}
 	if err != nil {
 		if errors.Is(err, invalidOperation) {
 			return fmt.Errorf("operation \"%T %s %T\" unsupported", a, op, b)
@@ -257,6 +305,10 @@ func (c *calculator) Exec(t Task) error {
 	return err
 }
 
+func (c *calculator) Stack() []interface{} {
+	return append([]interface{}{}, c.stack...)
+}
+
 func (c *calculator) Dump() string {
 	var a []string
 	for _, e := range c.stack {
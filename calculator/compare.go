@@ -0,0 +1,353 @@
+package calculator
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Comparable is implemented by values which define their own ordering.
+//
+// Compare returns a negative number if the value is less than b, 0 if they are equal
+// or a positive number if it's greater than b.
+type Comparable interface {
+	Compare(b interface{}) (int, error)
+}
+
+var (
+	boolType = reflect.TypeOf(false)
+	intType  = reflect.TypeOf(0)
+)
+
+// Equals returns true if a and b are equal.
+//
+// null is only equal to null, or a nil map, slice or pointer.
+//
+// If a implements Comparable, or has an Equal(T) bool or Compare(T) int method like time.Time,
+// and b is of type T, then that method is used.
+//
+// Slices, arrays, maps, structs and pointers to them are compared deeply, element by element.
+//
+// Anything else is compared as an int, float, string or bool.
+func Equals(a, b interface{}) (bool, error) {
+	a, b = GetValue(a), GetValue(b)
+
+	aNil, bNil := IsNil(a), IsNil(b)
+	if aNil || bNil {
+		return aNil && bNil, nil
+	}
+
+	if isBasic(a) && isBasic(b) {
+		return scalarBool(equality, a, b)
+	}
+
+	if eq, ok, err := methodEquals(a, b); ok {
+		return eq, err
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isList(av) && isList(bv):
+		if av.Len() != bv.Len() {
+			return false, nil
+		}
+		for i := 0; i < av.Len(); i++ {
+			if eq, err := Equals(av.Index(i).Interface(), bv.Index(i).Interface()); err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case av.Kind() == reflect.Map && bv.Kind() == reflect.Map:
+		if av.Len() != bv.Len() {
+			return false, nil
+		}
+		for _, k := range av.MapKeys() {
+			be, ok := mapIndex(bv, k.Interface())
+			if !ok {
+				return false, nil
+			}
+			if eq, err := Equals(av.MapIndex(k).Interface(), be.Interface()); err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case av.Kind() == reflect.Struct && bv.Kind() == reflect.Struct:
+		return structEquals(av, bv)
+
+	case av.Kind() == reflect.Pointer && bv.Kind() == reflect.Pointer:
+		if av.Pointer() == bv.Pointer() {
+			return true, nil
+		}
+		return Equals(av.Elem().Interface(), bv.Elem().Interface())
+
+	case isContainer(av) || isContainer(bv):
+		// Different types of collection, or a collection and a simple value
+		return false, nil
+	}
+
+	return scalarBool(equality, a, b)
+}
+
+// structEquals compares two structs of the same type field by field.
+// Structs of different types are never equal.
+func structEquals(av, bv reflect.Value) (bool, error) {
+	t := av.Type()
+	if t != bv.Type() {
+		return false, nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		// Unexported fields cannot be accessed so fall back to reflect.DeepEqual
+		if !t.Field(i).IsExported() {
+			return reflect.DeepEqual(av.Interface(), bv.Interface()), nil
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if eq, err := Equals(av.Field(i).Interface(), bv.Field(i).Interface()); err != nil || !eq {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Compare returns a negative number if a is less than b, 0 if they are equal,
+// or a positive number if a is greater than b.
+//
+// If a implements Comparable, or has a Compare(T) int method like time.Time, and b is of type T,
+// then that method is used. Otherwise, a and b are compared as an int, float or string.
+//
+// null cannot be compared.
+func Compare(a, b interface{}) (int, error) {
+	a, b = GetValue(a), GetValue(b)
+
+	if IsNil(a) || IsNil(b) {
+		return 0, invalidOperation
+	}
+
+	if !(isBasic(a) && isBasic(b)) {
+		if c, ok, err := methodCompare(a, b); ok {
+			return c, err
+		}
+	}
+
+	lt, err := scalarBool(lessThan, a, b)
+	if err != nil {
+		return 0, err
+	}
+	if lt {
+		return -1, nil
+	}
+
+	gt, err := scalarBool(greaterThan, a, b)
+	switch {
+	case err != nil:
+		return 0, err
+	case gt:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// IsNil returns true if v is nil, or a nil map, slice, pointer, channel, func or interface.
+func IsNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// In returns true if a is within b.
+//
+// For a slice or array this is true if any element Equals a.
+// For a map this is true if a is one of its keys.
+// For a string this is true if a is a substring.
+// If b is null then this returns false.
+func In(a, b interface{}) (bool, error) {
+	a, b = GetValue(a), GetValue(b)
+	if IsNil(b) {
+		return false, nil
+	}
+
+	bv := reflect.Indirect(reflect.ValueOf(b))
+	switch bv.Kind() {
+	case reflect.String:
+		s, err := GetString(a)
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(bv.String(), s), nil
+
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < bv.Len(); i++ {
+			if eq, err := Equals(a, bv.Index(i).Interface()); err == nil && eq {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case reflect.Map:
+		_, ok := mapIndex(bv, a)
+		return ok, nil
+
+	default:
+		return false, invalidOperation
+	}
+}
+
+// mapIndex looks up key in a map.
+// If key is not of the map's key type then it is converted, and if that's not possible
+// then each key is tested with Equals.
+func mapIndex(m reflect.Value, key interface{}) (reflect.Value, bool) {
+	kt := m.Type().Key()
+
+	if key != nil {
+		if k, err := Cast(reflect.ValueOf(key), kt); err == nil && k.Type().AssignableTo(kt) {
+			v := m.MapIndex(k)
+			return v, v.IsValid()
+		}
+	}
+
+	it := m.MapRange()
+	for it.Next() {
+		if eq, err := Equals(key, it.Key().Interface()); err == nil && eq {
+			return it.Value(), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// methodEquals uses Comparable, or an Equal(T) bool or Compare(T) int method on a.
+// The returned bool is false if a has no suitable method.
+func methodEquals(a, b interface{}) (bool, bool, error) {
+	if m, ok := compareMethod(a, b, "Equal", boolType); ok {
+		return m.Call([]reflect.Value{reflect.ValueOf(b)})[0].Bool(), true, nil
+	}
+
+	c, ok, err := methodCompare(a, b)
+	return c == 0, ok, err
+}
+
+// methodCompare uses Comparable or a Compare(T) int method on a.
+// The returned bool is false if a has no suitable method.
+func methodCompare(a, b interface{}) (int, bool, error) {
+	if c, ok := a.(Comparable); ok {
+		r, err := c.Compare(b)
+		return r, true, err
+	}
+
+	if m, ok := compareMethod(a, b, "Compare", intType); ok {
+		return int(m.Call([]reflect.Value{reflect.ValueOf(b)})[0].Int()), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// compareMethod returns the named method of a if it has the signature func(T) R where b is a T
+func compareMethod(a, b interface{}, name string, r reflect.Type) (reflect.Value, bool) {
+	m := reflect.ValueOf(a).MethodByName(name)
+	if !m.IsValid() {
+		return reflect.Value{}, false
+	}
+
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.NumOut() != 1 || mt.Out(0) != r || !reflect.TypeOf(b).AssignableTo(mt.In(0)) {
+		return reflect.Value{}, false
+	}
+
+	return m, true
+}
+
+// scalarBool performs a BiOpDef which returns a bool
+func scalarBool(op *BiOpDef, a, b interface{}) (bool, error) {
+	r, err := op.BiCalculate(a, b)
+	if err != nil {
+		return false, err
+	}
+	return GetBool(r)
+}
+
+// isBasic returns true if v is a bool, number or string
+func isBasic(v interface{}) bool {
+	switch v.(type) {
+	case *big.Int, *big.Rat, *big.Float:
+		return !IsNil(v)
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// isContainer returns true for a slice, array or map.
+// Structs are not included as they may be convertible to a string, e.g. time.Time
+func isContainer(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// compareOp implements the ordering operators.
+// Basic values use the BiOpDef whilst anything else uses Compare.
+type compareOp struct {
+	scalar *BiOpDef
+	test   func(c int) bool
+}
+
+func (op *compareOp) BiCalculate(a, b interface{}) (interface{}, error) {
+	if isBasic(GetValue(a)) && isBasic(GetValue(b)) {
+		return op.scalar.BiCalculate(a, b)
+	}
+
+	c, err := Compare(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return op.test(c), nil
+}
+
+// equalsOp implements == and !=
+type equalsOp struct {
+	not bool
+}
+
+func (op *equalsOp) BiCalculate(a, b interface{}) (interface{}, error) {
+	eq, err := Equals(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return eq != op.not, nil
+}
+
+// inOp implements the in operator
+type inOp struct{}
+
+func (op *inOp) BiCalculate(a, b interface{}) (interface{}, error) {
+	return In(a, b)
+}
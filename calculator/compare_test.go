@@ -0,0 +1,144 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+)
+
+// version is a Comparable which orders by its numeric value
+type version int
+
+func (v version) Compare(b interface{}) (int, error) {
+	i, err := GetInt(b)
+	return int(v) - i, err
+}
+
+type point struct {
+	X, Y int
+}
+
+func TestEquals(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{name: "int", a: 1, b: 1, want: true},
+		{name: "int float", a: 1, b: 1.0, want: true},
+		{name: "int string", a: 1, b: "1", want: true},
+		{name: "nil nil", a: nil, b: nil, want: true},
+		{name: "nil int", a: nil, b: 0, want: false},
+		{name: "int nil", a: 0, b: nil, want: false},
+		{name: "nil slice", a: []int(nil), b: nil, want: true},
+		{name: "empty slice nil", a: []int{}, b: nil, want: false},
+		{name: "slice", a: []int{1, 2}, b: []int{1, 2}, want: true},
+		{name: "slice types", a: []int{1, 2}, b: []interface{}{1, 2.0}, want: true},
+		{name: "slice array", a: []int{1, 2}, b: [2]int{1, 2}, want: true},
+		{name: "slice differ", a: []int{1, 2}, b: []int{2, 1}, want: false},
+		{name: "slice length", a: []int{1, 2}, b: []int{1}, want: false},
+		{name: "slice int", a: []int{1}, b: 1, want: false},
+		{name: "nested", a: [][]int{{1}, {2, 3}}, b: [][]int{{1}, {2, 3}}, want: true},
+		{name: "map", a: map[string]int{"a": 1}, b: map[string]interface{}{"a": 1}, want: true},
+		{name: "map value", a: map[string]int{"a": 1}, b: map[string]int{"a": 2}, want: false},
+		{name: "map key", a: map[string]int{"a": 1}, b: map[string]int{"b": 1}, want: false},
+		{name: "map length", a: map[string]int{"a": 1}, b: map[string]int{"a": 1, "b": 2}, want: false},
+		{name: "struct", a: point{1, 2}, b: point{1, 2}, want: true},
+		{name: "struct differ", a: point{1, 2}, b: point{2, 1}, want: false},
+		{name: "struct pointer", a: &point{1, 2}, b: &point{1, 2}, want: true},
+		{name: "time", a: now, b: now.In(time.UTC), want: true},
+		{name: "time differ", a: now, b: now.Add(time.Second), want: false},
+		{name: "comparable", a: version(3), b: 3, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Equals(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Equals(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		a, b    interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "int lt", a: 1, b: 2, want: -1},
+		{name: "int eq", a: 2, b: 2, want: 0},
+		{name: "int gt", a: 3, b: 2, want: 1},
+		{name: "float int", a: 1.5, b: 2, want: -1},
+		{name: "string", a: "b", b: "a", want: 1},
+		{name: "time lt", a: now, b: now.Add(time.Hour), want: -1},
+		{name: "time gt", a: now.Add(time.Hour), b: now, want: 1},
+		{name: "time eq", a: now, b: now, want: 0},
+		{name: "comparable", a: version(2), b: 5, want: -1},
+		{name: "nil", a: nil, b: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compare(tt.a, tt.b)
+			switch {
+			case err != nil:
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+			case tt.wantErr:
+				t.Errorf("Compare() passed but wanted error")
+			case sign(got) != tt.want:
+				t.Errorf("Compare(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIn(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{name: "slice", a: 2, b: []int{1, 2, 3}, want: true},
+		{name: "slice missing", a: 4, b: []int{1, 2, 3}, want: false},
+		{name: "slice mixed", a: "b", b: []interface{}{1, "b"}, want: true},
+		{name: "slice of slices", a: []int{2}, b: [][]int{{1}, {2}}, want: true},
+		{name: "map", a: "a", b: map[string]int{"a": 1}, want: true},
+		{name: "map missing", a: "b", b: map[string]int{"a": 1}, want: false},
+		{name: "map value", a: 1, b: map[string]int{"a": 1}, want: false},
+		{name: "map int key", a: 1.0, b: map[int]string{1: "a"}, want: true},
+		{name: "string", a: "ell", b: "hello", want: true},
+		{name: "string missing", a: "x", b: "hello", want: false},
+		{name: "nil", a: 1, b: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := In(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("In(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	default:
+		return 0
+	}
+}
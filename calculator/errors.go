@@ -3,9 +3,23 @@ package calculator
 import "errors"
 
 var (
-	stackEmpty = errors.New("stack empty")
+	stackEmpty      = errors.New("stack empty")
+	integerOverflow = errors.New("integer overflow")
+	divisionByZero  = errors.New("division by zero")
+	negativeShift   = errors.New("negative shift amount")
+	notANumber      = errors.New("result is NaN")
 )
 
 func IsStackEmpty(err error) bool {
 	return err == stackEmpty
 }
+
+// IsOverflow returns true if the error is due to integer overflow when CheckOverflow is enabled
+func IsOverflow(err error) bool {
+	return errors.Is(err, integerOverflow)
+}
+
+// IsDivisionByZero returns true if the error is due to dividing by zero
+func IsDivisionByZero(err error) bool {
+	return errors.Is(err, divisionByZero)
+}
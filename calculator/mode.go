@@ -0,0 +1,138 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// Mode enables optional behaviour of a Calculator.
+// Modes are flags so more than one can be enabled, e.g. CheckOverflow|SomeOtherMode
+type Mode uint8
+
+const (
+	// CheckOverflow causes integer arithmetic to fail with an error when the result overflows,
+	// instead of wrapping around as it does in go.
+	//
+	// Only int, int64 and uint64 results are checked as big.Int cannot overflow.
+	CheckOverflow Mode = 1 << iota
+
+	// StrictFloat causes float and complex operations to fail with an error instead of returning NaN,
+	// and their division by zero to fail instead of returning an infinity.
+	StrictFloat
+)
+
+// Is returns true if all the flags in m are set
+func (mode Mode) Is(m Mode) bool {
+	return mode&m == m
+}
+
+// exactOps calculate the exact result of an integer operation which can overflow.
+// They return nil if the result would not be an integer.
+var exactOps = map[string]func(a, b *big.Int) *big.Int{
+	"+": func(a, b *big.Int) *big.Int { return new(big.Int).Add(a, b) },
+	"-": func(a, b *big.Int) *big.Int { return new(big.Int).Sub(a, b) },
+	"*": func(a, b *big.Int) *big.Int { return new(big.Int).Mul(a, b) },
+	"/": func(a, b *big.Int) *big.Int {
+		if b.Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Quo(a, b)
+	},
+	"**": func(a, b *big.Int) *big.Int {
+		switch {
+		case b.Sign() < 0:
+			return nil
+		case a.CmpAbs(big.NewInt(1)) > 0 && b.Cmp(big.NewInt(64)) > 0:
+			// Too large for any integer type so don't bother calculating it
+			return tooLarge
+		default:
+			return new(big.Int).Exp(a, b, nil)
+		}
+	},
+	"<<": func(a, b *big.Int) *big.Int {
+		switch {
+		case b.Sign() < 0:
+			return nil
+		case a.Sign() != 0 && b.Cmp(big.NewInt(64)) > 0:
+			return tooLarge
+		default:
+			return new(big.Int).Lsh(a, uint(b.Uint64()))
+		}
+	},
+}
+
+// tooLarge is a value which cannot be held in any of the go integer types
+var tooLarge = new(big.Int).Lsh(big.NewInt(1), 64)
+
+// checkOverflow returns an error if v, the result of a op b, has overflowed
+func checkOverflow(op string, a, b, v interface{}) error {
+	f, exists := exactOps[op]
+	if !exists || !isFixedInteger(v) || !isFixedInteger(a) || !isFixedInteger(b) {
+		return nil
+	}
+
+	if exact := f(toBigInt(a), toBigInt(b)); exact != nil && exact.Cmp(toBigInt(v)) != 0 {
+		return fmt.Errorf("%w: %v %s %v", integerOverflow, a, op, b)
+	}
+	return nil
+}
+
+// checkMonoOverflow returns an error if v, the result of op a, has overflowed
+func checkMonoOverflow(op string, a, v interface{}) error {
+	if op != "-" || !isFixedInteger(v) || !isFixedInteger(a) {
+		return nil
+	}
+
+	if new(big.Int).Neg(toBigInt(a)).Cmp(toBigInt(v)) != 0 {
+		return fmt.Errorf("%w: %s%v", integerOverflow, op, a)
+	}
+	return nil
+}
+
+// isFixedInteger returns true if v is an integer of fixed size, i.e. not a big.Int
+func isFixedInteger(v interface{}) bool {
+	k, ok := numericKind(GetValue(v))
+	return ok && k.isInteger() && k != kindBigInt
+}
+
+// checkFloat returns an error if a float operation divides by zero or returns NaN
+func checkFloat(op string, a, b, v interface{}) error {
+	if (op == "/" || op == "%") && (isFloat(a) || isFloat(b)) && isNumberZero(b) {
+		return divisionByZero
+	}
+
+	if isNaN(v) {
+		if b == nil {
+			return fmt.Errorf("%w: %s%v", notANumber, op, a)
+		}
+		return fmt.Errorf("%w: %v %s %v", notANumber, a, op, b)
+	}
+	return nil
+}
+
+// isNumberZero returns true if v is a number equal to 0
+func isNumberZero(v interface{}) bool {
+	v = GetValue(v)
+	_, ok := numericKind(v)
+	return ok && isZero(v)
+}
+
+// isFloat returns true if v is a float, big.Float or complex number
+func isFloat(v interface{}) bool {
+	k, ok := numericKind(GetValue(v))
+	return ok && (k == kindFloat || k == kindBigFloat || k == kindComplex)
+}
+
+// isNaN returns true if v is a float or complex number which is NaN
+func isNaN(v interface{}) bool {
+	switch f := v.(type) {
+	case float64:
+		return math.IsNaN(f)
+	case complex128:
+		return cmplx.IsNaN(f)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,332 @@
+package calculator
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// numKind is the kind of number an operation is performed as.
+//
+// When two numbers of different kinds are used in an operation, both are promoted to
+// the higher kind so no precision is lost, e.g. int + int64 is performed as int64.
+type numKind int
+
+const (
+	kindInt      numKind = iota // int, or any smaller integer type
+	kindInt64                   // int64, or uint32 so it does not overflow on 32-bit platforms
+	kindUint64                  // uint64, uint or uintptr
+	kindFloat                   // float64 or float32
+	kindBigInt                  // *big.Int
+	kindBigRat                  // *big.Rat
+	kindBigFloat                // *big.Float
+	kindComplex                 // complex128 or complex64
+)
+
+var (
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigRatType   = reflect.TypeOf((*big.Rat)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+)
+
+// numericKind returns the numKind of v, false if it's not a number
+func numericKind(v interface{}) (numKind, bool) {
+	switch v.(type) {
+	case int:
+		return kindInt, true
+	case int64:
+		return kindInt64, true
+	case uint64:
+		return kindUint64, true
+	case float64:
+		return kindFloat, true
+	case complex128:
+		return kindComplex, true
+	case *big.Int:
+		return kindBigInt, v.(*big.Int) != nil
+	case *big.Rat:
+		return kindBigRat, v.(*big.Rat) != nil
+	case *big.Float:
+		return kindBigFloat, v.(*big.Float) != nil
+	case nil, bool, string:
+		return 0, false
+	case Int:
+		return kindInt, true
+	case Float:
+		return kindFloat, true
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return kindInt, true
+	case reflect.Int64, reflect.Uint32:
+		return kindInt64, true
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return kindUint64, true
+	case reflect.Float32, reflect.Float64:
+		return kindFloat, true
+	case reflect.Complex64, reflect.Complex128:
+		return kindComplex, true
+	default:
+		return 0, false
+	}
+}
+
+// IsNumber returns true if v is a number, including math/big and complex numbers
+func IsNumber(v interface{}) bool {
+	_, ok := numericKind(GetValue(v))
+	return ok
+}
+
+// isInteger returns true if the kind is an integer
+func (k numKind) isInteger() bool {
+	return k == kindInt || k == kindInt64 || k == kindUint64 || k == kindBigInt
+}
+
+// promote returns the kind an operation between a of kind ka and b of kind kb is performed as
+func promote(ka, kb numKind, a, b interface{}) numKind {
+	if ka == kb {
+		return ka
+	}
+
+	lo, hi := ka, kb
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+
+	switch {
+	// Signed and unsigned, use uint64 unless the signed value is negative
+	case hi == kindUint64:
+		if toInt64(a) < 0 && ka != kindUint64 || toInt64(b) < 0 && kb != kindUint64 {
+			return kindBigInt
+		}
+		return kindUint64
+
+	// A float can be represented exactly as a Rat but not as an Int
+	case lo == kindFloat && hi == kindBigInt:
+		return kindBigRat
+
+	default:
+		return hi
+	}
+}
+
+// toKind converts a number to the go type representing the kind.
+// The number must be of a kind less than or equal to k.
+func toKind(v interface{}, k numKind) interface{} {
+	switch k {
+	case kindInt:
+		return toInt(v)
+	case kindInt64:
+		return toInt64(v)
+	case kindUint64:
+		return toUint64(v)
+	case kindFloat:
+		return toFloat(v)
+	case kindBigInt:
+		return toBigInt(v)
+	case kindBigRat:
+		return toBigRat(v)
+	case kindBigFloat:
+		return toBigFloat(v)
+	default:
+		return toComplex(v)
+	}
+}
+
+func toInt(v interface{}) int {
+	switch i := v.(type) {
+	case int:
+		return i
+	case Int:
+		return i.Int()
+	}
+	return int(toInt64(v))
+}
+
+func toInt64(v interface{}) int64 {
+	switch i := v.(type) {
+	case int:
+		return int64(i)
+	case int64:
+		return i
+	case Int:
+		return int64(i.Int())
+	case *big.Int:
+		return i.Int64()
+	case *big.Rat:
+		n, _ := new(big.Float).SetRat(i).Int64()
+		return n
+	case *big.Float:
+		n, _ := i.Int64()
+		return n
+	}
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return rv.Int()
+	case rv.CanUint():
+		return int64(rv.Uint())
+	case rv.CanFloat():
+		return int64(rv.Float())
+	case rv.CanComplex():
+		return int64(real(rv.Complex()))
+	default:
+		return 0
+	}
+}
+
+func toUint64(v interface{}) uint64 {
+	switch i := v.(type) {
+	case uint64:
+		return i
+	case *big.Int:
+		return i.Uint64()
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.CanUint() {
+		return rv.Uint()
+	}
+	return uint64(toInt64(v))
+}
+
+func toFloat(v interface{}) float64 {
+	switch f := v.(type) {
+	case float64:
+		return f
+	case Float:
+		return f.Float()
+	case *big.Int:
+		r, _ := new(big.Float).SetInt(f).Float64()
+		return r
+	case *big.Rat:
+		r, _ := f.Float64()
+		return r
+	case *big.Float:
+		r, _ := f.Float64()
+		return r
+	}
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanFloat():
+		return rv.Float()
+	case rv.CanUint():
+		return float64(rv.Uint())
+	case rv.CanComplex():
+		return real(rv.Complex())
+	default:
+		return float64(toInt64(v))
+	}
+}
+
+// toComplex returns a number as a complex128. Anything other than a complex number has no imaginary part.
+func toComplex(v interface{}) complex128 {
+	if c, ok := v.(complex128); ok {
+		return c
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.CanComplex() {
+		return rv.Complex()
+	}
+	return complex(toFloat(v), 0)
+}
+
+func toBigInt(v interface{}) *big.Int {
+	switch i := v.(type) {
+	case *big.Int:
+		return i
+	case uint64:
+		return new(big.Int).SetUint64(i)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.CanUint() {
+		return new(big.Int).SetUint64(rv.Uint())
+	}
+	return big.NewInt(toInt64(v))
+}
+
+func toBigRat(v interface{}) *big.Rat {
+	switch r := v.(type) {
+	case *big.Rat:
+		return r
+	case *big.Int:
+		return new(big.Rat).SetInt(r)
+	case float64:
+		if math.IsInf(r, 0) || math.IsNaN(r) {
+			return new(big.Rat)
+		}
+		return new(big.Rat).SetFloat64(r)
+	}
+
+	if k, _ := numericKind(v); k == kindFloat {
+		return toBigRat(toFloat(v))
+	}
+	return new(big.Rat).SetInt(toBigInt(v))
+}
+
+func toBigFloat(v interface{}) *big.Float {
+	switch f := v.(type) {
+	case *big.Float:
+		return f
+	case *big.Int:
+		return new(big.Float).SetInt(f)
+	case *big.Rat:
+		return new(big.Float).SetRat(f)
+	}
+
+	switch k, _ := numericKind(v); k {
+	case kindFloat:
+		return big.NewFloat(toFloat(v))
+	case kindUint64:
+		return new(big.Float).SetUint64(toUint64(v))
+	default:
+		return new(big.Float).SetInt64(toInt64(v))
+	}
+}
+
+// fitsInt returns true if v is an integer which can be held in an int without loss
+func fitsInt(v interface{}) bool {
+	switch k, _ := numericKind(v); k {
+	case kindInt:
+		return true
+	case kindInt64:
+		i := toInt64(v)
+		return i >= math.MinInt && i <= math.MaxInt
+	case kindUint64:
+		return toUint64(v) <= math.MaxInt
+	case kindBigInt:
+		i := toBigInt(v)
+		return i.IsInt64() && fitsInt(i.Int64())
+	default:
+		return false
+	}
+}
+
+// isZero returns true if a number is 0
+func isZero(v interface{}) bool {
+	switch n := v.(type) {
+	case *big.Int:
+		return n.Sign() == 0
+	case *big.Rat:
+		return n.Sign() == 0
+	case *big.Float:
+		return n.Sign() == 0
+	}
+
+	k, _ := numericKind(v)
+	switch k {
+	case kindComplex:
+		return toComplex(v) == 0
+	case kindFloat:
+		return toFloat(v) == 0
+	case kindUint64:
+		return toUint64(v) == 0
+	default:
+		return toInt64(v) == 0
+	}
+}
@@ -0,0 +1,184 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bigInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 10)
+	return i
+}
+
+func TestNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    interface{}
+		op      string
+		mode    Mode
+		want    interface{}
+		wantErr string
+	}{
+		// Integer kinds are preserved and promoted to the larger kind
+		{name: "int", a: 1, op: "+", b: 2, want: 3},
+		{name: "int8", a: int8(100), op: "+", b: int8(100), want: 200},
+		{name: "int64", a: int64(math.MaxInt64), op: "-", b: int64(1), want: int64(math.MaxInt64 - 1)},
+		{name: "int int64", a: 1, op: "+", b: int64(2), want: int64(3)},
+		{name: "uint32", a: uint32(math.MaxUint32), op: "+", b: 1, want: int64(math.MaxUint32 + 1)},
+		{name: "uint64", a: uint64(math.MaxUint64), op: "-", b: 1, want: uint64(math.MaxUint64 - 1)},
+		{name: "uint64 large", a: uint64(math.MaxUint64), op: "/", b: uint64(3), want: uint64(math.MaxUint64 / 3)},
+		{name: "uint64 wraps", a: uint64(math.MaxUint64), op: "+", b: 1, want: uint64(0)},
+		{name: "uint64 negative", a: uint64(1), op: "-", b: -2, want: big.NewInt(3)},
+		{name: "uint64 compare", a: uint64(math.MaxUint64), op: ">", b: math.MaxInt64, want: true},
+		{name: "uint64 compare negative", a: uint64(1), op: ">", b: -1, want: true},
+		{name: "uint64 equal", a: uint64(math.MaxUint64), op: "==", b: -1, want: false},
+		{name: "duration", a: time.Hour, op: "/", b: time.Minute, want: int64(60)},
+		{name: "int float", a: 1, op: "+", b: 0.5, want: 1.5},
+		{name: "uint64 shift", a: uint64(1), op: "<<", b: 63, want: uint64(1 << 63)},
+		{name: "int64 not", a: int64(0), op: "^", want: int64(-1)},
+		{name: "uint64 neg", a: uint64(1), op: "-", want: uint64(math.MaxUint64)},
+
+		// Integer exponentiation
+		{name: "pow int", a: 2, op: "**", b: 10, want: 1024},
+		{name: "pow zero", a: 5, op: "**", b: 0, want: 1},
+		{name: "pow negative base", a: -3, op: "**", b: 3, want: -27},
+		{name: "pow negative exponent", a: 2, op: "**", b: -1, want: 0.5},
+		{name: "pow float", a: 2, op: "**", b: 0.5, want: math.Sqrt2},
+		{name: "pow int64", a: int64(2), op: "**", b: 62, want: int64(1 << 62)},
+		{name: "pow uint64", a: uint64(2), op: "**", b: 63, want: uint64(1 << 63)},
+
+		// math/big
+		{name: "big int", a: bigInt("123456789012345678901234567890"), op: "*", b: 2, want: bigInt("246913578024691357802469135780")},
+		{name: "big int pow", a: big.NewInt(2), op: "**", b: 100, want: bigInt("1267650600228229401496703205376")},
+		{name: "big int pow negative", a: big.NewInt(2), op: "**", b: -2, want: big.NewRat(1, 4)},
+		{name: "big int div", a: big.NewInt(-7), op: "/", b: 2, want: big.NewInt(-3)},
+		{name: "big int mod", a: big.NewInt(-7), op: "%", b: 2, want: big.NewInt(-1)},
+		{name: "big int shift", a: big.NewInt(1), op: "<<", b: 100, want: bigInt("1267650600228229401496703205376")},
+		{name: "big int and", a: big.NewInt(12), op: "&", b: 10, want: big.NewInt(8)},
+		{name: "big int neg", a: big.NewInt(12), op: "-", want: big.NewInt(-12)},
+		{name: "big int not", a: big.NewInt(12), op: "!", want: false},
+		{name: "big int compare", a: bigInt("123456789012345678901234567890"), op: ">", b: math.MaxInt64, want: true},
+		{name: "big int equal", a: big.NewInt(12), op: "==", b: 12, want: true},
+		{name: "big int float", a: big.NewInt(1), op: "+", b: 0.5, want: big.NewRat(3, 2)},
+		{name: "big rat", a: big.NewRat(1, 3), op: "+", b: big.NewRat(1, 6), want: big.NewRat(1, 2)},
+		{name: "big rat int", a: big.NewRat(1, 3), op: "*", b: 3, want: big.NewRat(1, 1)},
+		{name: "big rat equal", a: big.NewRat(1, 3), op: "==", b: big.NewRat(2, 6), want: true},
+		{name: "big rat pow", a: big.NewRat(2, 3), op: "**", b: 2, want: big.NewRat(4, 9)},
+		{name: "big rat pow negative", a: big.NewRat(2, 3), op: "**", b: -2, want: big.NewRat(9, 4)},
+		{name: "big rat pow zero", a: big.NewRat(0, 1), op: "**", b: -1, wantErr: "division by zero"},
+		{name: "big rat mod", a: big.NewRat(1, 3), op: "%", b: 1, wantErr: "unsupported"},
+		{name: "big float", a: big.NewFloat(1.5), op: "*", b: 2, want: big.NewFloat(3)},
+		{name: "big float rat", a: big.NewFloat(0.5), op: "+", b: big.NewRat(1, 4), want: big.NewFloat(0.75)},
+		{name: "big float pow", a: big.NewFloat(2), op: "**", b: -2, want: big.NewFloat(0.25)},
+		{name: "big float less", a: big.NewFloat(1.5), op: "<", b: 2, want: true},
+		{name: "big string", a: "n=", op: "+", b: big.NewInt(5), want: "n=5"},
+
+		// Complex numbers
+		{name: "complex add", a: complex(1, 2), op: "+", b: complex(3, 4), want: complex(4, 6)},
+		{name: "complex int", a: 3, op: "+", b: complex(0, 4), want: complex(3, 4)},
+		{name: "complex float", a: complex(1, 1), op: "*", b: 0.5, want: complex(0.5, 0.5)},
+		{name: "complex mul", a: complex(0, 1), op: "*", b: complex(0, 1), want: complex(-1, 0)},
+		{name: "complex div", a: complex(-7, 24), op: "/", b: complex(3, 4), want: complex(3, 4)},
+		{name: "complex pow", a: complex(3, 4), op: "**", b: 2, want: complex(-7, 24)},
+		{name: "complex pow negative", a: complex(0, 2), op: "**", b: -1, want: complex(0, -0.5)},
+		{name: "complex64", a: complex64(complex(1, 2)), op: "-", b: 1, want: complex(0, 2)},
+		{name: "complex equal", a: complex(1, 2), op: "==", b: complex(1, 2), want: true},
+		{name: "complex equal real", a: complex(2, 0), op: "==", b: 2, want: true},
+		{name: "complex not equal", a: complex(1, 2), op: "!=", b: complex(1, 3), want: true},
+		{name: "complex less", a: complex(1, 2), op: "<", b: complex(1, 3), wantErr: "unsupported"},
+		{name: "complex mod", a: complex(1, 2), op: "%", b: 2, wantErr: "unsupported"},
+		{name: "complex neg", a: complex(1, 2), op: "-", want: complex(-1, -2)},
+		{name: "complex not", a: complex(0, 0), op: "!", want: true},
+		{name: "complex string", a: "z=", op: "+", b: complex(1, 2), want: "z=(1.000000+2.000000i)"},
+		{name: "complex parse", a: complex(1, 2), op: "+", b: "1+1i", want: complex(2, 3)},
+		{name: "complex strict", a: complex(1, 2), op: "/", b: 0, mode: StrictFloat, wantErr: "division by zero"},
+
+		// Overflow detection
+		{name: "overflow wraps", a: math.MaxInt, op: "+", b: 1, want: math.MinInt},
+		{name: "overflow add", a: math.MaxInt, op: "+", b: 1, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow sub", a: math.MinInt, op: "-", b: 1, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow mul", a: int64(math.MaxInt64), op: "*", b: 2, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow uint64", a: uint64(0), op: "-", b: 1, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow pow", a: 2, op: "**", b: 64, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow pow large", a: 3, op: "**", b: 1000000, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow shift", a: 1, op: "<<", b: 63, mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow neg", a: math.MinInt, op: "-", mode: CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow ok", a: math.MaxInt - 1, op: "+", b: 1, mode: CheckOverflow, want: math.MaxInt},
+		{name: "overflow pow ok", a: 2, op: "**", b: 62, mode: CheckOverflow, want: 1 << 62},
+		{name: "overflow big", a: big.NewInt(math.MaxInt64), op: "+", b: 1, mode: CheckOverflow, want: bigInt("9223372036854775808")},
+		{name: "overflow float", a: math.MaxFloat64, op: "*", b: 2, mode: CheckOverflow, want: math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New().SetMode(tt.mode)
+			c.Push(tt.a)
+
+			var err error
+			if tt.b == nil {
+				err = c.Op1(tt.op)
+			} else {
+				c.Push(tt.b)
+				err = c.Op2(tt.op)
+			}
+
+			switch {
+			case err != nil:
+				if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatal(err)
+				}
+				if tt.wantErr == "integer overflow" && !IsOverflow(err) {
+					t.Errorf("IsOverflow false for %v", err)
+				}
+				return
+			case tt.wantErr != "":
+				t.Fatalf("expected error %q", tt.wantErr)
+			}
+
+			got, err := c.Pop()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Compare as strings as big values with the same value may not be DeepEqual
+			g, w := fmt.Sprintf("%T %v", got, got), fmt.Sprintf("%T %v", tt.want, tt.want)
+			if g != w {
+				t.Errorf("got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestGetBig(t *testing.T) {
+	tests := []struct {
+		name string
+		get  func(v interface{}) (interface{}, error)
+		v    interface{}
+		want string
+	}{
+		{name: "int string", get: func(v interface{}) (interface{}, error) { return GetBigInt(v) }, v: "0x10", want: "16"},
+		{name: "int uint64", get: func(v interface{}) (interface{}, error) { return GetBigInt(v) }, v: uint64(math.MaxUint64), want: "18446744073709551615"},
+		{name: "int float", get: func(v interface{}) (interface{}, error) { return GetBigInt(v) }, v: 2.7, want: "2"},
+		{name: "rat string", get: func(v interface{}) (interface{}, error) { return GetBigRat(v) }, v: "0.25", want: "1/4"},
+		{name: "rat int", get: func(v interface{}) (interface{}, error) { return GetBigRat(v) }, v: 3, want: "3/1"},
+		{name: "float string", get: func(v interface{}) (interface{}, error) { return GetBigFloat(v) }, v: "1.5", want: "1.5"},
+		{name: "uint64", get: func(v interface{}) (interface{}, error) { return GetUint64(v) }, v: "18446744073709551615", want: "18446744073709551615"},
+		{name: "uint64 string", get: func(v interface{}) (interface{}, error) { return GetString(v) }, v: uint64(math.MaxUint64), want: "18446744073709551615"},
+		{name: "int64", get: func(v interface{}) (interface{}, error) { return GetInt64(v) }, v: big.NewInt(math.MinInt64), want: "-9223372036854775808"},
+		{name: "float big", get: func(v interface{}) (interface{}, error) { return GetFloat(v) }, v: big.NewRat(1, 4), want: "0.25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := fmt.Sprint(got); s != tt.want {
+				t.Errorf("got %s want %s", s, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package calculator
 
 import (
 	"errors"
+	"math/big"
 )
 
 // MonoCalculation performs an operation against two values
@@ -15,10 +16,16 @@ type BiCalculation interface {
 }
 
 type biOpCommon struct {
-	intOp    func(a, b int) (interface{}, error)
-	floatOp  func(a, b float64) (interface{}, error)
-	stringOp func(a, b string) (interface{}, error)
-	boolOp   func(a, b bool) (interface{}, error)
+	intOp      func(a, b int) (interface{}, error)
+	int64Op    func(a, b int64) (interface{}, error)
+	uint64Op   func(a, b uint64) (interface{}, error)
+	floatOp    func(a, b float64) (interface{}, error)
+	bigIntOp   func(a, b *big.Int) (interface{}, error)
+	bigRatOp   func(a, b *big.Rat) (interface{}, error)
+	bigFloatOp func(a, b *big.Float) (interface{}, error)
+	complexOp  func(a, b complex128) (interface{}, error)
+	stringOp   func(a, b string) (interface{}, error)
+	boolOp     func(a, b bool) (interface{}, error)
 }
 
 // BiOpDef implements an operation whose behaviour depends on the type
@@ -59,7 +66,46 @@ func (op *BiOpDef) doBool(a bool, b interface{}) (interface{}, error) {
 	return op.boolOp(a, c)
 }
 
+// numeric performs the operation on two numbers once promoted to kind k.
+//
+// If the operation is not defined for that kind then an integer kind falls back to the int
+// operation if both values fit in an int, and any kind below kindBigInt to the float operation.
+func (op *BiOpDef) numeric(k numKind, a, b interface{}) (interface{}, error) {
+	switch {
+	case k == kindInt && op.intOp != nil:
+		return op.intOp(toInt(a), toInt(b))
+	case k == kindInt64 && op.int64Op != nil:
+		return op.int64Op(toInt64(a), toInt64(b))
+	case k == kindUint64 && op.uint64Op != nil:
+		return op.uint64Op(toUint64(a), toUint64(b))
+	case k == kindFloat && op.floatOp != nil:
+		return op.floatOp(toFloat(a), toFloat(b))
+	case k == kindBigInt && op.bigIntOp != nil:
+		return op.bigIntOp(toBigInt(a), toBigInt(b))
+	case k == kindBigRat && op.bigRatOp != nil:
+		return op.bigRatOp(toBigRat(a), toBigRat(b))
+	case k == kindBigFloat && op.bigFloatOp != nil:
+		return op.bigFloatOp(toBigFloat(a), toBigFloat(b))
+	case k == kindComplex && op.complexOp != nil:
+		return op.complexOp(toComplex(a), toComplex(b))
+	case k.isInteger() && op.intOp != nil && fitsInt(a) && fitsInt(b):
+		return op.intOp(toInt(a), toInt(b))
+	case k < kindBigInt && op.floatOp != nil:
+		return op.floatOp(toFloat(a), toFloat(b))
+	default:
+		return nil, invalidOperation
+	}
+}
+
 func (op *BiOpDef) BiCalculate(a0, b0 interface{}) (interface{}, error) {
+	a0, b0 = GetValue(a0), GetValue(b0)
+
+	// Numbers are promoted to the larger kind, so no precision is lost
+	if ka, ok := numericKind(a0); ok {
+		if kb, ok := numericKind(b0); ok {
+			return op.numeric(promote(ka, kb, a0, b0), a0, b0)
+		}
+	}
 
 	// Convert so a and b are the same type
 	a, b, err := Convert(a0, b0)
@@ -67,6 +113,12 @@ func (op *BiOpDef) BiCalculate(a0, b0 interface{}) (interface{}, error) {
 		return nil, err
 	}
 
+	if op.complexOp != nil {
+		if c, ok := a.(complex128); ok {
+			return op.complexOp(c, b.(complex128))
+		}
+	}
+
 	if op.floatOp != nil {
 		if f, ok := GetFloatRaw(a); ok {
 			return op.doFloat(f, b)
@@ -103,7 +155,13 @@ func NewBiOpDef() BiOpDefBuilder {
 
 type BiOpDefBuilder interface {
 	Int(func(a, b int) (interface{}, error)) BiOpDefBuilder
+	Int64(func(a, b int64) (interface{}, error)) BiOpDefBuilder
+	Uint64(func(a, b uint64) (interface{}, error)) BiOpDefBuilder
 	Float(func(a, b float64) (interface{}, error)) BiOpDefBuilder
+	BigInt(func(a, b *big.Int) (interface{}, error)) BiOpDefBuilder
+	BigRat(func(a, b *big.Rat) (interface{}, error)) BiOpDefBuilder
+	BigFloat(func(a, b *big.Float) (interface{}, error)) BiOpDefBuilder
+	Complex(func(a, b complex128) (interface{}, error)) BiOpDefBuilder
 	String(func(a, b string) (interface{}, error)) BiOpDefBuilder
 	Bool(func(a, b bool) (interface{}, error)) BiOpDefBuilder
 	Build() *BiOpDef
@@ -118,11 +176,41 @@ func (b *biOpBuilder) Int(f func(a, b int) (interface{}, error)) BiOpDefBuilder
 	return b
 }
 
+func (b *biOpBuilder) Int64(f func(a, b int64) (interface{}, error)) BiOpDefBuilder {
+	b.int64Op = f
+	return b
+}
+
+func (b *biOpBuilder) Uint64(f func(a, b uint64) (interface{}, error)) BiOpDefBuilder {
+	b.uint64Op = f
+	return b
+}
+
 func (b *biOpBuilder) Float(f func(a, b float64) (interface{}, error)) BiOpDefBuilder {
 	b.floatOp = f
 	return b
 }
 
+func (b *biOpBuilder) BigInt(f func(a, b *big.Int) (interface{}, error)) BiOpDefBuilder {
+	b.bigIntOp = f
+	return b
+}
+
+func (b *biOpBuilder) BigRat(f func(a, b *big.Rat) (interface{}, error)) BiOpDefBuilder {
+	b.bigRatOp = f
+	return b
+}
+
+func (b *biOpBuilder) BigFloat(f func(a, b *big.Float) (interface{}, error)) BiOpDefBuilder {
+	b.bigFloatOp = f
+	return b
+}
+
+func (b *biOpBuilder) Complex(f func(a, b complex128) (interface{}, error)) BiOpDefBuilder {
+	b.complexOp = f
+	return b
+}
+
 func (b *biOpBuilder) String(f func(a, b string) (interface{}, error)) BiOpDefBuilder {
 	b.stringOp = f
 	return b
@@ -144,17 +232,29 @@ func NewMonoOpDef() MonoOpDefBuilder {
 
 type MonoOpDefBuilder interface {
 	Int(func(a int) (interface{}, error)) MonoOpDefBuilder
+	Int64(func(a int64) (interface{}, error)) MonoOpDefBuilder
+	Uint64(func(a uint64) (interface{}, error)) MonoOpDefBuilder
 	Float(func(a float64) (interface{}, error)) MonoOpDefBuilder
+	BigInt(func(a *big.Int) (interface{}, error)) MonoOpDefBuilder
+	BigRat(func(a *big.Rat) (interface{}, error)) MonoOpDefBuilder
+	BigFloat(func(a *big.Float) (interface{}, error)) MonoOpDefBuilder
+	Complex(func(a complex128) (interface{}, error)) MonoOpDefBuilder
 	String(func(a string) (interface{}, error)) MonoOpDefBuilder
 	Bool(func(a bool) (interface{}, error)) MonoOpDefBuilder
 	Build() *MonoOpDef
 }
 
 type monoOpCommon struct {
-	intOp    func(a int) (interface{}, error)
-	floatOp  func(a float64) (interface{}, error)
-	stringOp func(a string) (interface{}, error)
-	boolOp   func(a bool) (interface{}, error)
+	intOp      func(a int) (interface{}, error)
+	int64Op    func(a int64) (interface{}, error)
+	uint64Op   func(a uint64) (interface{}, error)
+	floatOp    func(a float64) (interface{}, error)
+	bigIntOp   func(a *big.Int) (interface{}, error)
+	bigRatOp   func(a *big.Rat) (interface{}, error)
+	bigFloatOp func(a *big.Float) (interface{}, error)
+	complexOp  func(a complex128) (interface{}, error)
+	stringOp   func(a string) (interface{}, error)
+	boolOp     func(a bool) (interface{}, error)
 }
 
 // MonoOpDef implements an operation whose behaviour depends on the type
@@ -163,18 +263,39 @@ type MonoOpDef struct {
 	monoOpCommon
 }
 
-func (op *MonoOpDef) MonoCalculate(a interface{}) (interface{}, error) {
-
-	if op.floatOp != nil {
-		if f, ok := GetFloatRaw(a); ok {
-			return op.floatOp(f)
-		}
+// numeric performs the operation on a number of kind k, falling back as BiOpDef does
+func (op *MonoOpDef) numeric(k numKind, a interface{}) (interface{}, error) {
+	switch {
+	case k == kindInt && op.intOp != nil:
+		return op.intOp(toInt(a))
+	case k == kindInt64 && op.int64Op != nil:
+		return op.int64Op(toInt64(a))
+	case k == kindUint64 && op.uint64Op != nil:
+		return op.uint64Op(toUint64(a))
+	case k == kindFloat && op.floatOp != nil:
+		return op.floatOp(toFloat(a))
+	case k == kindBigInt && op.bigIntOp != nil:
+		return op.bigIntOp(toBigInt(a))
+	case k == kindBigRat && op.bigRatOp != nil:
+		return op.bigRatOp(toBigRat(a))
+	case k == kindBigFloat && op.bigFloatOp != nil:
+		return op.bigFloatOp(toBigFloat(a))
+	case k == kindComplex && op.complexOp != nil:
+		return op.complexOp(toComplex(a))
+	case k.isInteger() && op.intOp != nil && fitsInt(a):
+		return op.intOp(toInt(a))
+	case k < kindBigInt && op.floatOp != nil:
+		return op.floatOp(toFloat(a))
+	default:
+		return nil, invalidOperation
 	}
+}
 
-	if op.intOp != nil {
-		if i, ok := GetIntRaw(a); ok {
-			return op.intOp(i)
-		}
+func (op *MonoOpDef) MonoCalculate(a interface{}) (interface{}, error) {
+	a = GetValue(a)
+
+	if k, ok := numericKind(a); ok {
+		return op.numeric(k, a)
 	}
 
 	if op.stringOp != nil {
@@ -201,11 +322,41 @@ func (b *monoOpBuilder) Int(f func(a int) (interface{}, error)) MonoOpDefBuilder
 	return b
 }
 
+func (b *monoOpBuilder) Int64(f func(a int64) (interface{}, error)) MonoOpDefBuilder {
+	b.int64Op = f
+	return b
+}
+
+func (b *monoOpBuilder) Uint64(f func(a uint64) (interface{}, error)) MonoOpDefBuilder {
+	b.uint64Op = f
+	return b
+}
+
 func (b *monoOpBuilder) Float(f func(a float64) (interface{}, error)) MonoOpDefBuilder {
 	b.floatOp = f
 	return b
 }
 
+func (b *monoOpBuilder) BigInt(f func(a *big.Int) (interface{}, error)) MonoOpDefBuilder {
+	b.bigIntOp = f
+	return b
+}
+
+func (b *monoOpBuilder) BigRat(f func(a *big.Rat) (interface{}, error)) MonoOpDefBuilder {
+	b.bigRatOp = f
+	return b
+}
+
+func (b *monoOpBuilder) BigFloat(f func(a *big.Float) (interface{}, error)) MonoOpDefBuilder {
+	b.bigFloatOp = f
+	return b
+}
+
+func (b *monoOpBuilder) Complex(f func(a complex128) (interface{}, error)) MonoOpDefBuilder {
+	b.complexOp = f
+	return b
+}
+
 func (b *monoOpBuilder) String(f func(a string) (interface{}, error)) MonoOpDefBuilder {
 	b.stringOp = f
 	return b
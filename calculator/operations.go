@@ -1,80 +1,134 @@
 package calculator
 
-import "math"
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
 
 var (
-	equality = NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a == b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) < 1e-9, nil }).
-			String(func(a, b string) (interface{}, error) { return a == b, nil }).
+	equality = comparison(equal, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }).
+			Complex(func(a, b complex128) (interface{}, error) { return cmplx.Abs(a-b) < 1e-9, nil }).
 			Bool(func(a, b bool) (interface{}, error) { return a == b, nil }).
 			Build()
 
+	lessThan = comparison(less, func(a, b float64) bool { return math.Abs(a-b) >= 1e-9 && a < b }).
+			Build()
+
+	lessThanEqual = comparison(lessEqual, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 || a <= b }).
+			Build()
+
+	greaterThan = comparison(greater, func(a, b float64) bool { return math.Abs(a-b) >= 1e-9 && a > b }).
+			Build()
+
+	greaterThanEqual = comparison(greaterEqual, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 || a >= b }).
+				Build()
+
 	add = NewBiOpDef().
-		Int(func(a, b int) (interface{}, error) { return a + b, nil }).
-		Float(func(a, b float64) (interface{}, error) { return a + b, nil }).
-		String(func(a, b string) (interface{}, error) { return a + b, nil }).
+		Int(sum[int]).
+		Int64(sum[int64]).
+		Uint64(sum[uint64]).
+		Float(sum[float64]).
+		BigInt(bigOp((*big.Int).Add)).
+		BigRat(bigOp((*big.Rat).Add)).
+		BigFloat(bigOp((*big.Float).Add)).
+		Complex(sum[complex128]).
+		String(sum[string]).
 		Build()
 
 	subtract = NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a - b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return a - b, nil }).
+			Int(difference[int]).
+			Int64(difference[int64]).
+			Uint64(difference[uint64]).
+			Float(difference[float64]).
+			BigInt(bigOp((*big.Int).Sub)).
+			BigRat(bigOp((*big.Rat).Sub)).
+			BigFloat(bigOp((*big.Float).Sub)).
+			Complex(difference[complex128]).
 			Build()
 
 	monoOperations = map[string]MonoCalculation{
 		"!": NewMonoOpDef().
 			Int(func(a int) (interface{}, error) { return a == 0, nil }).
+			Int64(func(a int64) (interface{}, error) { return a == 0, nil }).
+			Uint64(func(a uint64) (interface{}, error) { return a == 0, nil }).
 			Float(func(a float64) (interface{}, error) { return math.Abs(a) <= 1e-9, nil }).
+			BigInt(func(a *big.Int) (interface{}, error) { return a.Sign() == 0, nil }).
+			BigRat(func(a *big.Rat) (interface{}, error) { return a.Sign() == 0, nil }).
+			BigFloat(func(a *big.Float) (interface{}, error) { return a.Sign() == 0, nil }).
+			Complex(func(a complex128) (interface{}, error) { return cmplx.Abs(a) <= 1e-9, nil }).
 			Bool(func(a bool) (interface{}, error) { return !a, nil }).
 			Build(),
 		"-": NewMonoOpDef().
 			Int(func(a int) (interface{}, error) { return -a, nil }).
+			Int64(func(a int64) (interface{}, error) { return -a, nil }).
+			Uint64(func(a uint64) (interface{}, error) { return -a, nil }).
 			Float(func(a float64) (interface{}, error) { return -a, nil }).
+			BigInt(func(a *big.Int) (interface{}, error) { return new(big.Int).Neg(a), nil }).
+			BigRat(func(a *big.Rat) (interface{}, error) { return new(big.Rat).Neg(a), nil }).
+			BigFloat(func(a *big.Float) (interface{}, error) { return new(big.Float).Neg(a), nil }).
+			Complex(func(a complex128) (interface{}, error) { return -a, nil }).
 			Bool(func(a bool) (interface{}, error) { return !a, nil }).
 			Build(),
+		"+": NewMonoOpDef().
+			Int(identity[int]).
+			Int64(identity[int64]).
+			Uint64(identity[uint64]).
+			Float(identity[float64]).
+			BigInt(identity[*big.Int]).
+			BigRat(identity[*big.Rat]).
+			BigFloat(identity[*big.Float]).
+			Complex(identity[complex128]).
+			Build(),
+		"^": NewMonoOpDef().
+			Int(func(a int) (interface{}, error) { return ^a, nil }).
+			Int64(func(a int64) (interface{}, error) { return ^a, nil }).
+			Uint64(func(a uint64) (interface{}, error) { return ^a, nil }).
+			BigInt(func(a *big.Int) (interface{}, error) { return new(big.Int).Not(a), nil }).
+			Build(),
 	}
 
 	biOperations = map[string]BiCalculation{
-		"==": equality,
-		"!=": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a != b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) >= 1e-9, nil }).
-			String(func(a, b string) (interface{}, error) { return a != b, nil }).
-			Bool(func(a, b bool) (interface{}, error) { return a != b, nil }).
-			Build(),
-		"<": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a < b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) >= 1e-9 && a < b, nil }).
-			String(func(a, b string) (interface{}, error) { return a < b, nil }).
-			Build(),
-		"<=": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a <= b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) < 1e-9 || a <= b, nil }).
-			String(func(a, b string) (interface{}, error) { return a <= b, nil }).
-			Build(),
-		">": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a > b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) >= 1e-9 && a > b, nil }).
-			String(func(a, b string) (interface{}, error) { return a > b, nil }).
-			Build(),
-		">=": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a >= b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return math.Abs(a-b) < 1e-9 || a >= b, nil }).
-			String(func(a, b string) (interface{}, error) { return a >= b, nil }).
-			Build(),
-		"+": add,
-		"-": subtract,
+		"==": &equalsOp{},
+		"!=": &equalsOp{not: true},
+		"<":  &compareOp{scalar: lessThan, test: less},
+		"<=": &compareOp{scalar: lessThanEqual, test: lessEqual},
+		">":  &compareOp{scalar: greaterThan, test: greater},
+		">=": &compareOp{scalar: greaterThanEqual, test: greaterEqual},
+		"in": &inOp{},
+		"+":  add,
+		"-":  subtract,
 		"*": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a * b, nil }).
-			Float(func(a, b float64) (interface{}, error) { return a * b, nil }).
+			Int(product[int]).
+			Int64(product[int64]).
+			Uint64(product[uint64]).
+			Float(product[float64]).
+			BigInt(bigOp((*big.Int).Mul)).
+			BigRat(bigOp((*big.Rat).Mul)).
+			BigFloat(bigOp((*big.Float).Mul)).
+			Complex(product[complex128]).
 			Build(),
 		"/": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a / b, nil }).
+			Int(quotient[int]).
+			Int64(quotient[int64]).
+			Uint64(quotient[uint64]).
 			Float(func(a, b float64) (interface{}, error) { return a / b, nil }).
+			BigInt(nonZero(bigOp((*big.Int).Quo))).
+			BigRat(nonZero(bigOp((*big.Rat).Quo))).
+			BigFloat(bigFloatQuo).
+			Complex(func(a, b complex128) (interface{}, error) { return a / b, nil }).
 			Build(),
 		"**": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return math.Pow(float64(a), float64(b)), nil }).
+			Int(power[int]).
+			Int64(power[int64]).
+			Uint64(power[uint64]).
 			Float(func(a, b float64) (interface{}, error) { return math.Pow(a, b), nil }).
+			BigInt(bigIntPow).
+			BigRat(bigRatPow).
+			BigFloat(bigFloatPow).
+			Complex(complexPow).
 			Build(),
 		"&&": NewBiOpDef().
 			Bool(func(a, b bool) (interface{}, error) { return a && b, nil }).
@@ -83,38 +137,51 @@ var (
 			Bool(func(a, b bool) (interface{}, error) { return a || b, nil }).
 			Build(),
 		"%": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a % b, nil }).
+			Int(remainder[int]).
+			Int64(remainder[int64]).
+			Uint64(remainder[uint64]).
 			Float(func(a, b float64) (interface{}, error) { return math.Mod(a, b), nil }).
+			BigInt(nonZero(bigOp((*big.Int).Rem))).
 			Build(),
 		"<<": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a << b, nil }).
+			Int(shiftLeft[int]).
+			Int64(shiftLeft[int64]).
+			Uint64(shiftLeft[uint64]).
+			BigInt(bigShift((*big.Int).Lsh)).
 			Build(),
 		">>": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a >> b, nil }).
+			Int(shiftRight[int]).
+			Int64(shiftRight[int64]).
+			Uint64(shiftRight[uint64]).
+			BigInt(bigShift((*big.Int).Rsh)).
 			Build(),
 		"&": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a & b, nil }).
+			Int(and[int]).
+			Int64(and[int64]).
+			Uint64(and[uint64]).
+			BigInt(bigOp((*big.Int).And)).
 			Build(),
 		"|": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a | b, nil }).
+			Int(or[int]).
+			Int64(or[int64]).
+			Uint64(or[uint64]).
+			BigInt(bigOp((*big.Int).Or)).
 			Build(),
 		"^": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a ^ b, nil }).
+			Int(xor[int]).
+			Int64(xor[int64]).
+			Uint64(xor[uint64]).
+			BigInt(bigOp((*big.Int).Xor)).
 			Build(),
 		"&^": NewBiOpDef().
-			Int(func(a, b int) (interface{}, error) { return a &^ b, nil }).
+			Int(andNot[int]).
+			Int64(andNot[int64]).
+			Uint64(andNot[uint64]).
+			BigInt(bigOp((*big.Int).AndNot)).
 			Build(),
 	}
 )
 
-func Equals(a, b interface{}) (bool, error) {
-	c, err := equality.BiCalculate(a, b)
-	if err != nil {
-		return false, err
-	}
-	return GetBool(c)
-}
-
 func Add(a, b interface{}) (interface{}, error) {
 	return add.BiCalculate(a, b)
 }
@@ -122,3 +189,237 @@ func Add(a, b interface{}) (interface{}, error) {
 func Subtract(a, b interface{}) (interface{}, error) {
 	return subtract.BiCalculate(a, b)
 }
+
+// integer is the go integer types the calculator performs arithmetic with
+type integer interface {
+	int | int64 | uint64
+}
+
+// number is the go real number types the calculator performs arithmetic with
+type number interface {
+	integer | float64
+}
+
+// arithmetic is the go types which support the basic arithmetic operators
+type arithmetic interface {
+	number | complex128
+}
+
+func identity[T any](a T) (interface{}, error)               { return a, nil }
+func sum[T arithmetic | string](a, b T) (interface{}, error) { return a + b, nil }
+func difference[T arithmetic](a, b T) (interface{}, error)   { return a - b, nil }
+func product[T arithmetic](a, b T) (interface{}, error)      { return a * b, nil }
+
+// quotient implements integer division, which unlike float division fails when b is 0
+func quotient[T integer](a, b T) (interface{}, error) {
+	if b == 0 {
+		return nil, divisionByZero
+	}
+	return a / b, nil
+}
+
+func remainder[T integer](a, b T) (interface{}, error) {
+	if b == 0 {
+		return nil, divisionByZero
+	}
+	return a % b, nil
+}
+
+func shiftLeft[T integer](a, b T) (interface{}, error) {
+	if b < 0 {
+		return nil, negativeShift
+	}
+	return a << b, nil
+}
+
+func shiftRight[T integer](a, b T) (interface{}, error) {
+	if b < 0 {
+		return nil, negativeShift
+	}
+	return a >> b, nil
+}
+
+func and[T integer](a, b T) (interface{}, error)    { return a & b, nil }
+func or[T integer](a, b T) (interface{}, error)     { return a | b, nil }
+func xor[T integer](a, b T) (interface{}, error)    { return a ^ b, nil }
+func andNot[T integer](a, b T) (interface{}, error) { return a &^ b, nil }
+
+// power implements ** for integers.
+// The result is an integer unless the exponent is negative, in which case it's a float.
+func power[T integer](a, b T) (interface{}, error) {
+	if b < 0 {
+		return math.Pow(float64(a), float64(b)), nil
+	}
+
+	// Exponentiation by squaring
+	r := T(1)
+	for ; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			r *= a
+		}
+		a *= a
+	}
+	return r, nil
+}
+
+// complexPow implements ** for complex numbers.
+// Integer exponents use repeated multiplication, so the result is exact where possible,
+// e.g. (3+4i) ** 2 is -7+24i rather than the approximation returned by cmplx.Pow.
+func complexPow(a, b complex128) (interface{}, error) {
+	n := real(b)
+	if imag(b) != 0 || n != math.Trunc(n) || math.Abs(n) > 64 {
+		return cmplx.Pow(a, b), nil
+	}
+
+	r := complex128(1)
+	for k := int(math.Abs(n)); k > 0; k >>= 1 {
+		if k&1 == 1 {
+			r *= a
+		}
+		a *= a
+	}
+
+	if n < 0 {
+		return 1 / r, nil
+	}
+	return r, nil
+}
+
+// bigIntPow implements ** for big.Int.
+// The result is a big.Int unless the exponent is negative, in which case it's a big.Rat.
+func bigIntPow(a, b *big.Int) (interface{}, error) {
+	if b.Sign() < 0 {
+		return bigRatPow(new(big.Rat).SetInt(a), new(big.Rat).SetInt(b))
+	}
+	return new(big.Int).Exp(a, b, nil), nil
+}
+
+// bigRatPow implements ** for big.Rat.
+// If the exponent is not an integer then the result is a float64.
+func bigRatPow(a, b *big.Rat) (interface{}, error) {
+	if !b.IsInt() || !b.Num().IsInt64() {
+		return math.Pow(toFloat(a), toFloat(b)), nil
+	}
+
+	n := b.Num().Int64()
+	if n < 0 {
+		if a.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		a, n = new(big.Rat).Inv(a), -n
+	}
+
+	r, x := big.NewRat(1, 1), new(big.Rat).Set(a)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			r.Mul(r, x)
+		}
+		x.Mul(x, x)
+	}
+	return r, nil
+}
+
+// bigFloatPow implements ** for big.Float, keeping the precision of a.
+// If the exponent is not an integer then the result is a float64.
+func bigFloatPow(a, b *big.Float) (interface{}, error) {
+	n, acc := b.Int64()
+	if acc != big.Exact {
+		return math.Pow(toFloat(a), toFloat(b)), nil
+	}
+
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+
+	r, x := new(big.Float).SetPrec(a.Prec()).SetInt64(1), new(big.Float).Set(a)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			r.Mul(r, x)
+		}
+		x.Mul(x, x)
+	}
+
+	if neg {
+		// 0 ** -n is +Inf as with math.Pow
+		r.Quo(new(big.Float).SetPrec(a.Prec()).SetInt64(1), r)
+	}
+	return r, nil
+}
+
+// bigOp adapts a math/big method which sets its receiver to the result, e.g. (*big.Int).Add,
+// so the operands are left unchanged.
+func bigOp[T any, P interface{ *T }](f func(z, x, y P) P) func(a, b P) (interface{}, error) {
+	return func(a, b P) (interface{}, error) {
+		return f(new(T), a, b), nil
+	}
+}
+
+// nonZero wraps a division operation so it fails if b is 0
+func nonZero[P interface{ Sign() int }](f func(a, b P) (interface{}, error)) func(a, b P) (interface{}, error) {
+	return func(a, b P) (interface{}, error) {
+		if b.Sign() == 0 {
+			return nil, divisionByZero
+		}
+		return f(a, b)
+	}
+}
+
+// bigFloatQuo divides big.Floats. As a big.Float cannot be NaN, 0/0 and Inf/Inf fail with the same
+// errors float64 does under StrictFloat, instead of panicking.
+func bigFloatQuo(a, b *big.Float) (interface{}, error) {
+	switch {
+	case a.Sign() == 0 && b.Sign() == 0:
+		return nil, divisionByZero
+	case a.IsInf() && b.IsInf():
+		return nil, fmt.Errorf("%w: %v / %v", notANumber, a, b)
+	}
+	return new(big.Float).Quo(a, b), nil
+}
+
+// bigShift adapts a big.Int shift method
+func bigShift(f func(z, x *big.Int, n uint) *big.Int) func(a, b *big.Int) (interface{}, error) {
+	return func(a, b *big.Int) (interface{}, error) {
+		switch {
+		case b.Sign() < 0:
+			return nil, negativeShift
+		case !b.IsUint64():
+			return nil, invalidOperation
+		}
+		return f(new(big.Int), a, uint(b.Uint64())), nil
+	}
+}
+
+func equal(c int) bool        { return c == 0 }
+func less(c int) bool         { return c < 0 }
+func lessEqual(c int) bool    { return c <= 0 }
+func greater(c int) bool      { return c > 0 }
+func greaterEqual(c int) bool { return c >= 0 }
+
+// comparison returns a BiOpDefBuilder for a comparison operator.
+//
+// test is passed the result of comparing a with b, whilst floats use their own test
+// as they are compared with a tolerance to account for rounding errors.
+func comparison(test func(c int) bool, float func(a, b float64) bool) BiOpDefBuilder {
+	return NewBiOpDef().
+		Int(ordered[int](test)).
+		Int64(ordered[int64](test)).
+		Uint64(ordered[uint64](test)).
+		Float(func(a, b float64) (interface{}, error) { return float(a, b), nil }).
+		BigInt(bigCompare[*big.Int](test)).
+		BigRat(bigCompare[*big.Rat](test)).
+		BigFloat(bigCompare[*big.Float](test)).
+		String(ordered[string](test))
+}
+
+func ordered[T cmp.Ordered](test func(c int) bool) func(a, b T) (interface{}, error) {
+	return func(a, b T) (interface{}, error) {
+		return test(cmp.Compare(a, b)), nil
+	}
+}
+
+func bigCompare[P interface{ Cmp(P) int }](test func(c int) bool) func(a, b P) (interface{}, error) {
+	return func(a, b P) (interface{}, error) {
+		return test(a.Cmp(b)), nil
+	}
+}
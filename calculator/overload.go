@@ -0,0 +1,225 @@
+package calculator
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Operators can be overloaded for go types in two ways:
+//
+// 1. Register a function with RegisterBiOp or RegisterMonoOp for specific types,
+// e.g. RegisterBiOp("*", func(a Money, b int) (interface{}, error) {...})
+//
+// 2. Implement the method named in BiOpMethods or MonoOpMethods, e.g. Add(b T) R for "+".
+// This is used automatically when the left hand side has that method and the right hand side
+// is of type T. The method can also return (R, error).
+// Note that time.Time - time.Time works as time.Time has a Sub(time.Time) time.Duration method.
+//
+// Registered functions take priority over methods, which take priority over the builtin operators.
+// The builtin operators for int, float64, string and bool cannot be overloaded.
+
+var (
+	// BiOpMethods is the method name used for each binary operator
+	BiOpMethods = map[string]string{
+		"+":  "Add",
+		"-":  "Sub",
+		"*":  "Mul",
+		"/":  "Div",
+		"%":  "Mod",
+		"**": "Pow",
+		"&":  "And",
+		"|":  "Or",
+		"^":  "Xor",
+		"&^": "AndNot",
+		"<<": "Lsh",
+		">>": "Rsh",
+	}
+
+	// MonoOpMethods is the method name used for each unary operator
+	MonoOpMethods = map[string]string{
+		"-": "Neg",
+		"^": "Not",
+	}
+
+	overloads = &overloadRegistry{
+		bi:   make(map[string][]biOverload),
+		mono: make(map[string][]monoOverload),
+	}
+)
+
+type biOverload struct {
+	a, b reflect.Type
+	f    func(a, b interface{}) (interface{}, error)
+}
+
+type monoOverload struct {
+	a reflect.Type
+	f func(a interface{}) (interface{}, error)
+}
+
+type overloadRegistry struct {
+	mutex sync.RWMutex
+	bi    map[string][]biOverload
+	mono  map[string][]monoOverload
+}
+
+// RegisterBiOp registers a binary operator for when the left hand side is of type A
+// and the right hand side of type B.
+//
+// A and B can be interfaces, in which case the operator is used for any type implementing them.
+// If more than one registration matches then the first one registered is used.
+func RegisterBiOp[A, B any](op string, f func(a A, b B) (interface{}, error)) {
+	o := biOverload{
+		a: reflect.TypeOf((*A)(nil)).Elem(),
+		b: reflect.TypeOf((*B)(nil)).Elem(),
+		f: func(a, b interface{}) (interface{}, error) {
+			return f(a.(A), b.(B))
+		},
+	}
+
+	overloads.mutex.Lock()
+	defer overloads.mutex.Unlock()
+	overloads.bi[op] = append(overloads.bi[op], o)
+}
+
+// RegisterMonoOp registers a unary operator for values of type A.
+//
+// A can be an interface, in which case the operator is used for any type implementing it.
+// If more than one registration matches then the first one registered is used.
+func RegisterMonoOp[A any](op string, f func(a A) (interface{}, error)) {
+	o := monoOverload{
+		a: reflect.TypeOf((*A)(nil)).Elem(),
+		f: func(a interface{}) (interface{}, error) {
+			return f(a.(A))
+		},
+	}
+
+	overloads.mutex.Lock()
+	defer overloads.mutex.Unlock()
+	overloads.mono[op] = append(overloads.mono[op], o)
+}
+
+// biOp returns the overload for op against a and b.
+// The returned bool is false if there is none.
+func (r *overloadRegistry) biOp(op string, a, b interface{}) (func() (interface{}, error), bool) {
+	if a == nil || b == nil || (isPrimitive(a) && isPrimitive(b)) {
+		return nil, false
+	}
+
+	at, bt := reflect.TypeOf(a), reflect.TypeOf(b)
+
+	r.mutex.RLock()
+	for _, o := range r.bi[op] {
+		if at.AssignableTo(o.a) && bt.AssignableTo(o.b) {
+			r.mutex.RUnlock()
+			return func() (interface{}, error) { return o.f(a, b) }, true
+		}
+	}
+	r.mutex.RUnlock()
+
+	if name, ok := BiOpMethods[op]; ok {
+		if m, ok := operatorMethod(a, name, bt); ok {
+			return func() (interface{}, error) {
+				return callOperatorMethod(m, reflect.ValueOf(b))
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// monoOp returns the overload for op against a.
+// The returned bool is false if there is none.
+func (r *overloadRegistry) monoOp(op string, a interface{}) (func() (interface{}, error), bool) {
+	if a == nil || isPrimitive(a) {
+		return nil, false
+	}
+
+	at := reflect.TypeOf(a)
+
+	r.mutex.RLock()
+	for _, o := range r.mono[op] {
+		if at.AssignableTo(o.a) {
+			r.mutex.RUnlock()
+			return func() (interface{}, error) { return o.f(a) }, true
+		}
+	}
+	r.mutex.RUnlock()
+
+	if name, ok := MonoOpMethods[op]; ok {
+		if m, ok := operatorMethod(a, name, nil); ok {
+			return func() (interface{}, error) {
+				return callOperatorMethod(m)
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// methodCache holds the result of operatorMethod lookups per type
+var methodCache sync.Map
+
+type methodKey struct {
+	t    reflect.Type
+	name string
+	arg  reflect.Type
+}
+
+// operatorMethod returns the named method of v which takes a single argument of type arg,
+// or no arguments if arg is nil, and returns either one value or a value and an error.
+func operatorMethod(v interface{}, name string, arg reflect.Type) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	key := methodKey{t: rv.Type(), name: name, arg: arg}
+
+	idx, ok := methodCache.Load(key)
+	if !ok {
+		idx = findOperatorMethod(rv.Type(), name, arg)
+		methodCache.Store(key, idx)
+	}
+
+	if i := idx.(int); i >= 0 {
+		return rv.Method(i), true
+	}
+	return reflect.Value{}, false
+}
+
+// findOperatorMethod returns the index of a method suitable for an operator, -1 if none
+func findOperatorMethod(t reflect.Type, name string, arg reflect.Type) int {
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return -1
+	}
+
+	// Method type includes the receiver as the first argument
+	mt := m.Type
+	switch {
+	case arg == nil && mt.NumIn() != 1,
+		arg != nil && (mt.NumIn() != 2 || !arg.AssignableTo(mt.In(1))),
+		mt.NumOut() < 1 || mt.NumOut() > 2,
+		mt.NumOut() == 2 && !mt.Out(1).Implements(errorInterface):
+		return -1
+	}
+
+	return m.Index
+}
+
+var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+
+func callOperatorMethod(m reflect.Value, args ...reflect.Value) (interface{}, error) {
+	ret := m.Call(args)
+	if len(ret) == 2 && !ret[1].IsNil() {
+		return nil, ret[1].Interface().(error)
+	}
+	return ret[0].Interface(), nil
+}
+
+// isPrimitive returns true for the types handled directly by the builtin operators
+func isPrimitive(v interface{}) bool {
+	switch v.(type) {
+	case int, float64, string, bool:
+		return true
+	default:
+		return false
+	}
+}
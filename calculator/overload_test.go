@@ -0,0 +1,117 @@
+package calculator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type vector struct {
+	X, Y float64
+}
+
+func (v vector) Add(b vector) vector { return vector{v.X + b.X, v.Y + b.Y} }
+
+func (v vector) Mul(b float64) vector { return vector{v.X * b, v.Y * b} }
+
+func (v vector) Neg() vector { return vector{-v.X, -v.Y} }
+
+func (v vector) Div(b float64) (vector, error) {
+	if b == 0 {
+		return vector{}, errors.New("divide by zero")
+	}
+	return vector{v.X / b, v.Y / b}, nil
+}
+
+type money struct {
+	Pence int
+}
+
+type currency interface {
+	Value() int
+}
+
+type dollars int
+
+func (d dollars) Value() int { return int(d) * 100 }
+
+func init() {
+	RegisterBiOp("*", func(a money, b int) (interface{}, error) {
+		return money{Pence: a.Pence * b}, nil
+	})
+	RegisterBiOp("*", func(a int, b money) (interface{}, error) {
+		return money{Pence: a * b.Pence}, nil
+	})
+	RegisterBiOp("+", func(a money, b currency) (interface{}, error) {
+		return money{Pence: a.Pence + b.Value()}, nil
+	})
+	RegisterMonoOp("-", func(a money) (interface{}, error) {
+		return money{Pence: -a.Pence}, nil
+	})
+	// Takes priority over the Add method on vector
+	RegisterBiOp("+", func(a vector, b money) (interface{}, error) {
+		return money{Pence: int(a.X) + b.Pence}, nil
+	})
+}
+
+func TestOverload(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		a, b    interface{}
+		op      string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "method", a: vector{1, 2}, op: "+", b: vector{3, 4}, want: vector{4, 6}},
+		{name: "method arg", a: vector{1, 2}, op: "*", b: 2.0, want: vector{2, 4}},
+		{name: "method error", a: vector{1, 2}, op: "/", b: 0.0, wantErr: "divide by zero"},
+		{name: "method result", a: vector{1, 2}, op: "/", b: 2.0, want: vector{0.5, 1}},
+		{name: "method wrong arg", a: vector{1, 2}, op: "+", b: 1, wantErr: "unsupported"},
+		{name: "method unary", a: vector{1, 2}, op: "-", want: vector{-1, -2}},
+		{name: "registered", a: money{150}, op: "*", b: 3, want: money{450}},
+		{name: "registered rhs", a: 2, op: "*", b: money{150}, want: money{300}},
+		{name: "registered interface", a: money{150}, op: "+", b: dollars(2), want: money{350}},
+		{name: "registered unary", a: money{150}, op: "-", want: money{-150}},
+		{name: "registered priority", a: vector{1, 2}, op: "+", b: money{5}, want: money{6}},
+		{name: "registered unsupported", a: money{150}, op: "/", b: 3, wantErr: "unsupported"},
+		{name: "time sub", a: now.Add(time.Hour), op: "-", b: now, want: time.Hour},
+		{name: "time add", a: now, op: "+", b: time.Minute, want: now.Add(time.Minute)},
+		{name: "builtin", a: 1, op: "+", b: 2, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.Push(tt.a)
+
+			var err error
+			if tt.b == nil {
+				err = c.Op1(tt.op)
+			} else {
+				c.Push(tt.b)
+				err = c.Op2(tt.op)
+			}
+
+			switch {
+			case err != nil:
+				if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			case tt.wantErr != "":
+				t.Fatalf("expected error %q", tt.wantErr)
+			}
+
+			got, err := c.Pop()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %T %v want %T %v", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package calculator
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 )
@@ -27,6 +28,12 @@ type Bool interface {
 	Bool() bool
 }
 
+// Map is an instance that can return its fields as a map, e.g. a record declared within a script.
+// This allows it to be passed to go functions which expect a map or struct.
+type Map interface {
+	Map() map[string]interface{}
+}
+
 // Value is an instance that can return it's value as an interface{}
 type Value interface {
 	Value() interface{}
@@ -81,8 +88,8 @@ func GetFloat(v interface{}) (float64, error) {
 	}
 
 	if v != nil {
-		if i, ok := GetIntRaw(v); ok {
-			return float64(i), nil
+		if k, ok := numericKind(v); ok && (k != kindComplex || imag(toComplex(v)) == 0) {
+			return toFloat(v), nil
 		}
 
 		if s, ok := v.(string); ok {
@@ -114,12 +121,18 @@ func GetIntRaw(v interface{}) (int, bool) {
 		if i, ok := v.(int64); ok {
 			return int(i), true
 		}
+		if i, ok := v.(*big.Int); ok && i != nil {
+			return int(i.Int64()), true
+		}
 
 		// Issue #4 Check for types which can convert to int
 		tv := reflect.ValueOf(v)
 		if tv.CanInt() {
 			return int(tv.Int()), true
 		}
+		if tv.CanUint() {
+			return int(tv.Uint()), true
+		}
 	}
 	return 0, false
 }
@@ -138,8 +151,8 @@ func GetInt(v interface{}) (int, error) {
 	}
 
 	if v != nil {
-		if f, ok := GetFloatRaw(v); ok {
-			return int(f), nil
+		if _, ok := numericKind(v); ok {
+			return toInt(v), nil
 		}
 
 		if s, ok := v.(string); ok {
@@ -156,6 +169,121 @@ func GetInt(v interface{}) (int, error) {
 	return 0, fmt.Errorf("not an int %q", v)
 }
 
+// GetComplex returns v as a complex128.
+// If v is a complex number it will return it, any other number will have no imaginary part.
+// If v is a string it will parse it, e.g. "3+4i".
+func GetComplex(v interface{}) (complex128, error) {
+	v = GetValue(v)
+
+	if _, ok := numericKind(v); ok {
+		return toComplex(v), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return strconv.ParseComplex(s, 128)
+	}
+
+	if b, ok := v.(bool); ok && b {
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("not complex %v", v)
+}
+
+// GetInt64 returns v as an int64.
+// This is the same as GetInt except no precision is lost on platforms where int is 32 bits.
+func GetInt64(v interface{}) (int64, error) {
+	v = GetValue(v)
+
+	if _, ok := numericKind(v); ok {
+		return toInt64(v), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return strconv.ParseInt(s, 10, 64)
+	}
+
+	i, err := GetInt(v)
+	return int64(i), err
+}
+
+// GetUint64 returns v as an uint64.
+// This is the same as GetInt except no precision is lost for values larger than math.MaxInt64.
+func GetUint64(v interface{}) (uint64, error) {
+	v = GetValue(v)
+
+	if _, ok := numericKind(v); ok {
+		return toUint64(v), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return strconv.ParseUint(s, 10, 64)
+	}
+
+	i, err := GetInt(v)
+	return uint64(i), err
+}
+
+// GetBigInt returns v as a *big.Int.
+// If v is a string it will parse it, otherwise any number is converted, with floats being truncated.
+func GetBigInt(v interface{}) (*big.Int, error) {
+	v = GetValue(v)
+
+	if k, ok := numericKind(v); ok && k.isInteger() {
+		return new(big.Int).Set(toBigInt(v)), nil
+	}
+
+	if s, ok := v.(string); ok {
+		if i, ok := new(big.Int).SetString(s, 0); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("not an integer %q", s)
+	}
+
+	f, err := GetBigFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	i, _ := f.Int(nil)
+	return i, nil
+}
+
+// GetBigRat returns v as a *big.Rat.
+// If v is a string it will parse it, e.g. "1/3" or "0.25", otherwise any number is converted.
+func GetBigRat(v interface{}) (*big.Rat, error) {
+	v = GetValue(v)
+
+	if _, ok := numericKind(v); ok {
+		return new(big.Rat).Set(toBigRat(v)), nil
+	}
+
+	if s, ok := v.(string); ok {
+		if r, ok := new(big.Rat).SetString(s); ok {
+			return r, nil
+		}
+		return nil, fmt.Errorf("not a rational %q", s)
+	}
+
+	return nil, fmt.Errorf("not a rational %v", v)
+}
+
+// GetBigFloat returns v as a *big.Float.
+// If v is a string it will parse it, otherwise any number is converted.
+func GetBigFloat(v interface{}) (*big.Float, error) {
+	v = GetValue(v)
+
+	if _, ok := numericKind(v); ok {
+		return new(big.Float).Set(toBigFloat(v)), nil
+	}
+
+	if s, ok := v.(string); ok {
+		f, _, err := big.ParseFloat(s, 10, 0, big.ToNearestEven)
+		return f, err
+	}
+
+	return nil, fmt.Errorf("not a float %v", v)
+}
+
 // GetStringRaw returns v as a string if it's a string or implements String.
 // Returns "",false if the value is not a string.
 func GetStringRaw(v interface{}) (string, bool) {
@@ -188,6 +316,10 @@ func GetString(v interface{}) (string, error) {
 	}
 
 	if v != nil {
+		if k, ok := numericKind(v); ok && k == kindUint64 {
+			return strconv.FormatUint(toUint64(v), 10), nil
+		}
+
 		if i, ok := GetIntRaw(v); ok {
 			return strconv.Itoa(i), nil
 		}
@@ -196,6 +328,10 @@ func GetString(v interface{}) (string, error) {
 			return strconv.FormatFloat(f, 'f', 6, 64), nil
 		}
 
+		if k, ok := numericKind(v); ok && k == kindComplex {
+			return strconv.FormatComplex(toComplex(v), 'f', 6, 128), nil
+		}
+
 		if b, ok := v.(bool); ok {
 			if b {
 				return "true", nil
@@ -254,6 +390,9 @@ func GetBool(v interface{}) (bool, error) {
 			return math.Abs(f) >= 1e-9, nil
 		}
 
+		if _, ok := numericKind(v); ok {
+			return !isZero(v), nil
+		}
 	}
 
 	return false, fmt.Errorf("not a bool %q", v)
@@ -275,8 +414,14 @@ func Convert(a, b interface{}) (interface{}, interface{}, error) {
 	af, aFloat := GetFloatRaw(a)
 	bf, bFloat := GetFloatRaw(b)
 	ai, aInt := GetIntRaw(a)
+	ak, _ := numericKind(GetValue(a))
 	switch {
 
+	// 'a' complex so try to convert 'b' to complex
+	case ak == kindComplex:
+		c, err := GetComplex(b)
+		return toComplex(GetValue(a)), c, err
+
 	// a & b are floats so leave alone
 	case aFloat && bFloat:
 		return af, bf, nil
@@ -307,50 +452,168 @@ func Convert(a, b interface{}) (interface{}, interface{}, error) {
 		}
 	}
 
-	return nil, nil, fmt.Errorf("unable to convert %T to %T", b, a)
+	return nil, nil, fmt.Errorf("unable to convert %T to %T: %w", b, a, invalidOperation)
 }
 
 // Cast takes a Value and attempt to cast it to a specific Type.
 // This will attempt to use our conversions for Int, Float, Bool, String etc. as part of that conversion.
 func Cast(rv reflect.Value, as reflect.Type) (reflect.Value, error) {
-	v := rv.Interface()
-	vt := reflect.ValueOf(v)
+	return Caster(as)(rv)
+}
+
+// CastFunc converts a Value to a specific Type
+type CastFunc func(rv reflect.Value) (reflect.Value, error)
+
+// Caster returns a CastFunc which will perform Cast for a specific Type.
+// This allows the conversion to be planned once and reused, e.g. when calling the same go function repeatedly.
+func Caster(as reflect.Type) CastFunc {
+	var conv func(v interface{}) (interface{}, error)
+
+	switch as {
+	case bigIntType:
+		conv = func(v interface{}) (interface{}, error) { return GetBigInt(v) }
+
+	case bigRatType:
+		conv = func(v interface{}) (interface{}, error) { return GetBigRat(v) }
+
+	case bigFloatType:
+		conv = func(v interface{}) (interface{}, error) { return GetBigFloat(v) }
+	}
 
 	switch as.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-		i, err := GetInt(v)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		vt = reflect.ValueOf(i)
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		conv = func(v interface{}) (interface{}, error) { return GetInt(v) }
+
+	case reflect.Int64:
+		conv = func(v interface{}) (interface{}, error) { return GetInt64(v) }
+
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		conv = func(v interface{}) (interface{}, error) { return GetUint64(v) }
 
 	case reflect.Float64, reflect.Float32:
-		f, err := GetFloat(v)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		vt = reflect.ValueOf(f)
+		conv = func(v interface{}) (interface{}, error) { return GetFloat(v) }
+
+	case reflect.Complex128, reflect.Complex64:
+		conv = func(v interface{}) (interface{}, error) { return GetComplex(v) }
 
 	case reflect.Bool:
-		f, err := GetBool(v)
+		conv = func(v interface{}) (interface{}, error) { return GetBool(v) }
+
+	case reflect.String:
+		conv = func(v interface{}) (interface{}, error) { return GetString(v) }
+
+	case reflect.Map:
+		if as.Key().Kind() == reflect.String {
+			conv = func(v interface{}) (interface{}, error) { return castMap(v, as) }
+		}
+
+	case reflect.Struct:
+		conv = func(v interface{}) (interface{}, error) { return castStruct(v, as) }
+
+	case reflect.Pointer:
+		if conv == nil && as.Elem().Kind() == reflect.Struct {
+			conv = func(v interface{}) (interface{}, error) { return castStructPointer(v, as) }
+		}
+	}
+
+	return func(rv reflect.Value) (reflect.Value, error) {
+		v := rv.Interface()
+
+		if conv != nil {
+			var err error
+			v, err = conv(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+		}
+
+		vt := reflect.ValueOf(v)
+		if vt.CanConvert(as) {
+			vt = vt.Convert(as)
+		}
+
+		return vt, nil
+	}
+}
+
+// castMap converts a value implementing Map to a map of type as.
+// Anything else is returned unchanged.
+func castMap(v interface{}, as reflect.Type) (interface{}, error) {
+	m, ok := v.(Map)
+	if !ok {
+		return v, nil
+	}
+
+	fields := m.Map()
+	r := reflect.MakeMapWithSize(as, len(fields))
+	for k, fv := range fields {
+		val, err := castField(k, fv, as.Elem())
 		if err != nil {
-			return reflect.Value{}, err
+			return nil, err
 		}
-		vt = reflect.ValueOf(f)
+		r.SetMapIndex(reflect.ValueOf(k).Convert(as.Key()), val)
+	}
+	return r.Interface(), nil
+}
 
-	case reflect.String:
-		f, err := GetString(v)
+// castStruct converts a value implementing Map to a struct of type as.
+// Like encoding/json, fields which are not present in the struct or are not exported are ignored.
+// Anything else is returned unchanged.
+func castStruct(v interface{}, as reflect.Type) (interface{}, error) {
+	r, ok, err := newStruct(v, as)
+	if !ok || err != nil {
+		return v, err
+	}
+	return r.Interface(), nil
+}
+
+// castStructPointer is like castStruct but returns a pointer to a new struct
+func castStructPointer(v interface{}, as reflect.Type) (interface{}, error) {
+	r, ok, err := newStruct(v, as.Elem())
+	if !ok || err != nil {
+		return v, err
+	}
+	return r.Addr().Interface(), nil
+}
+
+// newStruct returns a new struct of type as populated from v.
+// The returned bool is false if v does not implement Map.
+func newStruct(v interface{}, as reflect.Type) (reflect.Value, bool, error) {
+	m, ok := v.(Map)
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+
+	r := reflect.New(as).Elem()
+	for k, fv := range m.Map() {
+		f := r.FieldByName(k)
+		if !f.IsValid() || !f.CanSet() {
+			continue
+		}
+
+		val, err := castField(k, fv, f.Type())
 		if err != nil {
-			return reflect.Value{}, err
+			return reflect.Value{}, true, err
 		}
-		vt = reflect.ValueOf(f)
+		f.Set(val)
+	}
+	return r, true, nil
+}
 
+// castField casts the value of a field so it can be assigned to something of type t
+func castField(name string, v interface{}, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
 	}
 
-	if vt.CanConvert(as) {
-		vt = vt.Convert(as)
+	rv, err := Cast(reflect.ValueOf(v), t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("field %q: %w", name, err)
 	}
 
-	return vt, nil
+	if !rv.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("field %q: cannot use %T as %s", name, v, t)
+	}
+	return rv, nil
 }
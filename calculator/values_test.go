@@ -2,7 +2,10 @@ package calculator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +23,10 @@ func TestCast(t *testing.T) {
 		{reflect.ValueOf("5"), reflect.ValueOf(5), false},
 		{reflect.ValueOf("5.5"), reflect.ValueOf(5.5), false},
 		{reflect.ValueOf("5.5"), reflect.ValueOf(5), true},
+		{reflect.ValueOf(5), reflect.ValueOf(complex(5, 0)), false},
+		{reflect.ValueOf("1+2i"), reflect.ValueOf(complex64(complex(1, 2))), false},
+		{reflect.ValueOf(complex(5, 0)), reflect.ValueOf(5.0), false},
+		{reflect.ValueOf(complex(5, 1)), reflect.ValueOf(5.0), true},
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%v %v", tt.from.Kind(), tt.want.Kind()), func(t *testing.T) {
@@ -76,3 +83,82 @@ func TestGetFloatRaw(t *testing.T) {
 		})
 	}
 }
+
+// TestSafeArithmetic ensures operations which would panic in go return an error instead
+func TestSafeArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    interface{}
+		op      string
+		mode    Mode
+		want    interface{}
+		wantErr string
+	}{
+		{name: "int div zero", a: 1, op: "/", b: 0, wantErr: "division by zero"},
+		{name: "int mod zero", a: 1, op: "%", b: 0, wantErr: "division by zero"},
+		{name: "int64 div zero", a: int64(1), op: "/", b: int64(0), wantErr: "division by zero"},
+		{name: "uint64 mod zero", a: uint64(1), op: "%", b: 0, wantErr: "division by zero"},
+		{name: "big int div zero", a: big.NewInt(1), op: "/", b: 0, wantErr: "division by zero"},
+		{name: "big int mod zero", a: big.NewInt(1), op: "%", b: 0, wantErr: "division by zero"},
+		{name: "big rat div zero", a: big.NewRat(1, 2), op: "/", b: 0, wantErr: "division by zero"},
+		{name: "big float zero by zero", a: big.NewFloat(0), op: "/", b: 0, wantErr: "division by zero"},
+		{name: "big float inf by inf", a: new(big.Float).SetInf(false), op: "/", b: new(big.Float).SetInf(true), wantErr: "result is NaN"},
+		{name: "big float div zero", a: big.NewFloat(1), op: "/", b: 0, want: "+Inf"},
+		{name: "shift left negative", a: 1, op: "<<", b: -1, wantErr: "negative shift amount"},
+		{name: "shift right negative", a: int64(8), op: ">>", b: -2, wantErr: "negative shift amount"},
+		{name: "big shift negative", a: big.NewInt(1), op: "<<", b: -1, wantErr: "negative shift amount"},
+		{name: "shift large", a: 1, op: "<<", b: 100, want: 0},
+		{name: "min int div", a: math.MinInt, op: "/", b: -1, want: math.MinInt},
+		{name: "min int div overflow", a: math.MinInt, op: "/", b: -1, mode: CheckOverflow, wantErr: "integer overflow"},
+
+		// Floats follow IEEE 754 unless StrictFloat is set
+		{name: "float div zero", a: 1.0, op: "/", b: 0, want: math.Inf(1)},
+		{name: "float mod zero", a: 1.0, op: "%", b: 0.0, want: "NaN"},
+		{name: "float nan", a: math.Inf(1), op: "-", b: math.Inf(1), want: "NaN"},
+		{name: "strict div zero", a: 1.0, op: "/", b: 0, mode: StrictFloat, wantErr: "division by zero"},
+		{name: "strict mod zero", a: 1.0, op: "%", b: 0.0, mode: StrictFloat, wantErr: "division by zero"},
+		{name: "strict big div zero", a: big.NewFloat(1), op: "/", b: 0, mode: StrictFloat, wantErr: "division by zero"},
+		{name: "strict nan", a: math.Inf(1), op: "-", b: math.Inf(1), mode: StrictFloat, wantErr: "result is NaN"},
+		{name: "strict pow nan", a: -8.0, op: "**", b: 0.5, mode: StrictFloat, wantErr: "result is NaN"},
+		{name: "strict ok", a: 1.0, op: "/", b: 4, mode: StrictFloat, want: 0.25},
+		{name: "strict int div", a: 1, op: "/", b: 0, mode: StrictFloat, wantErr: "division by zero"},
+		{name: "strict mono nan", a: math.NaN(), op: "-", mode: StrictFloat, wantErr: "result is NaN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New().SetMode(tt.mode)
+			c.Push(tt.a)
+
+			var err error
+			if tt.b == nil {
+				err = c.Op1(tt.op)
+			} else {
+				c.Push(tt.b)
+				err = c.Op2(tt.op)
+			}
+
+			switch {
+			case err != nil:
+				if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatal(err)
+				}
+				if tt.wantErr == "division by zero" && !IsDivisionByZero(err) {
+					t.Errorf("IsDivisionByZero false for %v", err)
+				}
+				return
+			case tt.wantErr != "":
+				t.Fatalf("expected error %q", tt.wantErr)
+			}
+
+			got, err := c.Pop()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// NaN is never equal to itself so compare as strings
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %T %v want %v", got, got, tt.want)
+			}
+		})
+	}
+}
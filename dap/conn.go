@@ -0,0 +1,78 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// conn reads and writes messages framed with a Content-Length header, as used by the Debug Adapter Protocol
+type conn struct {
+	in    *bufio.Reader
+	out   io.Writer
+	mutex sync.Mutex // Prevents messages from being interleaved and keeps seq in order
+	seq   int        // The sequence number of the last message sent
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{in: bufio.NewReader(r), out: w}
+}
+
+// read returns the next message, or io.EOF once the input has ended
+func (c *conn) read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := c.in.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(c.in, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// write sends the message returned by f, which is passed the message's sequence number
+func (c *conn) write(f func(seq int) interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.seq++
+	b, err := json.Marshal(f(c.seq))
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+	_, err = c.out.Write(b)
+	return err
+}
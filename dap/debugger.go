@@ -0,0 +1,235 @@
+package dap
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/script"
+	"path/filepath"
+	"strings"
+)
+
+// stepMode is how the script continues once resumed
+type stepMode int
+
+const (
+	stepNone stepMode = iota // Run until a breakpoint
+	stepIn                   // Stop at the next statement, including within a called function
+	stepOver                 // Stop at the next statement in the same function or its callers
+	stepOut                  // Stop at the next statement in a caller of the function
+)
+
+// stopLine is where the script stopped, so other statements on the same line do not stop at a breakpoint again
+type stopLine struct {
+	thread   int
+	fileName string
+	line     int
+}
+
+// errTerminated ends the script when the client terminates it. As an exit it cannot be caught by the script.
+var errTerminated = errors.Exit(1)
+
+// hook is the executor.DebugHook, pausing the script when it reaches a breakpoint or has been stepped
+func (s *Server) hook(d *executor.DebugState) error {
+	s.hookMutex.Lock()
+	defer s.hookMutex.Unlock()
+
+	s.mutex.Lock()
+	if s.terminated {
+		s.mutex.Unlock()
+		return errTerminated
+	}
+
+	reason := s.stopReason(d)
+	if reason == "" {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.paused = d
+	s.mutex.Unlock()
+
+	if err := s.event("stopped", StoppedEvent{Reason: reason, ThreadID: threadID(d.Thread), AllThreadsStopped: true}); err != nil {
+		return err
+	}
+
+	<-s.resume
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.terminated {
+		return errTerminated
+	}
+	return nil
+}
+
+// stopReason returns why the script should stop at a statement, "" if it should not
+func (s *Server) stopReason(d *executor.DebugState) string {
+	pos := d.Current().Statement.Pos
+	at := stopLine{thread: d.Thread, fileName: pos.Filename, line: pos.Line}
+	sameLine := at == s.lastStop
+
+	reason := ""
+	switch {
+	case s.stopOnEntry:
+		reason = "entry"
+	case s.pauseRequested:
+		reason = "pause"
+	case s.step == stepIn,
+		s.step == stepOver && d.Thread == s.stepThread && d.Depth() <= s.stepDepth,
+		s.step == stepOut && d.Thread == s.stepThread && d.Depth() < s.stepDepth:
+		reason = "step"
+	case !sameLine && s.breakpoints[pos.Filename][pos.Line]:
+		reason = "breakpoint"
+	}
+
+	switch {
+	case reason != "":
+		s.stopOnEntry, s.pauseRequested, s.step = false, false, stepNone
+		s.lastStop = at
+	case !sameLine && d.Thread == s.lastStop.thread:
+		// The thread has left the line so a breakpoint there can stop again, e.g. in a loop
+		s.lastStop = stopLine{}
+	}
+	return reason
+}
+
+// continueWith resumes the paused script, stepping it as requested
+func (s *Server) continueWith(step stepMode) {
+	s.mutex.Lock()
+	d := s.paused
+	if d == nil {
+		s.mutex.Unlock()
+		return
+	}
+
+	s.step, s.stepThread, s.stepDepth = step, d.Thread, d.Depth()
+	s.paused = nil
+	s.references = make(map[int]func() []Variable)
+	s.mutex.Unlock()
+
+	s.resume <- struct{}{}
+}
+
+// terminate ends the script at the next statement it runs
+func (s *Server) terminate() {
+	s.mutex.Lock()
+	s.terminated = true
+	paused := s.paused != nil
+	s.paused = nil
+	s.mutex.Unlock()
+
+	if paused {
+		s.resume <- struct{}{}
+	}
+}
+
+// threads returns the thread running main() and any other thread which is paused
+func (s *Server) threads() []Thread {
+	threads := []Thread{{ID: threadID(0), Name: "main"}}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if d := s.paused; d != nil && d.Thread != 0 {
+		threads = append(threads, Thread{ID: threadID(d.Thread), Name: fmt.Sprintf("goroutine %d", d.Thread)})
+	}
+	return threads
+}
+
+// stackTrace returns the functions being executed by a paused thread, the current one first
+func (s *Server) stackTrace(thread int) []StackFrame {
+	frames := []StackFrame{}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	d := s.paused
+	if d == nil || threadID(d.Thread) != thread {
+		return frames
+	}
+
+	for i, f := range d.Frames {
+		pos := f.Statement.Pos
+		frames = append(frames, StackFrame{
+			ID:     i + 1,
+			Name:   functionName(f.Function),
+			Source: &Source{Name: filepath.Base(pos.Filename), Path: pos.Filename},
+			Line:   pos.Line,
+			Column: pos.Column,
+		})
+	}
+	return frames
+}
+
+// scopes returns the locals, globals and calculator stack of a frame
+func (s *Server) scopes(frameID int) ([]Scope, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := s.frame(frameID)
+	if err != nil {
+		return nil, err
+	}
+	globals := s.paused.Globals
+
+	return []Scope{
+		{Name: "Locals", VariablesReference: s.reference(func() []Variable { return s.scopeVariables(f.Scope, globals) })},
+		{Name: "Globals", VariablesReference: s.reference(func() []Variable { return s.scopeVariables(globals, nil) })},
+		{Name: "Stack", VariablesReference: s.reference(func() []Variable {
+			// The top of the stack first
+			var vars []Variable
+			for i := len(f.Stack) - 1; i >= 0; i-- {
+				vars = append(vars, s.variable(fmt.Sprintf("[%d]", len(f.Stack)-1-i), f.Stack[i]))
+			}
+			return vars
+		})},
+	}, nil
+}
+
+// frame returns a frame of the paused script, which must be called with the mutex held
+func (s *Server) frame(frameID int) (executor.Frame, error) {
+	d := s.paused
+	if d == nil {
+		return executor.Frame{}, fmt.Errorf("not paused")
+	}
+	if frameID < 1 || frameID > len(d.Frames) {
+		return executor.Frame{}, fmt.Errorf("invalid frame %d", frameID)
+	}
+	return d.Frames[frameID-1], nil
+}
+
+// evaluate returns the value of a variable, or of a field within one, e.g. p.X, in a frame
+func (s *Server) evaluate(args EvaluateArguments) (Variable, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := s.frame(args.FrameID)
+	if err != nil {
+		return Variable{}, err
+	}
+
+	names := strings.Split(strings.TrimSpace(args.Expression), ".")
+	v, ok := f.Scope.Get(names[0])
+	for _, n := range names[1:] {
+		if !ok {
+			break
+		}
+		v, ok = field(v, n)
+	}
+	if !ok {
+		return Variable{}, fmt.Errorf("%q is not defined", args.Expression)
+	}
+
+	return s.variable(args.Expression, v), nil
+}
+
+// threadID returns the id of a thread as seen by the client, which must not be 0
+func threadID(thread int) int {
+	return thread + 1
+}
+
+// functionName returns the name of a function as shown in a stack trace
+func functionName(f *script.FuncDec) string {
+	if f.Receiver != nil {
+		return "(" + f.Receiver.Type + ")." + f.Name
+	}
+	return f.Name
+}
@@ -0,0 +1,322 @@
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// Server is a Debug Adapter Protocol server which runs a script under a debugger,
+// e.g. for use by an editor via "goscript debug".
+//
+// It supports line breakpoints, stepping in, over and out of functions, pausing,
+// and inspecting the locals, globals and calculator stack of each function being executed.
+// Functions started by go statements and generators are shown as separate threads.
+type Server struct {
+	parser  parser.Parser
+	opts    []executor.Option // Options for the executor running the script
+	conn    *conn
+	outputs []output // Sent to the client as output events
+
+	exec       executor.Executor // The script once launched
+	configured bool              // true once the client has sent the breakpoints
+	started    bool              // true once the script has been started
+
+	hookMutex      sync.Mutex                // Only one thread can be paused at a time
+	mutex          sync.Mutex                // Guards the state below which is shared with the script
+	breakpoints    map[string]map[int]bool   // Lines with breakpoints by file name
+	stopOnEntry    bool                      // Stop before the first statement
+	step           stepMode                  // How to step when resumed
+	stepThread     int                       // The thread being stepped
+	stepDepth      int                       // The depth of the function being stepped
+	pauseRequested bool                      // Pause at the next statement
+	terminated     bool                      // The client has ended the session
+	lastStop       stopLine                  // Where the script last stopped
+	paused         *executor.DebugState      // The statement the script is paused at, nil if running
+	resume         chan struct{}             // Resumes the paused script
+	references     map[int]func() []Variable // The children of variables shown whilst paused
+	lastReference  int                       // The last reference added to references
+}
+
+type output struct {
+	category string
+	r        io.Reader
+}
+
+// New returns a Server which parses scripts with p and runs them with the supplied options
+func New(p parser.Parser, opts ...executor.Option) *Server {
+	return &Server{
+		parser:      p,
+		opts:        opts,
+		breakpoints: make(map[string]map[int]bool),
+		resume:      make(chan struct{}),
+		references:  make(map[int]func() []Variable),
+	}
+}
+
+// Output sends anything read from r to the client as output of a category, "stdout" or "stderr",
+// e.g. to show what the script prints.
+// It must be called before Serve.
+func (s *Server) Output(category string, r io.Reader) {
+	s.outputs = append(s.outputs, output{category: category, r: r})
+}
+
+// Serve handles requests from r, writing responses and events to w, until the client disconnects or r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for _, o := range s.outputs {
+		go s.forward(o)
+	}
+
+	for {
+		b, err := s.conn.read()
+		if err == io.EOF {
+			s.terminate()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			return err
+		}
+
+		body, err := s.handle(&req)
+
+		resp := response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+		if err != nil {
+			resp.Message = err.Error()
+		}
+		if err := s.conn.write(func(seq int) interface{} { resp.Seq = seq; return resp }); err != nil {
+			return err
+		}
+
+		switch req.Command {
+		case "initialize":
+			// The client can now send the breakpoints
+			if err := s.event("initialized", nil); err != nil {
+				return err
+			}
+
+		case "disconnect":
+			return nil
+		}
+
+		if s.configured && s.exec != nil && !s.started {
+			s.started = true
+			go s.run()
+		}
+	}
+}
+
+// handle processes a request, returning the body of its response
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsTerminateRequest:         true,
+			SupportsEvaluateForHovers:        true,
+		}, nil
+
+	case "launch":
+		var args LaunchArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		return nil, s.launch(args)
+
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		return s.setBreakpoints(args), nil
+
+	case "setExceptionBreakpoints":
+		return map[string]interface{}{"breakpoints": []Breakpoint{}}, nil
+
+	case "configurationDone":
+		s.configured = true
+		return nil, nil
+
+	case "threads":
+		return map[string]interface{}{"threads": s.threads()}, nil
+
+	case "stackTrace":
+		var args ThreadArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		frames := s.stackTrace(args.ThreadID)
+		return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+
+	case "scopes":
+		var args FrameArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		scopes, err := s.scopes(args.FrameID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"scopes": scopes}, nil
+
+	case "variables":
+		var args VariablesArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"variables": s.variables(args.VariablesReference)}, nil
+
+	case "evaluate":
+		var args EvaluateArguments
+		if err := decode(req, &args); err != nil {
+			return nil, err
+		}
+		v, err := s.evaluate(args)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"result": v.Value, "type": v.Type, "variablesReference": v.VariablesReference}, nil
+
+	case "continue":
+		s.continueWith(stepNone)
+		return map[string]interface{}{"allThreadsContinued": true}, nil
+
+	case "next":
+		s.continueWith(stepOver)
+		return nil, nil
+
+	case "stepIn":
+		s.continueWith(stepIn)
+		return nil, nil
+
+	case "stepOut":
+		s.continueWith(stepOut)
+		return nil, nil
+
+	case "pause":
+		s.mutex.Lock()
+		s.pauseRequested = true
+		s.mutex.Unlock()
+		return nil, nil
+
+	case "terminate", "disconnect":
+		s.terminate()
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%s is not supported", req.Command)
+}
+
+// launch prepares the script to be run once the client has sent the breakpoints
+func (s *Server) launch(args LaunchArguments) error {
+	if s.exec != nil {
+		return fmt.Errorf("a script has already been launched")
+	}
+
+	// File names are absolute so they match those of breakpoints and included scripts
+	program, err := filepath.Abs(args.Program)
+	if err != nil {
+		return err
+	}
+
+	sc, err := s.parser.ParseFile(program)
+	if err != nil {
+		return err
+	}
+
+	opts := append(append([]executor.Option{}, s.opts...), executor.WithArgs(args.Args...))
+	if !args.NoDebug {
+		opts = append(opts, executor.WithDebugHook(s.hook))
+	}
+
+	exec, err := executor.New(sc, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range exec.Warnings() {
+		_ = s.output("stderr", fmt.Sprintf("warning: %v\n", w))
+	}
+
+	s.mutex.Lock()
+	s.stopOnEntry = args.StopOnEntry
+	s.mutex.Unlock()
+
+	s.exec = exec
+	return nil
+}
+
+// run runs the script, telling the client once it has ended
+func (s *Server) run() {
+	code, err := s.exec.RunStatus()
+	if err != nil {
+		_ = s.output("stderr", err.Error()+"\n")
+		code = 1
+	}
+
+	_ = s.event("exited", ExitedEvent{ExitCode: code})
+	_ = s.event("terminated", nil)
+}
+
+func (s *Server) setBreakpoints(args SetBreakpointsArguments) map[string]interface{} {
+	fileName, err := filepath.Abs(args.Source.Path)
+	if err != nil {
+		fileName = args.Source.Path
+	}
+
+	lines := make(map[int]bool)
+	breakpoints := []Breakpoint{}
+	for _, bp := range args.Breakpoints {
+		lines[bp.Line] = true
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: bp.Line, Source: &args.Source})
+	}
+
+	s.mutex.Lock()
+	s.breakpoints[fileName] = lines
+	s.mutex.Unlock()
+
+	return map[string]interface{}{"breakpoints": breakpoints}
+}
+
+// forward sends the output of the script to the client
+func (s *Server) forward(o output) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := o.r.Read(buf)
+		if n > 0 {
+			if s.output(o.category, string(buf[:n])) != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) output(category, text string) error {
+	return s.event("output", OutputEvent{Category: category, Output: text})
+}
+
+func (s *Server) event(name string, body interface{}) error {
+	return s.conn.write(func(seq int) interface{} {
+		return event{Seq: seq, Type: "event", Event: name, Body: body}
+	})
+}
+
+func decode(req *request, v interface{}) error {
+	if len(req.Arguments) == 0 {
+		return nil
+	}
+	return json.Unmarshal(req.Arguments, v)
+}
@@ -0,0 +1,269 @@
+package dap
+
+import (
+	"encoding/json"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+const testScript = `type Point struct { X, Y }
+add(a, b) {
+    c := a + b
+    return c
+}
+main() {
+    p := Point(1, 2)
+    x := 1
+    x = 10 + add(x, p.Y)
+    result = x
+}
+`
+
+// client talks to a Server over pipes as an editor would
+type client struct {
+	t      *testing.T
+	conn   *conn
+	events []event // Events received whilst waiting for a response
+	done   chan error
+}
+
+// message is a response or event received from the server
+type message struct {
+	Type       string          `json:"type"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Event      string          `json:"event"`
+	Body       json.RawMessage `json:"body"`
+}
+
+func newClient(t *testing.T) (*client, string) {
+	fileName := filepath.Join(t.TempDir(), "test.c")
+	if err := os.WriteFile(fileName, []byte(testScript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	c := &client{t: t, conn: newConn(outR, inW), done: make(chan error, 1)}
+	go func() {
+		c.done <- New(parser.New()).Serve(inR, outW)
+	}()
+	return c, fileName
+}
+
+// request sends a request, returning the body of its response
+func (c *client) request(command string, args interface{}, body interface{}) {
+	var seq int
+	if err := c.conn.write(func(s int) interface{} {
+		seq = s
+		return struct {
+			Seq       int         `json:"seq"`
+			Type      string      `json:"type"`
+			Command   string      `json:"command"`
+			Arguments interface{} `json:"arguments,omitempty"`
+		}{Seq: s, Type: "request", Command: command, Arguments: args}
+	}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	for {
+		m := c.read()
+		if m.Type == "event" {
+			c.events = append(c.events, event{Event: m.Event, Body: m.Body})
+			continue
+		}
+		if m.RequestSeq != seq {
+			continue
+		}
+		if !m.Success {
+			c.t.Fatalf("%s failed: %s", command, m.Message)
+		}
+		if body != nil {
+			if err := json.Unmarshal(m.Body, body); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return
+	}
+}
+
+// event waits for an event, returning its body
+func (c *client) event(name string, body interface{}) {
+	for {
+		var e event
+		if len(c.events) > 0 {
+			e, c.events = c.events[0], c.events[1:]
+		} else {
+			m := c.read()
+			if m.Type != "event" {
+				continue
+			}
+			e = event{Event: m.Event, Body: m.Body}
+		}
+
+		if e.Event != name {
+			continue
+		}
+		if body != nil {
+			if err := json.Unmarshal(e.Body.(json.RawMessage), body); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return
+	}
+}
+
+func (c *client) read() message {
+	b, err := c.conn.read()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var m message
+	if err := json.Unmarshal(b, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// stopped waits for the script to stop, returning the reason and the function and line it stopped at
+func (c *client) stopped() []string {
+	var stopped StoppedEvent
+	c.event("stopped", &stopped)
+
+	var trace struct {
+		StackFrames []StackFrame `json:"stackFrames"`
+	}
+	c.request("stackTrace", ThreadArguments{ThreadID: stopped.ThreadID}, &trace)
+
+	r := []string{stopped.Reason}
+	for _, f := range trace.StackFrames {
+		r = append(r, f.Name+":"+strconv.Itoa(f.Line))
+	}
+	return r
+}
+
+// variables returns the values of a scope of a frame
+func (c *client) variables(frameID int, scope string) map[string]string {
+	var scopes struct {
+		Scopes []Scope `json:"scopes"`
+	}
+	c.request("scopes", FrameArguments{FrameID: frameID}, &scopes)
+
+	r := map[string]string{}
+	for _, s := range scopes.Scopes {
+		if s.Name == scope {
+			var vars struct {
+				Variables []Variable `json:"variables"`
+			}
+			c.request("variables", VariablesArguments{VariablesReference: s.VariablesReference}, &vars)
+			for _, v := range vars.Variables {
+				r[v.Name] = v.Value
+			}
+		}
+	}
+	return r
+}
+
+func (c *client) start(fileName string, stopOnEntry bool, lines ...int) {
+	c.request("initialize", map[string]interface{}{"adapterID": "goscript"}, nil)
+	c.event("initialized", nil)
+	c.request("launch", LaunchArguments{Program: fileName, StopOnEntry: stopOnEntry}, nil)
+
+	var breakpoints []SourceBreakpoint
+	for _, l := range lines {
+		breakpoints = append(breakpoints, SourceBreakpoint{Line: l})
+	}
+	c.request("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: fileName}, Breakpoints: breakpoints}, nil)
+	c.request("configurationDone", nil, nil)
+}
+
+func (c *client) disconnect() {
+	c.request("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// Test_Server tests a debugging session with breakpoints, stepping and inspecting variables
+func Test_Server(t *testing.T) {
+	c, fileName := newClient(t)
+	c.start(fileName, false, 3, 9)
+
+	if got, want := c.stopped(), []string{"breakpoint", "main:9"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+	if got := c.variables(1, "Locals"); got["x"] != "1" || got["p"] != "Point{X:1 Y:2}" {
+		t.Errorf("expected x and p got %v", got)
+	}
+
+	c.request("stepIn", nil, nil)
+	if got, want := c.stopped(), []string{"step", "add:3", "main:9"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+	if got, want := c.variables(1, "Locals"), map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	if got, want := c.variables(2, "Stack"), map[string]string{"[0]": "10"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected caller stack %v got %v", want, got)
+	}
+
+	c.request("next", nil, nil)
+	if got, want := c.stopped(), []string{"step", "add:4", "main:9"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+
+	var result struct {
+		Result string `json:"result"`
+	}
+	c.request("evaluate", EvaluateArguments{Expression: "c", FrameID: 1}, &result)
+	if result.Result != "3" {
+		t.Errorf("expected c 3 got %q", result.Result)
+	}
+	c.request("evaluate", EvaluateArguments{Expression: "p.Y", FrameID: 2}, &result)
+	if result.Result != "2" {
+		t.Errorf("expected p.Y 2 got %q", result.Result)
+	}
+
+	c.request("stepOut", nil, nil)
+	if got, want := c.stopped(), []string{"step", "main:10"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+
+	c.request("continue", nil, nil)
+	var exited ExitedEvent
+	c.event("exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("expected exit code 0 got %d", exited.ExitCode)
+	}
+	c.event("terminated", nil)
+
+	c.disconnect()
+}
+
+// Test_Server_terminate tests ending a paused script
+func Test_Server_terminate(t *testing.T) {
+	c, fileName := newClient(t)
+	c.start(fileName, true)
+
+	if got, want := c.stopped(), []string{"entry", "main:7"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+
+	c.request("terminate", nil, nil)
+	var exited ExitedEvent
+	c.event("exited", &exited)
+	if exited.ExitCode != 1 {
+		t.Errorf("expected exit code 1 got %d", exited.ExitCode)
+	}
+	c.event("terminated", nil)
+
+	c.disconnect()
+}
@@ -0,0 +1,169 @@
+package dap
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/state"
+	"reflect"
+	"sort"
+)
+
+// reference returns the variablesReference the client uses to request the variables returned by f.
+// References are only valid until the script is resumed. This must be called with the mutex held.
+func (s *Server) reference(f func() []Variable) int {
+	s.lastReference++
+	s.references[s.lastReference] = f
+	return s.lastReference
+}
+
+// variables returns the variables for a reference
+func (s *Server) variables(ref int) []Variable {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	vars := []Variable{}
+	if f, ok := s.references[ref]; ok {
+		vars = append(vars, f()...)
+	}
+	return vars
+}
+
+// scopeVariables returns the variables visible from a scope, stopping at the scope end, e.g. the globals
+// for the locals of a function. Variables in inner scopes hide those with the same name in outer ones.
+func (s *Server) scopeVariables(scope, end state.Variables) []Variable {
+	var vars []Variable
+	seen := make(map[string]bool)
+	for ; scope != nil && scope != end; scope = scope.Parent() {
+		for _, n := range scope.Names() {
+			if !seen[n] {
+				seen[n] = true
+				v, _ := scope.Get(n)
+				vars = append(vars, s.variable(n, v))
+			}
+		}
+	}
+
+	sort.SliceStable(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// variable returns a Variable for a value, with a reference to its fields or elements if it has any
+func (s *Server) variable(name string, v interface{}) Variable {
+	r := Variable{Name: name, Value: formatValue(v), Type: typeName(v)}
+	if hasChildren(v) {
+		r.VariablesReference = s.reference(func() []Variable { return s.children(v) })
+	}
+	return r
+}
+
+// hasChildren returns true if a value has fields or elements to show
+func hasChildren(v interface{}) bool {
+	if _, ok := v.(*executor.Record); ok {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Pointer:
+		return !rv.IsNil() && rv.Elem().Kind() == reflect.Struct
+	case reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// children returns the fields of a record or struct, the elements of a slice or the entries of a map
+func (s *Server) children(v interface{}) []Variable {
+	var vars []Variable
+
+	if r, ok := v.(*executor.Record); ok {
+		for _, n := range r.Type().Fields {
+			fv, _ := r.Get(n)
+			vars = append(vars, s.variable(n, fv))
+		}
+		return vars
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			vars = append(vars, s.variable(fmt.Sprintf("[%d]", i), rv.Index(i).Interface()))
+		}
+
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			vars = append(vars, s.variable(formatValue(k.Interface()), rv.MapIndex(k).Interface()))
+		}
+
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				vars = append(vars, s.variable(f.Name, rv.Field(i).Interface()))
+			}
+		}
+	}
+
+	return vars
+}
+
+// field returns a field of a record, struct or map
+func field(v interface{}, name string) (interface{}, bool) {
+	if r, ok := v.(*executor.Record); ok {
+		return r.Get(name)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			if fv := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())); fv.IsValid() {
+				return fv.Interface(), true
+			}
+		}
+
+	case reflect.Struct:
+		if f, ok := rv.Type().FieldByName(name); ok && f.IsExported() {
+			return rv.FieldByIndex(f.Index).Interface(), true
+		}
+	}
+
+	return nil, false
+}
+
+// formatValue returns a value as it would be written in a script
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case *executor.Record:
+		return v.TypeName()
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
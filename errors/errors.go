@@ -7,11 +7,6 @@ import (
 )
 
 var (
-	// error used to implement break
-	breakError = errors.New("break")
-	// error used to implement continue
-	continueError = errors.New("continue")
-
 	// VisitorExit is an error which will terminate the Visitor.
 	// This is the same as any error occurring within a Visitor except that the final error
 	// returned from specific handlers will become nil.
@@ -24,17 +19,29 @@ var (
 )
 
 type posError struct {
+	pos lexer.Position
 	msg string
 }
 
 func (e posError) Error() string {
+	return e.pos.String() + " " + e.msg
+}
+
+// Message returns the message without the position.
+// With Position this implements participle.Error so script errors can be handled like parser errors.
+func (e posError) Message() string {
 	return e.msg
 }
 
+// Position returns the position the error occurred
+func (e posError) Position() lexer.Position {
+	return e.pos
+}
+
 // Errorf returns an error containing the lexer.Position and the formatted message.
 // IsError with this error will return true.
 func Errorf(pos lexer.Position, f string, a ...interface{}) error {
-	return &posError{msg: fmt.Sprintf(pos.String()+" "+f, a...)}
+	return &posError{pos: pos, msg: fmt.Sprintf(f, a...)}
 }
 
 // Error wraps an error with the lexer.Position.
@@ -44,8 +51,8 @@ func Errorf(pos lexer.Position, f string, a ...interface{}) error {
 func Error(pos lexer.Position, err error) error {
 	// If err is a PosError then return it as it has the position already.
 	// Also, if err is nil then return nil, so we can use it as a catch-all
-	// Break and return dummy errors also are unchanged
-	if err == nil || IsError(err) || IsBreak(err) || IsContinue(err) || IsReturn(err) || IsNoFieldErr(err) || IsVisitorStop(err) || IsVisitorExit(err) {
+	// Return dummy errors also are unchanged
+	if err == nil || IsError(err) || IsReturn(err) || IsExit(err) || IsNoFieldErr(err) || IsVisitorStop(err) || IsVisitorExit(err) {
 		return err
 	}
 	return Errorf(pos, err.Error())
@@ -60,9 +67,22 @@ func IsError(err error) bool {
 	return ok
 }
 
+// TypeNamer is implemented by values of a type declared within a script,
+// so that errors refer to that type rather than the go type holding the value.
+type TypeNamer interface {
+	TypeName() string
+}
+
+func typeName(v interface{}) string {
+	if t, ok := v.(TypeNamer); ok {
+		return t.TypeName()
+	}
+	return fmt.Sprintf("%T", v)
+}
+
 func NoField(pos lexer.Position, v interface{}, n string) error {
 	return &NoFieldError{
-		msg: fmt.Sprintf("%s %T has no field %q", pos.String(), v, n),
+		msg: fmt.Sprintf("%s %s has no field %q", pos.String(), typeName(v), n),
 		v:   v,
 		n:   n,
 	}
@@ -102,25 +122,15 @@ func GetNoFieldErr(err error) (*NoFieldError, bool) {
 	return e, ok
 }
 
-func Break() error {
-	return breakError
-}
-
-// IsBreak returns true if err is from a break instruction being invoked.
-func IsBreak(err error) bool { return err == breakError }
-
-func Continue() error {
-	return continueError
-}
-
-// IsContinue returns true if err is from a continue instruction being invoked.
-func IsContinue(err error) bool { return err == continueError }
-
 func IsReturn(err error) bool {
 	_, ok := err.(*ReturnError)
 	return ok
 }
 
+// ReturnError allows a function registered with the executor to return a value.
+//
+// Deprecated: functions should push their result onto the Calculator instead.
+// Statements no longer use errors to implement return, break or continue.
 type ReturnError struct {
 	value interface{}
 }
@@ -133,10 +143,47 @@ func (r *ReturnError) Value() interface{} {
 	return r.value
 }
 
+// NewReturn returns a ReturnError for a value.
+//
+// Deprecated: functions should push their result onto the Calculator instead.
 func NewReturn(v interface{}) error {
 	return &ReturnError{value: v}
 }
 
+// ExitError ends a script with a status code, e.g. when it calls exit(1).
+//
+// Unlike other errors it cannot be caught by try, or replaced by a finally block or deferred statement,
+// and it is never wrapped with a position so the code can always be retrieved with GetExit.
+type ExitError struct {
+	code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// Code returns the status code the script exited with
+func (e *ExitError) Code() int {
+	return e.code
+}
+
+// Exit returns an ExitError with a status code
+func Exit(code int) error {
+	return &ExitError{code: code}
+}
+
+// IsExit returns true if err is an ExitError
+func IsExit(err error) bool {
+	_, ok := err.(*ExitError)
+	return ok
+}
+
+// GetExit returns the ExitError if err is one
+func GetExit(err error) (*ExitError, bool) {
+	e, ok := err.(*ExitError)
+	return e, ok
+}
+
 // IsVisitorStop returns true if err is VisitorStop
 func IsVisitorStop(err error) bool {
 	return err != nil && errors.Is(err, VisitorStop)
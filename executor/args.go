@@ -7,6 +7,11 @@ import (
 )
 
 func Args(e Executor, call *script.CallFunc) ([]interface{}, error) {
+	// Arguments already evaluated by bindCall
+	if ex, ok := e.(*executor); ok && ex.bound != nil && ex.bound.cf == call {
+		return ex.bound.args, nil
+	}
+
 	calc := e.Calculator()
 
 	var a []interface{}
@@ -60,12 +65,12 @@ func (f Function) RequireArgsRange(min, max int) Function {
 		}
 		switch {
 		case min == max && l != min:
-			return errors.Errorf(call.Pos, "%s requires %d arguments")
+			return errors.Errorf(call.Pos, "%s requires %d arguments", call.Name, min)
 
 		case l < min:
-			return errors.Errorf(call.Pos, "%s requires minimum of %d arguments")
+			return errors.Errorf(call.Pos, "%s requires minimum of %d arguments", call.Name, min)
 		case l > max:
-			return errors.Errorf(call.Pos, "%s requires maximum of %d arguments")
+			return errors.Errorf(call.Pos, "%s requires maximum of %d arguments", call.Name, max)
 		}
 		return nil
 	})
@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"github.com/peter-mount/go-script/calculator"
+	"reflect"
+	"sync"
+)
+
+// typeCache holds the reflective lookups of each reflect.Type seen by any executor.
+// It is shared between executors so a lookup only has to be made once per process.
+var typeCache sync.Map
+
+// typeInfo caches the method and field lookups for a single reflect.Type.
+//
+// Negative lookups are also cached, so a missing method or field is not searched for
+// each time it's referenced.
+type typeInfo struct {
+	typ     reflect.Type
+	mutex   sync.RWMutex
+	methods map[string]int   // method index, -1 if the type has no such method
+	fields  map[string][]int // field index path, nil if the type has no such field
+	plan    *funcPlan        // argument conversion plan if typ is a func
+}
+
+// getTypeInfo returns the typeInfo for a type, creating it if required
+func getTypeInfo(t reflect.Type) *typeInfo {
+	if ti, ok := typeCache.Load(t); ok {
+		return ti.(*typeInfo)
+	}
+
+	ti, _ := typeCache.LoadOrStore(t, &typeInfo{
+		typ:     t,
+		methods: make(map[string]int),
+		fields:  make(map[string][]int),
+	})
+	return ti.(*typeInfo)
+}
+
+// methodIndex returns the index of a named method, -1 if it does not exist
+func (ti *typeInfo) methodIndex(name string) int {
+	ti.mutex.RLock()
+	idx, exists := ti.methods[name]
+	ti.mutex.RUnlock()
+
+	if !exists {
+		idx = -1
+		if m, ok := ti.typ.MethodByName(name); ok {
+			idx = m.Index
+		}
+
+		ti.mutex.Lock()
+		ti.methods[name] = idx
+		ti.mutex.Unlock()
+	}
+
+	return idx
+}
+
+// fieldIndex returns the index path of a named field in a struct, nil if it does not exist
+func (ti *typeInfo) fieldIndex(name string) []int {
+	ti.mutex.RLock()
+	idx, exists := ti.fields[name]
+	ti.mutex.RUnlock()
+
+	if !exists {
+		if ti.typ.Kind() == reflect.Struct {
+			if f, ok := ti.typ.FieldByName(name); ok {
+				idx = f.Index
+			}
+		}
+
+		ti.mutex.Lock()
+		ti.fields[name] = idx
+		ti.mutex.Unlock()
+	}
+
+	return idx
+}
+
+// funcPlan returns the argument conversion plan for a func type
+func (ti *typeInfo) funcPlan() *funcPlan {
+	ti.mutex.RLock()
+	plan := ti.plan
+	ti.mutex.RUnlock()
+
+	if plan == nil {
+		plan = newFuncPlan(ti.typ)
+
+		ti.mutex.Lock()
+		ti.plan = plan
+		ti.mutex.Unlock()
+	}
+
+	return plan
+}
+
+// methodByName is the equivalent of reflect.Value.MethodByName but using the typeCache
+func methodByName(v reflect.Value, name string) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+
+	idx := getTypeInfo(v.Type()).methodIndex(name)
+	if idx < 0 {
+		return reflect.Value{}
+	}
+
+	return v.Method(idx)
+}
+
+// fieldByName is the equivalent of reflect.Value.FieldByName but using the typeCache.
+// v must be a struct.
+//
+// Unlike FieldByName, this will return an invalid Value if the field is within a
+// nil embedded pointer rather than panicking.
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	idx := getTypeInfo(v.Type()).fieldIndex(name)
+	if idx == nil {
+		return reflect.Value{}
+	}
+
+	f, err := v.FieldByIndexErr(idx)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return f
+}
+
+// funcPlan describes how to convert script arguments & return values for a go function.
+type funcPlan struct {
+	numIn    int                   // Number of fixed parameters, excluding any variadic one
+	variadic bool                  // true if the function is variadic
+	in       []calculator.CastFunc // Conversion of each fixed parameter
+	varIn    calculator.CastFunc   // Conversion of variadic parameters
+	out      []bool                // true if the return value at that index is an error
+}
+
+func newFuncPlan(tf reflect.Type) *funcPlan {
+	plan := &funcPlan{
+		numIn:    tf.NumIn(),
+		variadic: tf.IsVariadic(),
+	}
+
+	// If it's variadic then we take the last one off as we handle that
+	// last one specially due to it being a slice.
+	if plan.variadic {
+		plan.numIn--
+		// last element is actually a slice so call Elem to get the actual type
+		plan.varIn = calculator.Caster(tf.In(plan.numIn).Elem())
+	}
+
+	for i := 0; i < plan.numIn; i++ {
+		plan.in = append(plan.in, calculator.Caster(tf.In(i)))
+	}
+
+	for i := 0; i < tf.NumOut(); i++ {
+		plan.out = append(plan.out, tf.Out(i).Implements(errorInterface))
+	}
+
+	return plan
+}
@@ -0,0 +1,190 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+	"reflect"
+)
+
+// channelValue returns v as a channel which can be used in the direction dir.
+// A null channel is returned as the zero Value, which is only valid within a select.
+func channelValue(v interface{}, dir reflect.ChanDir) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Value{}, nil
+	}
+
+	cv := reflect.ValueOf(v)
+	if cv.Kind() != reflect.Chan {
+		return reflect.Value{}, fmt.Errorf("%T is not a channel", v)
+	}
+
+	if cv.Type().ChanDir()&dir == 0 {
+		if dir == reflect.SendDir {
+			return reflect.Value{}, fmt.Errorf("cannot send to receive-only channel %T", v)
+		}
+		return reflect.Value{}, fmt.Errorf("cannot receive from send-only channel %T", v)
+	}
+
+	return cv, nil
+}
+
+// send implements ch <- v, sending a value to a channel.
+// This blocks until the value is received, or there is room in the channel's buffer.
+func (e *executor) send(op *script.Assignment) (err error) {
+	if op.AugmentedOp != nil || op.Declare {
+		return errors.Errorf(op.Pos, "invalid send")
+	}
+
+	ch, val, err := e.sendValue(op)
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+	if !ch.IsValid() {
+		return errors.Errorf(op.Pos, "send to null channel")
+	}
+
+	// Sending to a closed channel panics
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = errors.Errorf(op.Pos, "%v", err1)
+		}
+	}()
+
+	ch.Send(val)
+	return nil
+}
+
+// sendValue evaluates the channel and value of a send, converting the value to the channel's element type
+func (e *executor) sendValue(op *script.Assignment) (reflect.Value, reflect.Value, error) {
+	ch, err := e.calculator.MustCalculate(func() error { return e.ternary(op.Left) })
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+
+	v, err := e.calculator.MustCalculate(func() error { return e.assignment(op.Right) })
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+
+	cv, err := channelValue(ch, reflect.SendDir)
+	if err != nil || !cv.IsValid() {
+		return cv, reflect.Value{}, err
+	}
+
+	val, err := assignableValue(v, cv.Type().Elem())
+	return cv, val, err
+}
+
+// receive implements <-ch, replacing the channel on the stack with the value received from it.
+// This blocks until a value is available.
+//
+// If the channel is closed then the zero value of the channel's element type is returned, null for chan().
+func (e *executor) receive() error {
+	ch, err := e.calculator.Pop()
+	if err != nil {
+		return err
+	}
+
+	cv, err := channelValue(ch, reflect.RecvDir)
+	if err != nil {
+		return err
+	}
+	if !cv.IsValid() {
+		return fmt.Errorf("receive from null channel")
+	}
+
+	v, _ := cv.Recv()
+	e.calculator.Push(v.Interface())
+	return nil
+}
+
+// selectStatement implements select, waiting until one of its cases can proceed and then
+// running that case's statement.
+//
+// As in go, if more than one case can proceed then one is chosen at random, a case with a
+// null channel never proceeds, and the default clause, if present, runs if no case can proceed immediately.
+func (e *executor) selectStatement(op *script.Select) Completion {
+	cases := make([]reflect.SelectCase, 0, len(op.Case)+1)
+	for _, c := range op.Case {
+		sc, err := e.selectCase(c)
+		if err != nil {
+			return errorCompletion(c.Pos, err)
+		}
+		cases = append(cases, sc)
+	}
+
+	if op.Default != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	chosen, v, err := e.doSelect(op, cases)
+	if err != nil {
+		return errorCompletion(op.Pos, err)
+	}
+
+	if chosen == len(op.Case) {
+		return e.Statement(op.Default).WithPos(op.Pos)
+	}
+
+	c := op.Case[chosen]
+
+	// The case runs in its own scope, so v := <-ch is only visible within it
+	e.state.NewScope()
+	defer e.state.EndScope()
+
+	if _, name, declare := c.Receive(); name != "" {
+		if declare {
+			e.state.Declare(name)
+		}
+		if !e.state.Set(name, v) {
+			e.state.Declare(name)
+			_ = e.state.Set(name, v)
+		}
+	}
+
+	return e.Statement(c.Statement).WithPos(c.Pos)
+}
+
+// selectCase evaluates the channel, and for a send the value, of a case within a select
+func (e *executor) selectCase(c *script.SelectCase) (reflect.SelectCase, error) {
+	if send := c.Send(); send != nil {
+		ch, val, err := e.sendValue(send)
+		if err != nil {
+			return reflect.SelectCase{}, errors.Error(c.Pos, err)
+		}
+		return reflect.SelectCase{Dir: reflect.SelectSend, Chan: ch, Send: val}, nil
+	}
+
+	recv, _, _ := c.Receive()
+	if recv == nil {
+		return reflect.SelectCase{}, errors.Errorf(c.Pos, "select case must be a channel send or receive")
+	}
+
+	ch, err := e.calculator.MustCalculate(func() error { return e.unary(recv.Left) })
+	if err == nil {
+		var cv reflect.Value
+		cv, err = channelValue(ch, reflect.RecvDir)
+		if err == nil {
+			return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: cv}, nil
+		}
+	}
+	return reflect.SelectCase{}, errors.Error(c.Pos, err)
+}
+
+// doSelect performs the select, returning the index of the chosen case and, if it was a receive,
+// the value received
+func (e *executor) doSelect(op *script.Select, cases []reflect.SelectCase) (chosen int, v interface{}, err error) {
+	// Sending to a closed channel panics
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = errors.Errorf(op.Pos, "%v", err1)
+		}
+	}()
+
+	chosen, rv, _ := reflect.Select(cases)
+	if rv.IsValid() {
+		v = rv.Interface()
+	}
+	return chosen, v, nil
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/errors"
+)
+
+// CompletionType describes how a Statement completed
+type CompletionType uint8
+
+const (
+	// Normal completion, execution continues with the next Statement
+	Normal CompletionType = iota
+	// Break terminates the enclosing loop
+	Break
+	// Continue starts the next iteration of the enclosing loop
+	Continue
+	// Return exits the current function, Completion.Value holding the result
+	Return
+	// Error terminates execution, Completion.Err holding the cause
+	Error
+)
+
+// Completion is the result of executing a Statement.
+//
+// Control flow like break, continue and return are signalled by the Type of the Completion,
+// so an error is only ever used for a real error.
+type Completion struct {
+	Type  CompletionType
+	Value interface{} // The result of a Return completion
+	Err   error       // The error of an Error completion
+}
+
+// normal returns a Normal Completion
+func normal() Completion {
+	return Completion{}
+}
+
+// errorCompletion returns an Error Completion for err, wrapping it with the position.
+// If err is nil then a Normal Completion is returned.
+func errorCompletion(pos lexer.Position, err error) Completion {
+	if err == nil {
+		return normal()
+	}
+	return Completion{Type: Error, Err: errors.Error(pos, err)}
+}
+
+// returnCompletion returns a Return Completion with the returned value
+func returnCompletion(v interface{}) Completion {
+	return Completion{Type: Return, Value: v}
+}
+
+// IsNormal returns true if execution should continue with the next Statement
+func (c Completion) IsNormal() bool {
+	return c.Type == Normal
+}
+
+// IsError returns true if the Completion is the result of an error
+func (c Completion) IsError() bool {
+	return c.Type == Error
+}
+
+// isExit returns true if the Completion is the result of the script calling exit()
+func (c Completion) isExit() bool {
+	return c.Type == Error && errors.IsExit(c.Err)
+}
+
+// WithPos ensures the error in an Error Completion has a position.
+// Any other Completion is returned unchanged.
+func (c Completion) WithPos(pos lexer.Position) Completion {
+	if c.Type == Error {
+		c.Err = errors.Error(pos, c.Err)
+	}
+	return c
+}
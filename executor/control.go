@@ -28,59 +28,59 @@ func (e *executor) condition(expr *script.Expression, defaultResult bool) (bool,
 	return b, errors.Error(expr.Pos, err)
 }
 
-// breakOrContinue checks for errors, break and continue statements.
-// the bool is true if the loop should be terminated, false to continue or no error.
-// error will be the error to return, or nil if no error.
-func (e *executor) breakOrContinue(pos lexer.Position, err error) (bool, error) {
+// loopBody processes the Completion of the body of a loop.
+// The bool is true if the loop should be terminated, false to continue with the next iteration.
+// The Completion is the one the loop should complete with when it terminates.
+func loopBody(pos lexer.Position, c Completion) (bool, Completion) {
+	switch c.Type {
 	// Consume break and exit the loop
-	if errors.IsBreak(err) {
-		return true, nil
-	}
+	case Break:
+		return true, normal()
 
 	// Consume continue
-	if errors.IsContinue(err) {
-		return false, nil
-	}
+	case Normal, Continue:
+		return false, normal()
 
-	// a normal error
-	return err != nil, errors.Error(pos, err)
+	// return or an error
+	default:
+		return true, c.WithPos(pos)
+	}
 }
 
-func (e *executor) ifStatement(s *script.If) error {
+func (e *executor) ifStatement(s *script.If) Completion {
 
 	b, err := e.condition(s.Condition, true)
-	if err == nil {
-		if b {
-			err = e.Statement(s.Body)
-		} else {
-			err = e.Statement(s.Else)
-		}
+	if err != nil {
+		return errorCompletion(s.Pos, err)
 	}
 
-	return errors.Error(s.Pos, err)
+	if b {
+		return e.Statement(s.Body).WithPos(s.Pos)
+	}
+	return e.Statement(s.Else).WithPos(s.Pos)
 }
 
 // repeatUntil from basic etc. repeats body until condition is met.
 // body is always evaluated once.
-func (e *executor) repeatUntil(s *script.Repeat) error {
+func (e *executor) repeatUntil(s *script.Repeat) Completion {
 	return e.forLoop(s.Pos, nil, nil, s.Body, nil, s.Condition, false)
 }
 
 // doWhile from C, repeats body while condition is met.
 // body is always executed once.
-func (e *executor) doWhile(s *script.DoWhile) error {
+func (e *executor) doWhile(s *script.DoWhile) Completion {
 	return e.forLoop(s.Pos, nil, nil, s.Body, nil, s.Condition, true)
 }
 
 // while from C, execute body while condition is met.
 // body will never run if condition never passes
-func (e *executor) while(s *script.While) error {
+func (e *executor) while(s *script.While) Completion {
 	return e.forLoop(s.Pos, nil, s.Condition, s.Body, nil, nil, true)
 }
 
 // forStatement from C, optional init & increment but executes body while condition is met.
 // body will never run if condition never passes.
-func (e *executor) forStatement(s *script.For) error {
+func (e *executor) forStatement(s *script.For) Completion {
 	return e.forLoop(s.Pos, s.Init, s.Condition, s.Body, s.Increment, nil, true)
 }
 
@@ -92,7 +92,7 @@ func (e *executor) forStatement(s *script.For) error {
 // inc is the optional increment Expression
 // conditionLast is the condition test performed at the end of the loop
 // conditionResult the result of conditionFirst or conditionLast to repeat the loop.
-func (e *executor) forLoop(p lexer.Position, init, conditionFirst *script.Expression, body *script.Statement, inc, conditionLast *script.Expression, conditionResult bool) error {
+func (e *executor) forLoop(p lexer.Position, init, conditionFirst *script.Expression, body *script.Statement, inc, conditionLast *script.Expression, conditionResult bool) Completion {
 
 	// Run for in a new scope so variables declared there are not accessible outside
 	e.state.NewScope()
@@ -101,39 +101,38 @@ func (e *executor) forLoop(p lexer.Position, init, conditionFirst *script.Expres
 	if init != nil {
 		err := e.Expression(init)
 		if err != nil {
-			return errors.Error(p, err)
+			return errorCompletion(p, err)
 		}
 	}
 
 	for {
 		b, err := e.condition(conditionFirst, conditionResult)
 		if err != nil || b != conditionResult {
-			return errors.Error(p, err)
+			return errorCompletion(p, err)
 		}
 
 		if body != nil {
-			exit, err1 := e.breakOrContinue(p, e.Statement(body))
-			if exit {
-				return err1
+			if exit, c := loopBody(p, e.Statement(body)); exit {
+				return c
 			}
 		}
 
 		if inc != nil {
 			err = e.Expression(inc)
 			if err != nil {
-				return errors.Error(p, err)
+				return errorCompletion(p, err)
 			}
 		}
 
 		// conditionResult false then condition last
 		b, err = e.condition(conditionLast, conditionResult)
 		if err != nil || b != conditionResult {
-			return errors.Error(p, err)
+			return errorCompletion(p, err)
 		}
 	}
 }
 
-func (e *executor) forRange(op *script.ForRange) error {
+func (e *executor) forRange(op *script.ForRange) Completion {
 	// Run for in a new scope so variables declared there are not accessible outside
 	e.state.NewScope()
 	defer e.state.EndScope()
@@ -147,7 +146,7 @@ func (e *executor) forRange(op *script.ForRange) error {
 	// Evaluate Expression
 	r, err := e.calculator.MustCalculate(func() error { return e.Expression(op.Expression) })
 	if err != nil {
-		return errors.Error(op.Pos, err)
+		return errorCompletion(op.Pos, err)
 	}
 
 	// Check for supported extensions
@@ -171,8 +170,21 @@ func (e *executor) forRange(op *script.ForRange) error {
 	case reflect.Array, reflect.Slice, reflect.String:
 		return e.forSlice(op, ti)
 
+	case reflect.Chan:
+		cv, err := channelValue(r, reflect.RecvDir)
+		if err != nil {
+			return errorCompletion(op.Pos, errors.Error(op.Expression.Pos, err))
+		}
+		return e.forChannel(op, cv)
+
+	case reflect.Func:
+		if seq, ok := seqYield(ti.Type()); ok {
+			return e.forSeq(op, ti, seq)
+		}
+		return errorCompletion(op.Pos, errors.Errorf(op.Expression.Pos, "cannot range over %T", r))
+
 	default:
-		return errors.Errorf(op.Expression.Pos, "cannot range over %T", r)
+		return errorCompletion(op.Pos, errors.Errorf(op.Expression.Pos, "cannot range over %T", r))
 	}
 }
 
@@ -181,57 +193,140 @@ func (e *executor) forRange(op *script.ForRange) error {
 //
 // Unlike go, as we require both variables in our for range statement, both variables
 // are set to the same index value.
-func (e *executor) forInteger(op *script.ForRange, limit int) error {
+func (e *executor) forInteger(op *script.ForRange, limit int) Completion {
 	if limit > 0 {
 		for i := 0; i < limit; i++ {
-			exit, err := e.breakOrContinue(op.Pos, e.forRangeEntry(i, i, op))
-			if exit {
-				return err
+			if exit, c := loopBody(op.Pos, e.forRangeEntry(i, i, op)); exit {
+				return c
 			}
 		}
 	}
-	return nil
+	return normal()
 }
 
 // forIterator will iterate for all values in an Iterator
-func (e *executor) forIterator(op *script.ForRange, it util.Iterator[interface{}]) error {
+func (e *executor) forIterator(op *script.ForRange, it util.Iterator[interface{}]) Completion {
+	// Stop a Generator if we leave the loop before it has completed
+	if g, ok := it.(*Generator); ok {
+		defer g.Stop()
+	}
+
 	for i := 0; it.HasNext(); i++ {
-		exit, err := e.breakOrContinue(op.Pos, e.forRangeEntry(i, it.Next(), op))
-		if exit {
-			return err
+		if exit, c := loopBody(op.Pos, e.forRangeValue(i, it.Next(), op)); exit {
+			return c
 		}
 	}
-	return nil
+
+	if g, ok := it.(*Generator); ok {
+		return errorCompletion(op.Pos, g.Err())
+	}
+	return normal()
 }
 
 // forMapIter will iterate over a MapIter
-func (e *executor) forMapIter(op *script.ForRange, mi *reflect.MapIter) error {
+func (e *executor) forMapIter(op *script.ForRange, mi *reflect.MapIter) Completion {
 	for mi.Next() {
-		exit, err := e.breakOrContinue(op.Pos, e.forRangeEntry(mi.Key().Interface(), mi.Value().Interface(), op))
-		if exit {
-			return err
+		if exit, c := loopBody(op.Pos, e.forRangeEntry(mi.Key().Interface(), mi.Value().Interface(), op)); exit {
+			return c
 		}
 	}
-	return nil
+	return normal()
 }
 
 // forSlice will iterate over a reflect.Array, reflect.Slice or reflect.String.
 // This will panic if Value is not one of those types.
-func (e *executor) forSlice(op *script.ForRange, ti reflect.Value) error {
+func (e *executor) forSlice(op *script.ForRange, ti reflect.Value) Completion {
 	l := ti.Len()
 	for i := 0; i < l; i++ {
-		exit, err := e.breakOrContinue(op.Pos, e.forRangeEntry(i, ti.Index(i).Interface(), op))
-		if exit {
-			return err
+		if exit, c := loopBody(op.Pos, e.forRangeEntry(i, ti.Index(i).Interface(), op)); exit {
+			return c
 		}
 	}
-	return nil
+	return normal()
+}
+
+// forChannel will receive values from a channel until it is closed
+func (e *executor) forChannel(op *script.ForRange, cv reflect.Value) Completion {
+	for i := 0; ; i++ {
+		v, ok := cv.Recv()
+		if !ok {
+			return normal()
+		}
+		if exit, c := loopBody(op.Pos, e.forRangeValue(i, v.Interface(), op)); exit {
+			return c
+		}
+	}
+}
+
+// seqYield returns the type of the yield function if t is an iterator function like go's iter.Seq,
+// func(yield func(V) bool), or iter.Seq2, func(yield func(K, V) bool)
+func seqYield(t reflect.Type) (reflect.Type, bool) {
+	if t.NumIn() != 1 || t.NumOut() != 0 {
+		return nil, false
+	}
+
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool ||
+		yield.NumIn() < 1 || yield.NumIn() > 2 || yield.IsVariadic() {
+		return nil, false
+	}
+
+	return yield, true
+}
+
+// forSeq will iterate over an iterator function like go's iter.Seq or iter.Seq2.
+//
+// The loop body is run from within the yield function passed to the iterator.
+// As in go, it is an error if the iterator calls yield again once the loop has exited.
+func (e *executor) forSeq(op *script.ForRange, seq reflect.Value, yieldType reflect.Type) (c Completion) {
+	i := 0
+	exited := false
+	yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+		if exited {
+			panic("range function continued iteration after loop exit")
+		}
+
+		var body Completion
+		if len(args) == 2 {
+			body = e.forRangeEntry(args[0].Interface(), args[1].Interface(), op)
+		} else {
+			body = e.forRangeValue(i, args[0].Interface(), op)
+		}
+		i++
+
+		exited, c = loopBody(op.Pos, body)
+		return []reflect.Value{reflect.ValueOf(!exited)}
+	})
+
+	// Any panics get resolved to errors
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			c = errorCompletion(op.Pos, errors.Errorf(op.Pos, "%v", err1))
+		}
+	}()
+
+	seq.Call([]reflect.Value{yield})
+	if exited {
+		return c
+	}
+	return normal()
+}
+
+// forRangeValue is used in for range for a sequence of values, e.g. a channel or iterator, rather than
+// an indexed collection.
+//
+// As in go, a single variable is set to the value rather than the index.
+func (e *executor) forRangeValue(i int, val interface{}, op *script.ForRange) Completion {
+	if op.Value == "" {
+		return e.forRangeEntry(val, nil, op)
+	}
+	return e.forRangeEntry(i, val, op)
 }
 
 // forRangeEntryImpl is used in for range either from forRangeEntryValue or an iterator
-func (e *executor) forRangeEntry(key, val interface{}, op *script.ForRange) error {
+func (e *executor) forRangeEntry(key, val interface{}, op *script.ForRange) Completion {
 	if op.Body == nil {
-		return nil
+		return normal()
 	}
 
 	if state.IsValidVariable(op.Key) {
@@ -248,7 +343,7 @@ func (e *executor) forRangeEntry(key, val interface{}, op *script.ForRange) erro
 		}
 	}
 
-	return errors.Error(op.Pos, e.Statement(op.Body))
+	return e.Statement(op.Body).WithPos(op.Pos)
 }
 
 // isIterable tests to see if the result is an iterator and returns a usable Iterator if that is the case.
@@ -270,7 +365,7 @@ func isIterable(r interface{}) (util.Iterator[interface{}], bool) {
 	// with an interface{}
 	if it, ok := r.(iterator); ok {
 		tv := reflect.ValueOf(r)
-		tm := tv.MethodByName("Next")
+		tm := methodByName(tv, "Next")
 		if tm.IsValid() {
 			tmt := tm.Type()
 			if tmt.NumIn() == 0 && tmt.NumOut() == 1 {
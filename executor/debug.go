@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"github.com/peter-mount/go-script/script"
+	"github.com/peter-mount/go-script/state"
+)
+
+// DebugHook is called before each statement is run when set with WithDebugHook, e.g. by a debugger
+// to implement breakpoints and stepping.
+//
+// It is called by the goroutine running the script, so the script is paused until it returns.
+// If it returns an error then the statement fails with that error, which can be caught by a try statement
+// unless it is an errors.ExitError.
+//
+// Functions started by go statements and generators run in their own goroutines, so the hook can be
+// called concurrently for different threads.
+type DebugHook func(d *DebugState) error
+
+// DebugState describes the statement about to be run
+type DebugState struct {
+	Thread  int             // Identifies the goroutine, 0 for the one running main()
+	Frames  []Frame         // The functions being executed, the one containing the statement first
+	Globals state.Variables // The global variables
+}
+
+// Frame is a function being executed.
+type Frame struct {
+	Function *script.FuncDec // The function
+	// The statement being run, which for the callers of the current function is the one making the call
+	Statement *script.Statement
+	Scope     state.Variables // The innermost variable scope within the function
+	// The values on the calculator stack. For the callers of the current function these are the
+	// values the expression making the call had calculated before the call was made.
+	Stack []interface{}
+}
+
+// Depth returns the number of functions being executed
+func (d *DebugState) Depth() int {
+	return len(d.Frames)
+}
+
+// Current returns the frame of the function containing the statement about to be run
+func (d *DebugState) Current() Frame {
+	return d.Frames[0]
+}
+
+// WithDebugHook sets a DebugHook to be called before each statement is run
+func WithDebugHook(hook DebugHook) Option {
+	return func(e *executor) {
+		e.debugHook = hook
+	}
+}
+
+// enterFrame records a function being called when debugging, returning a function to call once it has returned
+func (e *executor) enterFrame(f *script.FuncDec) func() {
+	if e.debugHook == nil {
+		return func() {}
+	}
+
+	// The caller is suspended at the call so record where it is
+	if n := len(e.frames); n > 0 {
+		caller := e.frames[n-1]
+		caller.Scope = e.state.Scope()
+		caller.Stack = e.calculator.Stack()
+	}
+
+	e.frames = append(e.frames, &Frame{Function: f})
+	return func() {
+		e.frames = e.frames[:len(e.frames)-1]
+	}
+}
+
+// debug calls the DebugHook before a statement is run
+func (e *executor) debug(statement *script.Statement) error {
+	// Statements run outside a function, e.g. in an interactive session, are not debugged
+	n := len(e.frames)
+	if n == 0 {
+		return nil
+	}
+
+	current := e.frames[n-1]
+	current.Statement = statement
+	current.Scope = e.state.Scope()
+	current.Stack = e.calculator.Stack()
+
+	d := &DebugState{
+		Thread:  e.thread,
+		Frames:  make([]Frame, n),
+		Globals: e.state.GlobalScope(),
+	}
+	for i, f := range e.frames {
+		d.Frames[n-1-i] = *f
+	}
+
+	return e.debugHook(d)
+}
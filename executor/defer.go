@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+)
+
+func init() {
+	Register("recover", _recover)
+	Register("setReturn", _setReturn)
+}
+
+// deferred holds the result of a function whilst its deferred calls are being made,
+// so recover() and setReturn() can change it.
+type deferred struct {
+	ret      interface{}
+	returned bool
+	err      error
+}
+
+// deferStatement records a call to be made when the current function returns.
+//
+// As in go, the function and its arguments are evaluated now, so defer println(i) within
+// a loop prints the value i had at each iteration.
+func (e *executor) deferStatement(op *script.Defer) Completion {
+	if len(e.defers) == 0 {
+		return errorCompletion(op.Pos, errors.Errorf(op.Pos, "defer outside of a function"))
+	}
+
+	c, err := e.bindCall(op.Call)
+	if err != nil {
+		return errorCompletion(op.Pos, err)
+	}
+	if c != nil {
+		i := len(e.defers) - 1
+		e.defers[i] = append(e.defers[i], c)
+	}
+	return normal()
+}
+
+// runDeferred makes the calls deferred by the current function in reverse order, passing
+// the function's result through them.
+//
+// As with a finally block, a deferred call which fails takes precedence over the result of the
+// function, so can replace an error. Any value returned by a deferred call is ignored, the result
+// can only be changed with recover() and setReturn().
+// The exception is when the script is exiting, which nothing can override.
+func (e *executor) runDeferred(ret interface{}, returned bool, err error) (interface{}, bool, error) {
+	if err != nil {
+		e.goroutines.fail(e)
+	}
+
+	d := &deferred{ret: ret, returned: returned, err: err}
+	e.deferring = append(e.deferring, d)
+	defer func() {
+		e.deferring = e.deferring[:len(e.deferring)-1]
+	}()
+
+	i := len(e.defers) - 1
+	for len(e.defers[i]) > 0 {
+		last := len(e.defers[i]) - 1
+		c := e.defers[i][last]
+		e.defers[i] = e.defers[i][:last]
+
+		_, _, err1 := e.runDeferredCall(c)
+		if err1 != nil && !errors.IsExit(d.err) {
+			d.ret, d.returned, d.err = nil, false, err1
+		}
+	}
+
+	return d.ret, d.returned, d.err
+}
+
+// runDeferredCall makes a deferred call
+func (e *executor) runDeferredCall(c *boundCall) (ret interface{}, returned bool, err error) {
+	// Any panics get resolved to errors
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			ret, returned, err = nil, false, errors.Errorf(c.cf.Pos, "%v", err1)
+		}
+	}()
+
+	return e.call(c)
+}
+
+// currentDeferred returns the result of the function whose deferred calls are being made,
+// nil if there are none.
+func currentDeferred(e Executor) *deferred {
+	if ex, ok := e.(*executor); ok && len(ex.deferring) > 0 {
+		return ex.deferring[len(ex.deferring)-1]
+	}
+	return nil
+}
+
+// _recover implements recover(), which when called from a deferred call returns the message of
+// the error the function is returning, as a catch block would, and clears it so the function
+// returns normally.
+// It returns null if there is no error, when the script is exiting, or when not called
+// from a deferred call.
+func _recover(e Executor, call *script.CallFunc) error {
+	a, err := Args(e, call)
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+	if len(a) != 0 {
+		return errors.Errorf(call.Pos, "recover()")
+	}
+
+	var r interface{}
+	if d := currentDeferred(e); d != nil && d.err != nil && !errors.IsExit(d.err) {
+		r = d.err.Error()
+		d.err = nil
+	}
+
+	e.Calculator().Push(r)
+	return nil
+}
+
+// _setReturn implements setReturn(value), which when called from a deferred call replaces
+// the value returned by the function.
+// If the function is returning an error then that still takes precedence unless recover()
+// is also called.
+func _setReturn(e Executor, call *script.CallFunc) error {
+	a, err := Args(e, call)
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+	if len(a) != 1 {
+		return fmt.Errorf("setReturn(value)")
+	}
+
+	d := currentDeferred(e)
+	if d == nil {
+		return errors.Errorf(call.Pos, "setReturn outside of a deferred call")
+	}
+
+	d.ret, d.returned = a[0], true
+	return nil
+}
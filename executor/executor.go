@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"fmt"
 	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/peter-mount/go-script/calculator"
 	"github.com/peter-mount/go-script/errors"
@@ -11,30 +12,62 @@ import (
 
 type Executor interface {
 	ExpressionExecutor
+	// Run calls the script's main() function.
+	// If the script calls exit() then the error is an *errors.ExitError holding the status code.
 	Run() error
+	// RunStatus calls the script's main() function like Run, returning the status the script exited with.
+	// This is the code passed to exit(), an integer returned by main(), otherwise 0.
+	RunStatus() (int, error)
+	// Call calls a function declared in the script with the supplied arguments, returning its result
+	Call(name string, args ...interface{}) (interface{}, error)
+	// Warnings returns any problems found in the script which do not prevent it from running
+	Warnings() []error
 	// ProcessParameters will call each parameter in a CallFunc returning the true values
 	ProcessParameters(*script.CallFunc) ([]interface{}, error)
 	// ArgsToValues will take a slice of arguments and convert to reflect.Value.
 	// This will handle if CallFunc.Variadic is set
 	ArgsToValues(cf *script.CallFunc, tf reflect.Type, args []interface{}) ([]reflect.Value, error)
 	CallReflectFuncImpl(*script.CallFunc, reflect.Value, []interface{}) (interface{}, error)
+	// Load adds the declarations in a script to those already made, e.g. in an interactive session
+	Load(s *script.Script) error
+	// Evaluate runs an entry from an interactive session, returning the value of a final expression
+	Evaluate(in *script.Input) (interface{}, bool, error)
+	// Functions returns the names of the declared functions
+	Functions() []string
 }
 
 type ExpressionExecutor interface {
 	Calculator() calculator.Calculator
 	GlobalScope() state.Variables
 	Expression(op *script.Expression) error
-	Statement(statements *script.Statement) error
-	Statements(statements *script.Statements) error
+	Statement(statements *script.Statement) Completion
+	Statements(statements *script.Statements) Completion
 }
 
 type executor struct {
-	script     *script.Script
-	state      state.State
-	calculator calculator.Calculator
+	script          *script.Script
+	state           state.State
+	calculator      calculator.Calculator
+	negativeIndices bool           // true to allow negative indices, e.g. a[-1]
+	defers          [][]*boundCall // calls deferred by each function being executed
+	bound           *boundCall     // the builtin being called by call, whose arguments have been evaluated
+	deferring       []*deferred    // the results of functions whose deferred calls are being made
+	goroutines      *goroutines    // goroutines started by this executor and its forks
+	program         *Program       // The Program which created this executor, nil if created by New
+	generator       *Generator     // The Generator this executor is running, nil if not a generator
+	args            []string       // Arguments passed to main() if it declares a parameter
+	debugHook       DebugHook      // Called before each statement when debugging
+	frames          []*Frame       // The functions being executed when debugging, the current one last
+	thread          int            // Identifies the goroutine to the DebugHook, 0 for the one running main()
+	goCall          bool           // true if running the function called by a go statement
+	failing         bool           // true once the function called by a go statement has failed
 }
 
-func New(s *script.Script) (Executor, error) {
+// New returns an Executor for a parsed script.
+//
+// The Executor can only run the script from one goroutine at a time.
+// Use Compile to run the same script concurrently.
+func New(s *script.Script, opts ...Option) (Executor, error) {
 	execState, err := state.New(s)
 	if err != nil {
 		return nil, err
@@ -44,6 +77,11 @@ func New(s *script.Script) (Executor, error) {
 		script:     s,
 		state:      execState,
 		calculator: calculator.New(),
+		goroutines: &goroutines{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
 	}
 
 	return e, nil
@@ -58,21 +96,63 @@ func NewExpressionExecutor() ExpressionExecutor {
 }
 
 func (e *executor) Run() error {
+	_, err := e.runMain()
+	return err
+}
+
+func (e *executor) RunStatus() (int, error) {
+	ret, err := e.runMain()
+	if exit, ok := errors.GetExit(err); ok {
+		return exit.Code(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	code, _ := calculator.GetIntRaw(ret)
+	return code, nil
+}
+
+// runMain calls main(), passing the arguments set by WithArgs if it declares a parameter
+func (e *executor) runMain() (interface{}, error) {
 	main, hasMain := e.state.GetFunction(lexer.Position{}, "main")
 	if !hasMain {
-		return errors.Errorf(e.script.Pos, "main() function not defined")
+		return nil, errors.Errorf(e.script.Pos, "main() function not defined")
+	}
+
+	var args []interface{}
+	switch len(main.Parameters) {
+	case 0:
+	case 1:
+		args = append(args, append([]string{}, e.args...))
+	default:
+		return nil, errors.Errorf(main.Pos, "main() can only declare a parameter for its arguments")
 	}
 
-	err := e.functionImpl(main, nil)
+	ret, _, err := e.functionImpl(main, args)
+	if err == nil {
+		// Report any goroutine which has failed
+		err = e.goroutines.Err()
+	}
+	return ret, errors.Error(e.script.Pos, err)
+}
 
-	// Pass err unless it's return, break or continue.
-	// break should happen lower down but this catches it, so it doesn't
-	// exit the function call
-	if err != nil && !(errors.IsReturn(err) || errors.IsBreak(err)) || errors.IsContinue(err) {
-		return errors.Error(e.script.Pos, err)
+func (e *executor) Call(name string, args ...interface{}) (interface{}, error) {
+	f, exists := e.state.GetFunction(lexer.Position{}, name)
+	if !exists {
+		return nil, fmt.Errorf("function %q not defined", name)
 	}
 
-	return nil
+	if f.Generator {
+		return e.newGenerator(f, args), nil
+	}
+
+	ret, _, err := e.functionImpl(f, args)
+	return ret, errors.Error(f.Pos, err)
+}
+
+func (e *executor) Warnings() []error {
+	return e.script.Warnings
 }
 
 func (e *executor) Calculator() calculator.Calculator {
@@ -9,6 +9,10 @@ import (
 
 func (e *executor) Expression(op *script.Expression) error {
 
+	if op.KeyValue != nil {
+		return errors.Error(op.Pos, e.keyValue(op.KeyValue))
+	}
+
 	if op.Right != nil {
 		v, exists, err := e.calculator.Calculate(func() error {
 			return e.assignment(op.Right)
@@ -27,28 +31,19 @@ func (e *executor) Expression(op *script.Expression) error {
 func (e *executor) assignment(op *script.Assignment) error {
 	if op.Op == "=" {
 
-		var primary *script.Primary
-		// This is messy TODO clean up this mess
-		if ternary := op.Left; ternary != nil {
-			if log := ternary.Left; log != nil {
-				if eq := log.Left; eq != nil {
-					if comp := eq.Left; comp != nil {
-						if add := comp.Left; add != nil {
-							if mul := add.Left; mul != nil {
-								if unary := mul.Left; unary != nil {
-									primary = unary.Right
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-
+		primary := op.Left.Primary()
 		if primary == nil || primary.Ident == nil || primary.Ident.Ident == "" {
 			return errors.Errorf(op.Pos, "Assignment without target")
 		}
 
+		if primary.IsOptionalChain() {
+			return errors.Errorf(op.Pos, "Cannot assign to optional chain")
+		}
+
+		if primary.Last().Ident.IsIndexed() {
+			return errors.Error(op.Pos, e.assignIndex(op, primary))
+		}
+
 		name := primary.Ident.Ident
 
 		if primary.Pointer == nil {
@@ -97,89 +92,186 @@ func (e *executor) assignment(op *script.Assignment) error {
 				_ = e.state.Set(name, v)
 			}
 		} else {
-			v, err := e.resolveIdent(primary)
-			if err != nil && !errors.IsNoFieldErr(err) {
-				return errors.Error(op.Pos, err)
-			}
+			return errors.Error(op.Pos, e.assignField(op, primary))
+		}
 
-			if nfe, ok := errors.GetNoFieldErr(err); ok {
-				v = nfe.Value()
-				name = nfe.Name()
-				err = nil
-			}
+		return nil
+	} else if op.IsSend() {
+		return errors.Error(op.Pos, e.send(op))
+	} else {
+		return errors.Error(op.Pos, e.ternary(op.Left))
+	}
+}
 
-			vV := reflect.ValueOf(v)
-			if vV.IsNil() {
-				return errors.Errorf(op.Pos, "Cannot set nil")
-			}
-			vT := vV.Type()
+// resolveFieldTarget resolves the container of a field assignment, e.g. for a.b[i].c = v it
+// returns a.b[i] and the final Primary c.
+func (e *executor) resolveFieldTarget(op *script.Primary) (interface{}, *script.Primary, error) {
+	v, exists := e.state.Get(op.Ident.Ident)
+	if !exists {
+		return nil, nil, errors.Errorf(op.Pos, "%q undefined", op.Ident.Ident)
+	}
 
-			// Process RHS to get value
-			err = e.assignment(op.Right)
-			if err != nil {
-				return errors.Error(op.Pos, err)
-			}
+	for p := op; ; {
+		var err error
+		v, _, err = e.resolveArray(p, v)
+		if err != nil {
+			return nil, nil, errors.Error(p.Pos, err)
+		}
 
-			setV, err := e.calculator.Peek()
-			if err != nil {
-				return errors.Error(op.Pos, err)
-			}
+		p = p.Pointer
+		if p.Ident == nil || p.Ident.Ident == "" {
+			return nil, nil, errors.Errorf(p.Pos, "Assignment without target")
+		}
 
-			switch vT.Kind() {
-			case reflect.Map:
-				vV.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(setV))
+		if p.Pointer == nil {
+			return v, p, nil
+		}
 
-			case reflect.Struct:
-				f := vV.FieldByName(name)
-				if f.IsValid() && f.CanSet() {
-					f.Set(reflect.ValueOf(setV))
-				} else {
-					return errors.Errorf(op.Pos, "Cannot set %q on %T", name, setV)
-				}
+		v, err = e.resolveReference(p, p.Ident.Ident, v)
+		if err != nil {
+			return nil, nil, errors.Error(p.Pos, err)
+		}
+	}
+}
 
-			default:
-				return errors.Errorf(op.Pos, "Cannot set %T", setV)
-			}
+// assignField implements assignment to a field of a record, struct or map, e.g. a.b = v
+func (e *executor) assignField(op *script.Assignment, primary *script.Primary) (err error) {
+	// Any panics get resolved to errors
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = errors.Errorf(op.Pos, "%v", err1)
 		}
+	}()
 
+	container, target, err := e.resolveFieldTarget(primary)
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+
+	name := target.Ident.Ident
+	if calculator.IsNil(container) {
+		return errors.Errorf(target.Pos, "Cannot set %q on nil", name)
+	}
+
+	// Process RHS to get value
+	v, err := e.assignmentValue(op, func() (interface{}, error) {
+		return e.resolveReference(target, name, container)
+	})
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+
+	if r, ok := container.(*Record); ok {
+		if !r.Set(name, v) {
+			return errors.Errorf(target.Pos, "%s has no field %q", r.TypeName(), name)
+		}
 		return nil
-	} else {
-		return errors.Error(op.Pos, e.ternary(op.Left))
 	}
+
+	tv := reflect.Indirect(reflect.ValueOf(container))
+	switch tv.Kind() {
+	case reflect.Map:
+		key, err := assignableValue(name, tv.Type().Key())
+		if err != nil {
+			return errors.Error(target.Pos, err)
+		}
+
+		val, err := assignableValue(v, tv.Type().Elem())
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		tv.SetMapIndex(key, val)
+
+	case reflect.Struct:
+		f := fieldByName(tv, name)
+		if !f.IsValid() || !f.CanSet() {
+			return errors.Errorf(target.Pos, "Cannot set %q on %T", name, container)
+		}
+
+		val, err := assignableValue(v, f.Type())
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		f.Set(val)
+
+	default:
+		return errors.Errorf(target.Pos, "Cannot set %q on %T", name, container)
+	}
+
+	return nil
 }
 
 func (e *executor) ternary(op *script.Ternary) (err error) {
 
-	err = e.level1(op.Left)
+	err = e.coalesce(op.Left)
 	if err == nil && op.True != nil && op.False != nil {
-		var v interface{}
-		v, err = e.calculator.Pop()
+		var b bool
+		b, err = e.popBool()
 		if err == nil {
-			var b bool
-			b, err = calculator.GetBool(v)
-			if err == nil {
-				if b {
-					err = e.level1(op.True)
-				} else {
-					err = e.level1(op.False)
-				}
+			// Only the selected expression is evaluated
+			if b {
+				err = e.ternary(op.True)
+			} else {
+				err = e.ternary(op.False)
 			}
 		}
 	}
 	return errors.Error(op.Pos, err)
 }
 
-func (e *executor) level1(op *script.Level1) error {
+// coalesce implements the null-coalescing operator.
+//
+// Like the logical operators this uses short-circuit evaluation, so the right hand side is only
+// evaluated if the left hand side is null.
+func (e *executor) coalesce(op *script.Coalesce) error {
 
-	err := e.level2(op.Left)
+	err := e.level1(op.Left)
 	for err == nil && op.Right != nil {
-		err = e.level2(op.Right.Left)
-		if err == nil {
-			err = e.calculator.Op2(op.Op)
+		var v interface{}
+		v, err = e.calculator.Peek()
+		if err == nil && v != nil {
+			break
 		}
+
+		_, err = e.calculator.Pop()
 		if err == nil {
+			err = e.level1(op.Right.Left)
+		}
+		op = op.Right
+	}
+
+	return errors.Error(op.Pos, err)
+}
+
+// popBool pops the top value from the stack as a bool
+func (e *executor) popBool() (bool, error) {
+	v, err := e.calculator.Pop()
+	if err != nil {
+		return false, err
+	}
+	return calculator.GetBool(v)
+}
+
+// level1 implements logical or.
+//
+// This uses short-circuit evaluation, so the right hand side is only evaluated
+// if the left hand side is false.
+func (e *executor) level1(op *script.Level1) error {
+
+	err := e.level2(op.Left)
+	if err == nil && op.Right != nil {
+		var b bool
+		b, err = e.popBool()
+		for err == nil && !b && op.Right != nil {
+			err = e.level2(op.Right.Left)
+			if err == nil {
+				b, err = e.popBool()
+			}
 			op = op.Right
 		}
+		if err == nil {
+			e.calculator.Push(b)
+		}
 	}
 
 	if err != nil {
@@ -188,16 +280,25 @@ func (e *executor) level1(op *script.Level1) error {
 	return nil
 }
 
+// level2 implements logical and.
+//
+// This uses short-circuit evaluation, so the right hand side is only evaluated
+// if the left hand side is true.
 func (e *executor) level2(op *script.Level2) error {
 
 	err := e.level3(op.Left)
-	for err == nil && op.Right != nil {
-		err = e.level3(op.Right.Left)
-		if err == nil {
-			err = e.calculator.Op2(op.Op)
+	if err == nil && op.Right != nil {
+		var b bool
+		b, err = e.popBool()
+		for err == nil && b && op.Right != nil {
+			err = e.level3(op.Right.Left)
+			if err == nil {
+				b, err = e.popBool()
+			}
+			op = op.Right
 		}
 		if err == nil {
-			op = op.Right
+			e.calculator.Push(b)
 		}
 	}
 
@@ -247,9 +348,9 @@ func (e *executor) level4(op *script.Level4) error {
 
 func (e *executor) level5(op *script.Level5) error {
 
-	err := e.unary(op.Left)
+	err := e.power(op.Left)
 	for err == nil && op.Right != nil {
-		err = e.unary(op.Right.Left)
+		err = e.power(op.Right.Left)
 		if err == nil {
 			err = e.calculator.Op2(op.Op)
 		}
@@ -264,22 +365,33 @@ func (e *executor) level5(op *script.Level5) error {
 	return nil
 }
 
-func (e *executor) unary(op *script.Unary) error {
-	if op.Left != nil {
-		err := e.primary(op.Left)
+// power implements a ** b. Unlike the other levels this is right associative
+// so it recurses rather than loops.
+func (e *executor) power(op *script.Power) error {
+	err := e.unary(op.Left)
+	if err == nil && op.Right != nil {
+		err = e.power(op.Right)
 		if err == nil {
-			err = e.calculator.Op1(op.Op)
-		}
-		if err != nil {
-			return errors.Error(op.Pos, err)
+			err = e.calculator.Op2(op.Op)
 		}
 	}
+	return errors.Error(op.Pos, err)
+}
 
-	if op.Right != nil {
-		return errors.Error(op.Pos, e.primary(op.Right))
+func (e *executor) unary(op *script.Unary) error {
+	if op.Left != nil {
+		err := e.unary(op.Left)
+		if err == nil {
+			if op.Op == "<-" {
+				err = e.receive()
+			} else {
+				err = e.calculator.Op1(op.Op)
+			}
+		}
+		return errors.Error(op.Pos, err)
 	}
 
-	return nil
+	return e.primary(op.Right)
 }
 
 func (e *executor) primary(op *script.Primary) error {
@@ -290,11 +402,26 @@ func (e *executor) primary(op *script.Primary) error {
 	case op.Integer != nil:
 		e.calculator.Push(*op.Integer)
 
+	case op.Imaginary != nil:
+		e.calculator.Push(op.Imaginary.Complex())
+
 	case op.String != nil:
 		e.calculator.Push(*op.String)
 
 	case op.CallFunc != nil:
-		return errors.Error(op.Pos, e.callFunc(op.CallFunc))
+		err := e.callFunc(op.CallFunc)
+		if err == nil && op.Pointer != nil {
+			// Reference against the result, e.g. Point(3, 4).Dist()
+			var v interface{}
+			v, err = e.calculator.Pop()
+			if err == nil {
+				v, err = e.getReferenceImpl(op.Pointer, v, op.IsOptional())
+			}
+			if err == nil {
+				e.calculator.Push(v)
+			}
+		}
+		return errors.Error(op.Pos, err)
 
 	case op.Null, op.Nil:
 		e.calculator.Push(nil)
@@ -310,9 +437,6 @@ func (e *executor) primary(op *script.Primary) error {
 
 	case op.SubExpression != nil:
 		return errors.Error(op.Pos, e.Expression(op.SubExpression))
-
-	case op.KeyValue != nil:
-		return errors.Error(op.KeyValue.Pos, e.keyValue(op.KeyValue))
 	}
 
 	return nil
@@ -345,11 +469,11 @@ func (e *executor) ident(op *script.Ident, primary *script.Primary) error {
 	switch {
 	case op.IsPreIncDec() && op.PreIncDec.Increment,
 		op.IsPostIncDec() && op.PostIncDec.Increment:
-		newValue, err = calculator.Add(value, 1)
+		newValue, err = e.incDec(value, "+")
 
 	case op.IsPreIncDec() && op.PreIncDec.Decrement,
 		op.IsPostIncDec() && op.PostIncDec.Decrement:
-		newValue, err = calculator.Subtract(value, 1)
+		newValue, err = e.incDec(value, "-")
 
 	default:
 		// Should never occur in normal use unless we reuse this function outside a Primary
@@ -370,6 +494,16 @@ func (e *executor) ident(op *script.Ident, primary *script.Primary) error {
 	return errors.Error(op.Pos, err)
 }
 
+// incDec applies op with 1 to value using the calculator, so ++ and -- behave as += 1 and -= 1
+func (e *executor) incDec(value interface{}, op string) (interface{}, error) {
+	calc := e.calculator
+	calc.Push(value).Push(1)
+	if err := calc.Op2(op); err != nil {
+		return nil, err
+	}
+	return calc.Pop()
+}
+
 func (e *executor) resolveIdent(op *script.Primary) (interface{}, error) {
 	ident := op.Ident.Ident
 	v, exists := e.state.Get(ident)
@@ -378,11 +512,11 @@ func (e *executor) resolveIdent(op *script.Primary) (interface{}, error) {
 	}
 
 	// Handle arrays
-	v, err := e.resolveArray(op, v)
+	v, ok, err := e.resolveArray(op, v)
 
-	if err == nil && op.Pointer != nil {
+	if err == nil && ok && op.Pointer != nil {
 		// Resolve references
-		v, err = e.getReferenceImpl(op.Pointer, v)
+		v, err = e.getReferenceImpl(op.Pointer, v, op.IsOptional())
 	}
 
 	if errors.IsNoFieldErr(err) {
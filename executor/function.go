@@ -9,47 +9,168 @@ import (
 )
 
 func (e *executor) callFunc(cf *script.CallFunc) error {
-	err := e.callFuncImpl(cf)
 
-	// Handle return values
-	if ret, ok := err.(*errors.ReturnError); ok {
-		e.calculator.Push(ret.Value())
+	// Lookup local function first so a script can declare a function with the same name as a builtin
+	f, exists := e.state.GetFunction(cf.Pos, cf.Name)
+	if !exists {
+		// Constructor of a declared type
+		if t, exists := e.state.GetType(cf.Name); exists {
+			return e.newRecord(cf, t)
+		}
+
+		// Lookup builtin functions
+		libFunc, exists := Lookup(cf.Name)
+		if !exists {
+			return fmt.Errorf("%s function %q not defined", cf.Pos, cf.Name)
+		}
+
+		err := libFunc(e, cf)
+
+		// Handle return values from functions still using errors.NewReturn
+		if ret, ok := err.(*errors.ReturnError); ok {
+			e.calculator.Push(ret.Value())
+			return nil
+		}
+
+		return err
+	}
+
+	args, err := e.ProcessParameters(cf)
+	if err != nil {
+		return err
+	}
+
+	if f.Generator {
+		e.calculator.Push(e.newGenerator(f, args))
 		return nil
 	}
 
-	return err
+	ret, returned, err := e.functionImpl(f, args)
+	if err != nil {
+		return errors.Error(f.Pos, err)
+	}
+
+	if returned {
+		e.calculator.Push(ret)
+	}
+	return nil
 }
 
-func (e *executor) callFuncImpl(cf *script.CallFunc) error {
+// boundCall is a call whose function and arguments have been evaluated so it can be made later,
+// e.g. by a go or defer statement
+type boundCall struct {
+	cf      *script.CallFunc
+	f       *script.FuncDec // A function declared within the script, or a method of a Record
+	method  reflect.Value   // A method of a go value
+	builtin Function        // A builtin function
+	args    []interface{}   // The arguments, starting with the receiver for a method of a Record
+}
 
-	// Lookup builtin functions
-	libFunc, exists := Lookup(cf.Name)
-	if exists {
-		return libFunc(e, cf)
+// bindCall evaluates the function and arguments of a call, e.g. a.b.f(x) evaluates a.b and x.
+//
+// It returns nil if the call follows "?." and the value it is called against is null,
+// so the call is not made.
+func (e *executor) bindCall(op *script.Primary) (*boundCall, error) {
+	recvOp, cf, optional := splitCall(op)
+
+	var recv interface{}
+	if recvOp != nil {
+		var err error
+		recv, err = e.calculator.MustCalculate(func() error { return e.primary(recvOp) })
+		if err != nil {
+			return nil, err
+		}
+		if recv == nil && optional {
+			return nil, nil
+		}
 	}
 
-	// Lookup local function
-	f, exists := e.state.GetFunction(cf.Pos, cf.Name)
-	if !exists {
-		return fmt.Errorf("%s function %q not defined", cf.Pos, cf.Name)
+	c := &boundCall{cf: cf}
+	switch r := recv.(type) {
+	case nil:
+		var exists bool
+		c.f, exists = e.state.GetFunction(cf.Pos, cf.Name)
+		if !exists {
+			c.builtin, exists = Lookup(cf.Name)
+		}
+		if !exists {
+			return nil, errors.Errorf(cf.Pos, "function %q not defined", cf.Name)
+		}
+
+	case *Record:
+		var exists bool
+		c.f, exists = r.Method(cf.Name)
+		if !exists {
+			return nil, errors.Errorf(cf.Pos, "%s has no method %q", r.TypeName(), cf.Name)
+		}
+
+	default:
+		c.method = methodByName(reflect.ValueOf(recv), cf.Name)
+		if !c.method.IsValid() {
+			return nil, errors.Errorf(cf.Pos, "%T has no function %q", recv, cf.Name)
+		}
 	}
 
 	args, err := e.ProcessParameters(cf)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if c.f != nil && recv != nil {
+		// The receiver is passed as the first argument
+		args = append([]interface{}{recv}, args...)
 	}
+	c.args = args
 
-	return errors.Error(f.Pos, e.functionImpl(f, args))
+	return c, nil
+}
+
+// call makes a boundCall.
+//
+// The bool returned is true if a function declared within the script returned a value.
+func (e *executor) call(c *boundCall) (interface{}, bool, error) {
+	switch {
+	case c.f != nil && c.f.Generator:
+		// As with any other call, a generator only returns its iterator
+		return e.newGenerator(c.f, c.args), true, nil
+
+	case c.f != nil:
+		return e.functionImpl(c.f, c.args)
+
+	case c.builtin != nil:
+		// The builtin gets the bound arguments from Args or ProcessParameters
+		old := e.bound
+		e.bound = c
+		defer func() { e.bound = old }()
+
+		v, _, err := e.calculator.Calculate(func() error {
+			err := c.builtin(e, c.cf)
+			if ret, ok := err.(*errors.ReturnError); ok {
+				e.calculator.Push(ret.Value())
+				return nil
+			}
+			return err
+		})
+		return v, false, err
+
+	default:
+		v, err := e.CallReflectFuncImpl(c.cf, c.method, c.args)
+		return v, false, err
+	}
 }
 
 func (e *executor) ProcessParameters(cf *script.CallFunc) ([]interface{}, error) {
+	// Arguments already evaluated by bindCall
+	if e.bound != nil && e.bound.cf == cf {
+		return e.bound.args, nil
+	}
 
 	// Process parameters
 	var args []interface{}
 	if cf.Parameters != nil {
 		for _, p := range cf.Parameters.Args {
 			v, ok, err := e.calculator.Calculate(func() error {
-				return e.assignment(p.Right)
+				return e.Expression(p)
 			})
 			if err != nil {
 				return nil, errors.Error(p.Pos, err)
@@ -65,22 +186,51 @@ func (e *executor) ProcessParameters(cf *script.CallFunc) ([]interface{}, error)
 }
 
 // functionImpl invokes a function declared within the script.
-// Used by callFuncImpl and executor.Run
-func (e *executor) functionImpl(f *script.FuncDec, args []interface{}) error {
+// Used by callFunc and executor.Run
+//
+// If the function is a method then the receiver is the first entry in args.
+//
+// The bool returned is true if the function returned a value, false if it completed without
+// a return statement.
+func (e *executor) functionImpl(f *script.FuncDec, args []interface{}) (ret interface{}, returned bool, err error) {
+	defer e.enterFrame(f)()
+
 	// Use NewRootScope so we cannot access variables outside the function
 	e.state.NewRootScope()
 
 	// Set the current function to this one preserving the caller
 	oldFunc := e.state.SetFunction(f)
 
-	// Restore state once we complete
+	// Calls deferred by this invocation
+	e.defers = append(e.defers, nil)
+
+	// Make any deferred calls then restore state once we complete
 	defer func() {
+		// Any panics get resolved to errors if there are deferred calls to make
+		if len(e.defers[len(e.defers)-1]) > 0 {
+			if err1 := recover(); err1 != nil {
+				ret, returned, err = nil, false, errors.Errorf(f.Pos, "%v", err1)
+			}
+		}
+
+		ret, returned, err = e.runDeferred(ret, returned, err)
+
+		e.defers = e.defers[:len(e.defers)-1]
 		e.state.EndScope()
 		e.state.SetFunction(oldFunc)
 	}()
 
+	if f.Receiver != nil {
+		if len(args) == 0 {
+			return nil, false, fmt.Errorf("%s method %q called without a receiver", f.Pos, f.Name)
+		}
+		e.state.Declare(f.Receiver.Name)
+		e.state.Set(f.Receiver.Name, args[0])
+		args = args[1:]
+	}
+
 	if len(args) != len(f.Parameters) {
-		return fmt.Errorf("%s parameter mismatch, expected %d got %d", f.Pos, len(f.Parameters), len(args))
+		return nil, false, fmt.Errorf("%s parameter mismatch, expected %d got %d", f.Pos, len(f.Parameters), len(args))
 	}
 
 	for i, p := range f.Parameters {
@@ -88,7 +238,18 @@ func (e *executor) functionImpl(f *script.FuncDec, args []interface{}) error {
 		e.state.Set(p, args[i])
 	}
 
-	return errors.Error(f.Pos, e.Statements(f.FunBody))
+	c := e.Statements(f.FunBody)
+	switch c.Type {
+	case Return:
+		return c.Value, true, nil
+
+	case Error:
+		return nil, false, errors.Error(f.Pos, c.Err)
+
+	default:
+		// break & continue cannot leave a loop so treat the same as falling off the end of the function
+		return nil, false, nil
+	}
 }
 
 // callReflectFunc invokes a function within go from a script
@@ -166,35 +327,24 @@ func (e *executor) ArgsToValues(cf *script.CallFunc, tf reflect.Type, args []int
 		}
 	}
 
-	// argC = number of arguments function accepts.
-	// However, if it's variadic when we take the last one off as we handle that
-	// last one specially due to it being a slice.
-	argC := tf.NumIn()
-	if tf.IsVariadic() {
-		argC = argC - 1
-	}
+	plan := getTypeInfo(tf).funcPlan()
 
 	// Cast every argument excluding the variadic one (if present)
 	for argN, argV := range args {
-		if argN < argC {
-			ret, err = e.castArg(ret, argV, tf.In(argN))
+		if argN < plan.numIn {
+			ret, err = castArg(ret, argV, plan.in[argN])
 			if err != nil {
 				return nil, errors.Error(cf.Parameters.Args[argN].Pos, err)
 			}
 		}
 	}
 
-	if tf.IsVariadic() {
-		// Type of variadic parameter, always the last one
-		variadicIndex := tf.NumIn() - 1
-		// last element is actually a slice so call Elem to get the actual type
-		variadicType := tf.In(variadicIndex).Elem()
-
+	if plan.variadic {
 		// For remaining args convert to the same type as the Variadic
 		for i := len(ret); i < len(args); i++ {
-			ret, err = e.castArg(ret, args[i], variadicType)
+			ret, err = castArg(ret, args[i], plan.varIn)
 			if err != nil {
-				return nil, errors.Error(cf.Parameters.Args[variadicIndex].Pos, err)
+				return nil, errors.Error(cf.Parameters.Args[plan.numIn].Pos, err)
 			}
 		}
 	}
@@ -202,9 +352,8 @@ func (e *executor) ArgsToValues(cf *script.CallFunc, tf reflect.Type, args []int
 	return
 }
 
-func (e *executor) castArg(ret []reflect.Value, arg interface{}, as reflect.Type) ([]reflect.Value, error) {
-	argV := reflect.ValueOf(arg)
-	val, err := calculator.Cast(argV, as)
+func castArg(ret []reflect.Value, arg interface{}, cast calculator.CastFunc) ([]reflect.Value, error) {
+	val, err := cast(reflect.ValueOf(arg))
 	if err != nil {
 		return nil, err
 	}
@@ -213,11 +362,10 @@ func (e *executor) castArg(ret []reflect.Value, arg interface{}, as reflect.Type
 
 func (e *executor) valuesToRet(tf reflect.Type, retVal []reflect.Value) (ret []interface{}, err error) {
 
-	for i := 0; i < tf.NumOut(); i++ {
-		tOut := tf.Out(i)
+	for i, isError := range getTypeInfo(tf).funcPlan().out {
 		rv := retVal[i]
 
-		if tOut.Implements(errorInterface) {
+		if isError {
 			// if err not nil fail the function
 			// otherwise drop the value from the results
 			if !rv.IsNil() {
@@ -232,22 +380,22 @@ func (e *executor) valuesToRet(tf reflect.Type, retVal []reflect.Value) (ret []i
 	return
 }
 
-func (e *executor) returnStatement(ret *script.Return) error {
+func (e *executor) returnStatement(ret *script.Return) Completion {
 	if ret.Result == nil {
-		return errors.NewReturn(nil)
+		return returnCompletion(nil)
 	}
 
 	v, ok, err := e.calculator.Calculate(func() error {
 		return e.Expression(ret.Result)
 	})
 	if err != nil {
-		return errors.Error(ret.Pos, err)
+		return errorCompletion(ret.Pos, err)
 	}
 	if !ok {
-		return errors.Errorf(ret.Pos, "No result from argument")
+		return errorCompletion(ret.Pos, errors.Errorf(ret.Pos, "No result from argument"))
 	}
 
-	return errors.NewReturn(v)
+	return returnCompletion(v)
 }
 
 var (
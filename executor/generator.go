@@ -0,0 +1,181 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-kernel/v2/util"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+)
+
+// Generator is the iterator returned by calling a function which contains yield, e.g.
+//
+//	count(n) { for i := 0; i < n; i++ { yield i } }
+//	main() { for _, v = range count(3) { println(v) } }
+//
+// The function does not start until the first value is requested, and then runs in its own
+// goroutine until it yields a value, pausing there until the next value is requested.
+// It ends when the function returns, any value returned being ignored.
+//
+// A Generator must only be used by one goroutine at a time.
+// If it is not consumed to the end then Stop should be called, which for range does automatically.
+type Generator struct {
+	exec    *executor        // Executor the function runs on
+	f       *script.FuncDec  // The generator function
+	args    []interface{}    // Arguments to the function
+	resume  chan bool        // Sent true to produce the next value, closed to stop
+	values  chan interface{} // Values yielded, closed once the function completes
+	started bool             // true once the function has been started
+	done    bool             // true once the function has completed or been stopped
+	pending bool             // true if next holds a value not yet returned by Next
+	next    interface{}      // The next value to return from Next
+	err     error            // Error returned by the function
+}
+
+// newGenerator returns a Generator which will call a generator function with the supplied arguments
+func (e *executor) newGenerator(f *script.FuncDec, args []interface{}) *Generator {
+	g := &Generator{
+		exec:   e.fork(),
+		f:      f,
+		args:   args,
+		resume: make(chan bool),
+		values: make(chan interface{}),
+	}
+	g.exec.generator = g
+	return g
+}
+
+// run invokes the generator function once the first value is requested
+func (g *Generator) run() {
+	defer close(g.values)
+
+	// Any panics get resolved to errors
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			g.err = errors.Errorf(g.f.Pos, "%v", err1)
+		}
+	}()
+
+	if !<-g.resume {
+		return
+	}
+
+	_, _, g.err = g.exec.functionImpl(g.f, g.args)
+}
+
+// HasNext returns true if the generator has another value, running the function until it
+// yields that value or completes.
+func (g *Generator) HasNext() bool {
+	if g.pending {
+		return true
+	}
+	if g.done {
+		return false
+	}
+
+	if !g.started {
+		g.started = true
+		go g.run()
+	}
+
+	g.resume <- true
+	v, ok := <-g.values
+	if !ok {
+		g.done = true
+		return false
+	}
+
+	g.next, g.pending = v, true
+	return true
+}
+
+// Next returns the next value from the generator.
+// This panics if there are no more values, so HasNext should be used first.
+func (g *Generator) Next() interface{} {
+	if !g.HasNext() {
+		panic(fmt.Errorf("iterator out of bounds"))
+	}
+	g.pending = false
+	return g.next
+}
+
+// Stop terminates the generator before it has completed.
+//
+// Any yield the function is paused at returns from the function, so its deferred calls and
+// finally blocks run before Stop returns. Stop does nothing if the generator has already completed.
+func (g *Generator) Stop() {
+	if g.done {
+		return
+	}
+	g.done, g.pending = true, false
+
+	if g.started {
+		close(g.resume)
+		// Discard anything yielded whilst the function returns
+		for range g.values {
+		}
+	}
+}
+
+// Err returns the error returned by the generator function, nil if it has not failed
+func (g *Generator) Err() error {
+	return g.err
+}
+
+// ForEach calls f for each remaining value
+func (g *Generator) ForEach(f func(interface{})) {
+	for g.HasNext() {
+		f(g.Next())
+	}
+}
+
+// ForEachAsync is the same as ForEach as a generator's values are produced in sequence
+func (g *Generator) ForEachAsync(f func(interface{})) {
+	g.ForEach(f)
+}
+
+// ForEachFailFast calls f for each remaining value, stopping the generator if f returns an error
+func (g *Generator) ForEachFailFast(f func(interface{}) error) error {
+	for g.HasNext() {
+		if err := f(g.Next()); err != nil {
+			g.Stop()
+			return err
+		}
+	}
+	return nil
+}
+
+// Iterator returns the Generator as it is already an Iterator
+func (g *Generator) Iterator() util.Iterator[interface{}] {
+	return g
+}
+
+// ReverseIterator returns an Iterator of the remaining values in reverse order.
+// As this has to consume the generator, it should only be used with generators which complete.
+func (g *Generator) ReverseIterator() util.Iterator[interface{}] {
+	var values []interface{}
+	g.ForEach(func(v interface{}) {
+		values = append([]interface{}{v}, values...)
+	})
+	return util.NewIterator[interface{}](values...)
+}
+
+// yieldStatement passes a value to the consumer of the generator the function is running within,
+// pausing until the next value is requested.
+//
+// If the generator is stopped then this returns from the function.
+func (e *executor) yieldStatement(op *script.Yield) Completion {
+	if e.generator == nil {
+		return errorCompletion(op.Pos, errors.Errorf(op.Pos, "yield outside of a generator"))
+	}
+
+	v, err := e.calculator.MustCalculate(func() error { return e.Expression(op.Result) })
+	if err != nil {
+		return errorCompletion(op.Pos, err)
+	}
+
+	e.generator.values <- v
+	if !<-e.generator.resume {
+		return returnCompletion(nil)
+	}
+	return normal()
+}
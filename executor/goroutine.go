@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+	"sync"
+)
+
+// goroutines records the result of the goroutines started by go statements.
+// It is shared between an executor and all of its forks.
+type goroutines struct {
+	mutex   sync.Mutex
+	err     error          // The first error returned by a goroutine
+	threads int            // The number of goroutines given a thread id for debugging
+	failing sync.WaitGroup // Goroutines which have failed but are still making their deferred calls
+}
+
+// start makes call c using fork in a new goroutine, recording the first error or panic from any goroutine
+func (g *goroutines) start(pos lexer.Position, fork *executor, c *boundCall) {
+	fork.goCall = true
+	go func() {
+		var err error
+		defer func() {
+			if err1 := recover(); err1 != nil {
+				err = errors.Errorf(pos, "%v", err1)
+			}
+			if err != nil {
+				g.setErr(pos, err)
+			}
+			if fork.failing {
+				g.failing.Done()
+			}
+		}()
+
+		_, _, err = fork.call(c)
+	}()
+}
+
+// fail is called when the function called by a go statement has failed, before it makes its
+// deferred calls. Those calls can let main() return, e.g. defer wg.Done(), so main() has to wait
+// for them to complete before it can see the error.
+func (g *goroutines) fail(e *executor) {
+	if e.goCall && !e.failing && len(e.defers) == 1 {
+		e.failing = true
+		g.failing.Add(1)
+	}
+}
+
+func (g *goroutines) setErr(pos lexer.Position, err error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.err == nil {
+		g.err = errors.Error(pos, err)
+	}
+}
+
+// newThread returns the id of a new goroutine for a DebugHook
+func (g *goroutines) newThread() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.threads++
+	return g.threads
+}
+
+// Err returns the first error returned by a goroutine, nil if none have failed.
+//
+// It waits for any goroutine which has failed to make its deferred calls.
+func (g *goroutines) Err() error {
+	g.failing.Wait()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.err
+}
+
+// fork returns an executor to run a goroutine.
+//
+// It shares the script, its declarations and global variables with this executor
+// but has its own calculator and scope chain.
+func (e *executor) fork() *executor {
+	return &executor{
+		script:          e.script,
+		state:           e.state.Fork(),
+		calculator:      calculator.New().SetMode(e.calculator.Mode()),
+		negativeIndices: e.negativeIndices,
+		goroutines:      e.goroutines,
+		debugHook:       e.debugHook,
+		thread:          e.goroutines.newThread(),
+	}
+}
+
+// goStatement implements go f(args), calling a function in a new goroutine.
+//
+// The function and its arguments are evaluated in the current goroutine, so go worker(i) receives
+// the value of i at the time of the go statement.
+func (e *executor) goStatement(op *script.Go) Completion {
+	c, err := e.bindCall(op.Call)
+	if err != nil {
+		return errorCompletion(op.Pos, err)
+	}
+	if c == nil {
+		return normal()
+	}
+	if c.builtin != nil {
+		return errorCompletion(op.Pos, errors.Errorf(c.cf.Pos, "go cannot call builtin %q", c.cf.Name))
+	}
+
+	e.goroutines.start(op.Pos, e.fork(), c)
+
+	return normal()
+}
+
+// splitCall splits the target of a go or defer statement into the function call and the value it is
+// called against, e.g. a.b.f(x) into a.b and f(x).
+//
+// The returned Primary is nil if the call is to a function declared within the script.
+// optional is true if the call follows "?.", so is not made if the value is null.
+func splitCall(op *script.Primary) (recv *script.Primary, cf *script.CallFunc, optional bool) {
+	if op.Pointer == nil {
+		return nil, op.CallFunc, false
+	}
+
+	// Copy this part of the chain, so it ends before the call
+	r := *op
+	r.Pointer, cf, optional = splitCall(op.Pointer)
+	if r.Pointer == nil {
+		optional = op.IsOptional()
+		r.PointOp = ""
+	}
+	return &r, cf, optional
+}
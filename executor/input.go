@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+)
+
+// Load adds the declarations in a script to those already made by the Executor,
+// e.g. from a file loaded into an interactive session.
+//
+// Functions, methods and imports replace any already declared with the same name.
+// Executors created by a Program cannot load declarations as they share them with each other.
+func (e *executor) Load(s *script.Script) error {
+	if e.program != nil {
+		return fmt.Errorf("cannot load declarations into an executor created by a Program")
+	}
+	return errors.Error(s.Pos, e.state.Load(s))
+}
+
+// Evaluate runs an entry from an interactive session, as parsed by parser.Parser.ParseInput.
+//
+// Any declarations are loaded first, then the statements are run in the global scope so
+// variables they declare are visible to later entries.
+// If the final statement is an expression other than an assignment, or is a return statement,
+// then its value is returned with true.
+func (e *executor) Evaluate(in *script.Input) (ret interface{}, ok bool, err error) {
+	if err := e.Load(in.Script); err != nil {
+		return nil, false, err
+	}
+
+	// The statements run as the body of a function in the entry's file, so imports in that file are visible
+	oldFunc := e.state.SetFunction(&script.FuncDec{Pos: in.Pos})
+	defer e.state.SetFunction(oldFunc)
+
+	// Any panics get resolved to errors, so the session can continue
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			ret, ok, err = nil, false, errors.Errorf(in.Pos, "%v", err1)
+		}
+	}()
+
+	for _, s := range in.Statements {
+		if s.Expression != nil && s.Expression.Right != nil && s.Expression.Right.Op == "" {
+			ret, ok, err = e.calculator.Calculate(func() error {
+				return errors.Error(s.Pos, e.Expression(s.Expression))
+			})
+			if err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		ret, ok = nil, false
+		c := e.Statement(s)
+		switch c.Type {
+		case Return:
+			return c.Value, true, nil
+
+		case Error:
+			return nil, false, c.Err
+		}
+	}
+
+	return ret, ok, nil
+}
+
+func (e *executor) Functions() []string {
+	return e.state.GetFunctions()
+}
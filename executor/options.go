@@ -0,0 +1,32 @@
+package executor
+
+import "github.com/peter-mount/go-script/calculator"
+
+// Option configures an Executor when it is created with New
+type Option func(*executor)
+
+// WithNegativeIndices allows negative indices when indexing or slicing strings, slices and arrays.
+// A negative index counts back from the end, so a[-1] is the last element and a[-2:] the last two.
+//
+// Without this option a negative index is out of bounds, as in go.
+func WithNegativeIndices() Option {
+	return func(e *executor) {
+		e.negativeIndices = true
+	}
+}
+
+// WithCalculatorMode enables optional behaviour of the calculator used to evaluate expressions,
+// e.g. WithCalculatorMode(calculator.CheckOverflow) to fail on integer overflow instead of wrapping around.
+func WithCalculatorMode(mode calculator.Mode) Option {
+	return func(e *executor) {
+		e.calculator.SetMode(e.calculator.Mode() | mode)
+	}
+}
+
+// WithArgs sets the arguments passed to main() when it declares a parameter, e.g. main(args).
+// They are passed as a []string, which is empty if this option is not used.
+func WithArgs(args ...string) Option {
+	return func(e *executor) {
+		e.args = args
+	}
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/script"
+	"github.com/peter-mount/go-script/state"
+	"sync"
+)
+
+// Program is a compiled script which can be executed any number of times, including
+// concurrently from multiple goroutines.
+//
+// Unlike New, which sets up the script's functions, types, enums and imports for a single Executor,
+// a Program sets them up once and shares them with every Executor it creates.
+// Each Executor has its own calculator and variables, so it can run independently of the others.
+type Program struct {
+	script *script.Script
+	state  state.State // Declarations and globals shared by all executors
+	opts   []Option
+}
+
+// Compile sets up a parsed script so it can be executed by multiple Executors.
+// The options are applied to every Executor created by the Program.
+func Compile(s *script.Script, opts ...Option) (*Program, error) {
+	progState, err := state.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		script: s,
+		state:  progState,
+		opts:   opts,
+	}, nil
+}
+
+// Globals returns the global variables shared by every Executor created by the Program.
+//
+// These should be set before any Executor is run. An Executor can read them but setting one
+// only changes that Executor's copy, so they cannot be used to pass values between executors.
+func (p *Program) Globals() state.Variables {
+	return p.state.GlobalScope()
+}
+
+// Warnings returns any problems found in the script which do not prevent it from running
+func (p *Program) Warnings() []error {
+	return p.script.Warnings
+}
+
+// New returns a new Executor for the Program.
+//
+// An Executor must only be used by one goroutine at a time.
+func (p *Program) New() Executor {
+	e := &executor{
+		script:     p.script,
+		state:      p.state.Fork(),
+		calculator: calculator.New(),
+		program:    p,
+	}
+
+	for _, opt := range p.opts {
+		opt(e)
+	}
+
+	e.reset()
+	return e
+}
+
+// reset clears any state left by a previous run of an Executor created by a Program,
+// giving it a new global scope on top of the Program's globals.
+func (e *executor) reset() {
+	e.state.SetScope(state.NewSharedVariables(e.program.Globals()))
+	e.state.SetFunction(nil)
+	e.calculator.Reset()
+	e.defers = nil
+	e.deferring = nil
+	e.frames = nil
+	e.goroutines = &goroutines{}
+}
+
+// Pool holds Executors for a Program so they can be reused, e.g. one per request in a server.
+//
+//	exec := pool.Get()
+//	defer pool.Put(exec)
+//	exec.GlobalScope().Declare("request")
+//	exec.GlobalScope().Set("request", r)
+//	err := exec.Run()
+type Pool struct {
+	program *Program
+	pool    sync.Pool
+}
+
+// NewPool returns a Pool of Executors for a Program
+func NewPool(p *Program) *Pool {
+	return &Pool{
+		program: p,
+		pool: sync.Pool{
+			New: func() any { return p.New() },
+		},
+	}
+}
+
+// Program returns the Program this Pool holds Executors for
+func (p *Pool) Program() *Program {
+	return p.program
+}
+
+// Get returns an Executor from the Pool, creating one if the Pool is empty
+func (p *Pool) Get() Executor {
+	return p.pool.Get().(Executor)
+}
+
+// Put returns an Executor to the Pool once it is no longer required.
+// Any variables it has set are discarded so the next user starts with just the Program's globals.
+//
+// Executors not created by the Pool's Program are ignored.
+func (p *Pool) Put(exec Executor) {
+	if e, ok := exec.(*executor); ok && e.program == p.program {
+		e.reset()
+		p.pool.Put(e)
+	}
+}
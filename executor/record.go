@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+	"sort"
+)
+
+// Record is an instance of a type declared within a script, e.g. type Point struct { X, Y }
+//
+// Like a map, a Record is a reference so changes made to it, e.g. within a method,
+// are seen by everything holding it.
+//
+// As it implements calculator.Map it can be passed to go functions which expect a map or struct.
+type Record struct {
+	recordType *script.TypeDec
+	methods    map[string]*script.FuncDec // The methods declared against the type
+	values     map[string]interface{}
+}
+
+// Type returns the type this is an instance of
+func (r *Record) Type() *script.TypeDec {
+	return r.recordType
+}
+
+// TypeName returns the name of the type this is an instance of
+func (r *Record) TypeName() string {
+	return r.recordType.Name
+}
+
+// Get returns the value of a field, false if the type does not have that field
+func (r *Record) Get(name string) (interface{}, bool) {
+	if !r.recordType.HasField(name) {
+		return nil, false
+	}
+	return r.values[name], true
+}
+
+// Set the value of a field, false if the type does not have that field
+func (r *Record) Set(name string, v interface{}) bool {
+	if !r.recordType.HasField(name) {
+		return false
+	}
+	r.values[name] = v
+	return true
+}
+
+// Method returns a method declared against the record's type
+func (r *Record) Method(name string) (*script.FuncDec, bool) {
+	f, exists := r.methods[name]
+	return f, exists
+}
+
+// MethodNames returns the sorted names of the methods declared against the record's type
+func (r *Record) MethodNames() []string {
+	var names []string
+	for n := range r.methods {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Map returns a copy of the record's fields
+func (r *Record) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.recordType.Fields))
+	for _, f := range r.recordType.Fields {
+		m[f] = r.values[f]
+	}
+	return m
+}
+
+// Equal returns true if b is of the same type and all fields are equal
+func (r *Record) Equal(b *Record) bool {
+	if r == b {
+		return true
+	}
+	if b == nil || r.recordType != b.recordType {
+		return false
+	}
+
+	for _, f := range r.recordType.Fields {
+		if eq, err := calculator.Equals(r.values[f], b.values[f]); err != nil || !eq {
+			return false
+		}
+	}
+	return true
+}
+
+// Format formats the record like go's %+v verb, prefixed with the type name, e.g. Point{X:1 Y:2}
+func (r *Record) Format(s fmt.State, _ rune) {
+	_, _ = fmt.Fprintf(s, "%s{", r.TypeName())
+	for i, f := range r.recordType.Fields {
+		if i > 0 {
+			_, _ = fmt.Fprint(s, " ")
+		}
+		_, _ = fmt.Fprintf(s, "%s:%v", f, r.values[f])
+	}
+	_, _ = fmt.Fprint(s, "}")
+}
+
+// MarshalJSON encodes the record as a JSON object with the fields in the order they are declared
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r.recordType.Fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(f)
+		v, err := json.Marshal(r.values[f])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// newRecord implements the constructor of a record type, e.g. Point(1, 2) which sets
+// the fields in the order they are declared, or Point("Y": 2) which sets the named fields.
+//
+// Any fields not set are null.
+func (e *executor) newRecord(cf *script.CallFunc, t *script.TypeDec) error {
+	args, err := e.ProcessParameters(cf)
+	if err != nil {
+		return err
+	}
+
+	r := &Record{
+		recordType: t,
+		methods:    e.state.GetMethods(t.Name),
+		values:     make(map[string]interface{}, len(t.Fields)),
+	}
+
+	named := 0
+	for _, arg := range args {
+		if kv, ok := calculator.GetKeyValue(arg); ok {
+			if !r.Set(kv.Key(), kv.Value()) {
+				return errors.Errorf(cf.Pos, "unknown field %q in %s", kv.Key(), t.Name)
+			}
+			named++
+		}
+	}
+
+	switch {
+	case named == len(args):
+	case named > 0:
+		return errors.Errorf(cf.Pos, "mixture of field:value and value initializers in %s", t.Name)
+	case len(args) < len(t.Fields):
+		return errors.Errorf(cf.Pos, "too few values in %s", t.Name)
+	case len(args) > len(t.Fields):
+		return errors.Errorf(cf.Pos, "too many values in %s", t.Name)
+	default:
+		for i, f := range t.Fields {
+			r.values[f] = args[i]
+		}
+	}
+
+	e.calculator.Push(r)
+	return nil
+}
+
+// callMethod invokes a method declared against a record's type
+func (e *executor) callMethod(cf *script.CallFunc, r *Record) (interface{}, error) {
+	f, exists := r.Method(cf.Name)
+	if !exists {
+		return nil, errors.Errorf(cf.Pos, "%s has no method %q", r.TypeName(), cf.Name)
+	}
+
+	args, err := e.ProcessParameters(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	// The receiver is passed as the first argument
+	args = append([]interface{}{r}, args...)
+
+	if f.Generator {
+		return e.newGenerator(f, args), nil
+	}
+
+	ret, _, err := e.functionImpl(f, args)
+	if err != nil {
+		return nil, errors.Error(cf.Pos, err)
+	}
+	return ret, nil
+}
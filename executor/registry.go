@@ -6,6 +6,7 @@ import (
 	"github.com/peter-mount/go-script/errors"
 	"github.com/peter-mount/go-script/script"
 	"reflect"
+	"sort"
 	"sync"
 )
 
@@ -72,6 +73,19 @@ func Lookup(name string) (Function, bool) {
 	return nil, false
 }
 
+// Names returns the names of all registered Functions in sorted order
+func Names() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var names []string
+	for n := range library {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // RegisterFloat1 registers a function that accepts a float64 as its argument and returns a float64.
 // This is common for mathematical functions
 func RegisterFloat1(name string, f func(float64) float64) {
@@ -102,7 +116,8 @@ func float1(f func(float64) float64, e Executor, call *script.CallFunc) error {
 		return err
 	}
 
-	return errors.NewReturn(f(af))
+	e.Calculator().Push(f(af))
+	return nil
 }
 
 func float2(f func(float64, float64) float64, e Executor, call *script.CallFunc) error {
@@ -129,7 +144,8 @@ func float2(f func(float64, float64) float64, e Executor, call *script.CallFunc)
 		return err
 	}
 
-	return errors.NewReturn(f(af, bf))
+	e.Calculator().Push(f(af, bf))
+	return nil
 }
 
 func FuncDelegate(f any) Function {
@@ -150,10 +166,10 @@ func FuncDelegate(f any) Function {
 		numIn := fT.NumIn()
 
 		if fT.IsVariadic() && argC < (numIn-1) {
-			return errors.Errorf(call.Pos, "%n requires at least %d parameters", call.Name, numIn)
+			return errors.Errorf(call.Pos, "%s requires at least %d parameters", call.Name, numIn)
 		}
 		if !fT.IsVariadic() && argC != fT.NumIn() {
-			return errors.Errorf(call.Pos, "%n requires %d parameters", call.Name, numIn)
+			return errors.Errorf(call.Pos, "%s requires %d parameters", call.Name, numIn)
 		}
 
 		// Process arguments
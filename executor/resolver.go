@@ -19,7 +19,7 @@ import (
 //
 // v the value this Primary is referencing.
 func (e *executor) getReference(op *script.Primary, v interface{}) (err error) {
-	ref, err := e.getReferenceImpl(op, v)
+	ref, err := e.getReferenceImpl(op, v, false)
 
 	if err != nil {
 		return errors.Error(op.Pos, err)
@@ -29,16 +29,29 @@ func (e *executor) getReference(op *script.Primary, v interface{}) (err error) {
 	return nil
 }
 
-// getReferenceImpl is like getReference but is used to locate a field/variable to set
-func (e *executor) getReferenceImpl(op *script.Primary, v interface{}) (ref interface{}, err error) {
+// getReferenceImpl is like getReference but is used to locate a field/variable to set.
+//
+// If optional is true, i.e. op follows the "?." operator, then null is returned instead of an error
+// if v is null or the field is not present. When v is null the rest of the chain is not evaluated.
+func (e *executor) getReferenceImpl(op *script.Primary, v interface{}, optional bool) (ref interface{}, err error) {
+	if optional && v == nil {
+		return nil, nil
+	}
+
+	// ok is false if an optional index short-circuited the rest of the chain
+	ok := true
+
 	// These are not valid at this point.
 	switch {
 
 	case op.Ident != nil && op.Ident.Ident != "":
 		ref, err = e.resolveReference(op, op.Ident.Ident, v)
+		if optional && errors.IsNoFieldErr(err) {
+			ref, err = nil, nil
+		}
 		if err == nil {
 			// Handle arrays
-			ref, err = e.resolveArray(op, ref)
+			ref, ok, err = e.resolveArray(op, ref)
 		}
 
 	// method reference against v not declared functions
@@ -59,8 +72,8 @@ func (e *executor) getReferenceImpl(op *script.Primary, v interface{}) (ref inte
 	}
 
 	// recurse as we have a pointer to the next field
-	if op.Pointer != nil {
-		return e.getReferenceImpl(op.Pointer, ref)
+	if ok && op.Pointer != nil {
+		return e.getReferenceImpl(op.Pointer, ref, op.IsOptional())
 	}
 
 	return ref, nil
@@ -74,12 +87,27 @@ func (e *executor) resolveReference(op *script.Primary, name string, v interface
 		}
 	}()
 
+	switch r := v.(type) {
+	case *Record:
+		if ret, ok := r.Get(name); ok {
+			return ret, nil
+		}
+		return v, errors.NoField(op.Pos, v, name)
+
+	case *script.EnumDec:
+		// Members of an enum, e.g. Status.Running
+		if ret, ok := r.Value(name); ok {
+			return ret, nil
+		}
+		return v, errors.Errorf(op.Pos, "%q is not a member of %s", name, r.Name)
+	}
+
 	tv := reflect.ValueOf(v)
 	ti := reflect.Indirect(tv)
 
 	switch ti.Kind() {
 	case reflect.Struct:
-		tf := ti.FieldByName(name)
+		tf := fieldByName(ti, name)
 		if tf.IsValid() {
 			ret = tf.Interface()
 			return
@@ -110,34 +138,41 @@ func (e *executor) resolveReference(op *script.Primary, name string, v interface
 	return v, errors.NoField(op.Pos, v, name)
 }
 
-func (e *executor) resolveArray(op *script.Primary, v interface{}) (interface{}, error) {
+// resolveArray resolves any indices against v.
+//
+// The returned bool is false if an optional index, e.g. ?.[i], was applied to null.
+// In that case the result is null and the rest of the chain should not be evaluated.
+func (e *executor) resolveArray(op *script.Primary, v interface{}) (interface{}, bool, error) {
 	// Nothing to do
 	if !op.Ident.IsIndexed() {
-		return v, nil
+		return v, true, nil
 	}
 
 	// Run through each dimension and set v to the result of each lookup
 	for _, dimension := range op.Ident.Index {
-		err := e.Expression(dimension)
-		if err != nil {
-			return nil, errors.Error(dimension.Pos, err)
+		if dimension.Optional && v == nil {
+			return nil, false, nil
 		}
 
-		index, err := e.calculator.Pop()
-		if err != nil {
-			return nil, errors.Error(dimension.Pos, err)
+		var err error
+		if dimension.Slice {
+			v, err = e.resolveSlice(v, dimension)
+		} else {
+			var index interface{}
+			index, err = e.indexValue(dimension)
+			if err == nil {
+				v, err = e.resolveArrayIndex(index, v, dimension)
+			}
 		}
-
-		v, err = e.resolveArrayIndex(index, v, dimension)
 		if err != nil {
-			return nil, errors.Error(dimension.Pos, err)
+			return nil, false, errors.Error(dimension.Pos, err)
 		}
 	}
 
-	return v, nil
+	return v, true, nil
 }
 
-func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Expression) (ret interface{}, err error) {
+func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Index) (ret interface{}, err error) {
 	// Any panics get resolved to errors
 	defer func() {
 		if err1 := recover(); err1 != nil {
@@ -145,6 +180,20 @@ func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Exp
 		}
 	}()
 
+	if r, ok := v.(*Record); ok {
+		var name string
+		name, err = calculator.GetString(index)
+		if err != nil {
+			return nil, errors.Error(dimension.Pos, err)
+		}
+
+		ret, ok = r.Get(name)
+		if !ok && !dimension.Optional {
+			return nil, errors.Errorf(dimension.Pos, "%s has no field %q", r.TypeName(), name)
+		}
+		return
+	}
+
 	tv := reflect.ValueOf(v)
 	ti := reflect.Indirect(tv)
 
@@ -157,11 +206,15 @@ func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Exp
 			return nil, errors.Error(dimension.Pos, err)
 		}
 
-		if idx < 0 || idx >= ti.Len() {
+		i, ok := e.checkIndex(idx, ti.Len())
+		if !ok {
+			if dimension.Optional {
+				return nil, nil
+			}
 			return nil, errors.Errorf(dimension.Pos, "Index out of bounds %d", idx)
 		}
 
-		ret = ti.Index(idx).Interface()
+		ret = ti.Index(i).Interface()
 
 	case reflect.Struct:
 		var name string
@@ -170,10 +223,10 @@ func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Exp
 			return nil, errors.Error(dimension.Pos, err)
 		}
 
-		tf := ti.FieldByName(name)
+		tf := fieldByName(ti, name)
 		if tf.IsValid() {
 			ret = tf.Interface()
-		} else {
+		} else if !dimension.Optional {
 			return nil, errors.Errorf(dimension.Pos, "%T has no field %q", v, name)
 		}
 
@@ -181,8 +234,8 @@ func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Exp
 		me := ti.MapIndex(reflect.ValueOf(index))
 		if me.IsValid() {
 			ret = me.Interface()
-		} else {
-			return nil, errors.Errorf(dimension.Pos, "%T has no field %v", v, v)
+		} else if !dimension.Optional {
+			return nil, errors.Errorf(dimension.Pos, "%T has no field %v", v, index)
 		}
 
 	default:
@@ -193,10 +246,13 @@ func (e *executor) resolveArrayIndex(index, v interface{}, dimension *script.Exp
 }
 
 func (e *executor) resolveFunction(op *script.CallFunc, v interface{}) (ret interface{}, err error) {
+	if r, ok := v.(*Record); ok {
+		return e.callMethod(op, r)
+	}
 
 	ti := reflect.ValueOf(v)
 
-	tf := ti.MethodByName(op.Name)
+	tf := methodByName(ti, op.Name)
 	if tf.IsValid() {
 		ret, err = e.callReflectFunc(op, tf)
 		return
@@ -0,0 +1,398 @@
+package executor
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+	"reflect"
+)
+
+// indexValue evaluates the index of a single element Index, e.g. [i]
+func (e *executor) indexValue(op *script.Index) (interface{}, error) {
+	if op.Low == nil {
+		return nil, errors.Errorf(op.Pos, "missing index")
+	}
+
+	err := e.Expression(op.Low)
+	if err != nil {
+		return nil, errors.Error(op.Pos, err)
+	}
+
+	return e.calculator.Pop()
+}
+
+// intExpression evaluates an optional expression returning it as an int.
+// If op is nil then def is returned.
+func (e *executor) intExpression(op *script.Expression, def int) (int, error) {
+	if op == nil {
+		return def, nil
+	}
+
+	err := e.Expression(op)
+	if err != nil {
+		return 0, errors.Error(op.Pos, err)
+	}
+
+	v, err := e.calculator.Pop()
+	if err != nil {
+		return 0, errors.Error(op.Pos, err)
+	}
+
+	i, err := calculator.GetInt(v)
+	return i, errors.Error(op.Pos, err)
+}
+
+// checkIndex returns the true index into something of the given length and true if it's within bounds.
+// If negative indices are enabled then a negative index is relative to the end.
+func (e *executor) checkIndex(idx, length int) (int, bool) {
+	if idx < 0 && e.negativeIndices {
+		idx += length
+	}
+	return idx, idx >= 0 && idx < length
+}
+
+// sliceBound converts a bound of a slice expression, handling negative indices if enabled
+func (e *executor) sliceBound(idx, length int) int {
+	if idx < 0 && e.negativeIndices {
+		return idx + length
+	}
+	return idx
+}
+
+// sliceBounds evaluates the bounds of a slice expression against v.
+//
+// The defaults are as in go, so low defaults to 0, high to the length and max to the capacity.
+func (e *executor) sliceBounds(v reflect.Value, op *script.Index) (low, high, max int, err error) {
+	length, capacity := v.Len(), v.Len()
+	if v.Kind() == reflect.Slice {
+		capacity = v.Cap()
+	}
+
+	if op.Max != nil && op.High == nil {
+		return 0, 0, 0, errors.Errorf(op.Pos, "middle index required in 3-index slice")
+	}
+
+	low, err = e.intExpression(op.Low, 0)
+	if err == nil {
+		high, err = e.intExpression(op.High, length)
+	}
+	if err == nil {
+		max, err = e.intExpression(op.Max, capacity)
+	}
+	if err != nil {
+		return 0, 0, 0, errors.Error(op.Pos, err)
+	}
+
+	low = e.sliceBound(low, length)
+	high = e.sliceBound(high, length)
+	max = e.sliceBound(max, length)
+
+	if low < 0 || high < low || max < high || max > capacity {
+		if op.Max != nil {
+			return 0, 0, 0, errors.Errorf(op.Pos, "slice bounds out of range [%d:%d:%d] with capacity %d", low, high, max, capacity)
+		}
+		return 0, 0, 0, errors.Errorf(op.Pos, "slice bounds out of range [%d:%d] with capacity %d", low, high, capacity)
+	}
+
+	return low, high, max, nil
+}
+
+// resolveSlice implements slice expressions, e.g. s[low:high] or s[low:high:max]
+func (e *executor) resolveSlice(v interface{}, op *script.Index) (interface{}, error) {
+	if op.Optional && v == nil {
+		return nil, nil
+	}
+
+	ti := sliceable(reflect.Indirect(reflect.ValueOf(v)))
+
+	switch ti.Kind() {
+	case reflect.String:
+		if op.Max != nil {
+			return nil, errors.Errorf(op.Pos, "3-index slice of string")
+		}
+
+		low, high, _, err := e.sliceBounds(ti, op)
+		if err != nil {
+			return nil, err
+		}
+		return ti.Slice(low, high).Interface(), nil
+
+	case reflect.Array, reflect.Slice:
+		low, high, max, err := e.sliceBounds(ti, op)
+		if err != nil {
+			return nil, err
+		}
+		return ti.Slice3(low, high, max).Interface(), nil
+
+	default:
+		return nil, errors.Errorf(op.Pos, "cannot slice %T", v)
+	}
+}
+
+// sliceable returns v if it can be sliced.
+// Arrays held in an interface are not addressable, so a copy is made to allow them to be sliced.
+func sliceable(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Array && !v.CanAddr() {
+		a := reflect.New(v.Type()).Elem()
+		a.Set(v)
+		return a
+	}
+	return v
+}
+
+// assignableValue converts v, so it can be assigned to something of type t
+func assignableValue(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+
+	rv, err := calculator.Cast(reflect.ValueOf(v), t)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if !rv.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("cannot use %T as %s", v, t)
+	}
+
+	return rv, nil
+}
+
+// resolveTarget resolves the container of an indexed assignment, e.g. for a.b[i][j] = v it
+// returns a.b[i] and the final Index [j].
+func (e *executor) resolveTarget(op *script.Primary) (interface{}, *script.Index, error) {
+	v, exists := e.state.Get(op.Ident.Ident)
+	if !exists {
+		return nil, nil, errors.Errorf(op.Pos, "%q undefined", op.Ident.Ident)
+	}
+
+	for p := op; ; {
+		indices := p.Ident.Index
+		if p.Pointer == nil {
+			indices = indices[:len(indices)-1]
+		}
+
+		for _, dimension := range indices {
+			var err error
+			if dimension.Slice {
+				v, err = e.resolveSlice(v, dimension)
+			} else {
+				var index interface{}
+				index, err = e.indexValue(dimension)
+				if err == nil {
+					v, err = e.resolveArrayIndex(index, v, dimension)
+				}
+			}
+			if err != nil {
+				return nil, nil, errors.Error(dimension.Pos, err)
+			}
+		}
+
+		if p.Pointer == nil {
+			return v, p.Ident.Index[len(p.Ident.Index)-1], nil
+		}
+
+		p = p.Pointer
+		if p.Ident == nil || p.Ident.Ident == "" {
+			return nil, nil, errors.Errorf(p.Pos, "Assignment without target")
+		}
+
+		var err error
+		v, err = e.resolveReference(p, p.Ident.Ident, v)
+		if err != nil {
+			return nil, nil, errors.Error(p.Pos, err)
+		}
+	}
+}
+
+// assignIndex implements assignment to an indexed target, e.g. a[i] = v, m["key"] = v,
+// or a slice range, e.g. a[low:high] = b where b must have the same length as the range.
+func (e *executor) assignIndex(op *script.Assignment, primary *script.Primary) (err error) {
+	// Any panics get resolved to errors
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = errors.Errorf(op.Pos, "%v", err1)
+		}
+	}()
+
+	container, dimension, err := e.resolveTarget(primary)
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+
+	tv := reflect.Indirect(reflect.ValueOf(container))
+
+	if dimension.Slice {
+		return e.assignSlice(op, tv, dimension)
+	}
+
+	index, err := e.indexValue(dimension)
+	if err != nil {
+		return errors.Error(dimension.Pos, err)
+	}
+
+	// Process RHS to get value
+	v, err := e.assignmentValue(op, func() (interface{}, error) {
+		return e.resolveArrayIndex(index, container, dimension)
+	})
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+
+	if r, ok := container.(*Record); ok {
+		name, err := calculator.GetString(index)
+		if err != nil {
+			return errors.Error(dimension.Pos, err)
+		}
+
+		if !r.Set(name, v) {
+			return errors.Errorf(dimension.Pos, "%s has no field %q", r.TypeName(), name)
+		}
+		return nil
+	}
+
+	switch tv.Kind() {
+	case reflect.Array, reflect.Slice:
+		idx, err := calculator.GetInt(index)
+		if err != nil {
+			return errors.Error(dimension.Pos, err)
+		}
+
+		i, ok := e.checkIndex(idx, tv.Len())
+		if !ok {
+			return errors.Errorf(dimension.Pos, "Index out of bounds %d", idx)
+		}
+
+		elem := tv.Index(i)
+		if !elem.CanSet() {
+			return errors.Errorf(dimension.Pos, "Cannot set index of %T", container)
+		}
+
+		val, err := assignableValue(v, elem.Type())
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		elem.Set(val)
+
+	case reflect.Map:
+		if tv.IsNil() {
+			return errors.Errorf(dimension.Pos, "Cannot set entry in nil map")
+		}
+
+		key, err := assignableValue(index, tv.Type().Key())
+		if err != nil {
+			return errors.Error(dimension.Pos, err)
+		}
+
+		val, err := assignableValue(v, tv.Type().Elem())
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		tv.SetMapIndex(key, val)
+
+	case reflect.Struct:
+		name, err := calculator.GetString(index)
+		if err != nil {
+			return errors.Error(dimension.Pos, err)
+		}
+
+		f := fieldByName(tv, name)
+		if !f.IsValid() || !f.CanSet() {
+			return errors.Errorf(dimension.Pos, "Cannot set %q on %T", name, container)
+		}
+
+		val, err := assignableValue(v, f.Type())
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		f.Set(val)
+
+	default:
+		return errors.Errorf(dimension.Pos, "Cannot set index of %T", container)
+	}
+
+	return nil
+}
+
+// assignSlice implements assignment to a slice range, e.g. a[low:high] = b.
+// The elements of b are copied into a, so b must be the same length as the range.
+func (e *executor) assignSlice(op *script.Assignment, tv reflect.Value, dimension *script.Index) error {
+	switch {
+	case tv.Kind() != reflect.Slice && !(tv.Kind() == reflect.Array && tv.CanAddr()):
+		return errors.Errorf(dimension.Pos, "Cannot assign to slice of %s", tv.Kind())
+
+	case dimension.Max != nil:
+		return errors.Errorf(dimension.Pos, "Cannot assign to 3-index slice")
+
+	case op.AugmentedOp != nil:
+		return errors.Errorf(op.Pos, "Cannot use %s= with a slice", *op.AugmentedOp)
+	}
+
+	low, high, _, err := e.sliceBounds(tv, dimension)
+	if err != nil {
+		return errors.Error(dimension.Pos, err)
+	}
+
+	v, err := e.assignmentValue(op, nil)
+	if err != nil {
+		return errors.Error(op.Pos, err)
+	}
+
+	src := reflect.ValueOf(v)
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return errors.Errorf(op.Pos, "Cannot assign %T to a slice", v)
+	}
+
+	if src.Len() != high-low {
+		return errors.Errorf(op.Pos, "Cannot assign %d elements to slice of length %d", src.Len(), high-low)
+	}
+
+	dst := tv.Slice(low, high)
+	et := dst.Type().Elem()
+	for i := 0; i < src.Len(); i++ {
+		val, err := assignableValue(src.Index(i).Interface(), et)
+		if err != nil {
+			return errors.Error(op.Pos, err)
+		}
+		dst.Index(i).Set(val)
+	}
+
+	return nil
+}
+
+// assignmentValue evaluates the right hand side of an assignment, leaving it on the stack
+// as the result of the assignment.
+//
+// If the assignment is augmented, e.g. a[i] += v then current is used to get the existing value.
+func (e *executor) assignmentValue(op *script.Assignment, current func() (interface{}, error)) (interface{}, error) {
+	err := e.assignment(op.Right)
+	if err != nil {
+		return nil, errors.Error(op.Pos, err)
+	}
+
+	if op.AugmentedOp == nil {
+		return e.calculator.Peek()
+	}
+
+	v, err := e.calculator.Pop()
+	if err != nil {
+		return nil, err
+	}
+
+	v0, err := current()
+	if err != nil {
+		return nil, err
+	}
+
+	// calculate existing op v to get the true new value
+	calc := e.calculator
+	calc.Push(v0)
+	calc.Push(v)
+	err = calc.Op2(*op.AugmentedOp)
+	if err != nil {
+		return nil, errors.Error(op.Pos, err)
+	}
+
+	return calc.Peek()
+}
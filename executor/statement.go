@@ -7,11 +7,11 @@ import (
 
 // Statements executes a Statements block.
 // Within this it runs with its own variable scope which is automatically closed when it completes
-func (e *executor) Statements(statements *script.Statements) error {
+func (e *executor) Statements(statements *script.Statements) Completion {
 
 	// Do nothing if it's an empty block
 	if statements == nil || len(statements.Statements) == 0 {
-		return nil
+		return normal()
 	}
 
 	e.state.NewScope()
@@ -19,65 +19,84 @@ func (e *executor) Statements(statements *script.Statements) error {
 
 	s := statements.Statements[0]
 	for s != nil {
-		if err := e.Statement(s); err != nil {
-			return errors.Error(s.Pos, err)
+		if c := e.Statement(s); !c.IsNormal() {
+			return c.WithPos(s.Pos)
 		}
 		s = s.Next
 	}
-	return nil
+	return normal()
 }
 
-func (e *executor) Statement(statement *script.Statement) error {
+func (e *executor) Statement(statement *script.Statement) Completion {
 	if statement == nil || statement.Empty {
-		return nil
+		return normal()
+	}
+
+	// Blocks are not debugged, just the statements within them
+	if e.debugHook != nil && statement.Block == nil {
+		if err := e.debug(statement); err != nil {
+			return errorCompletion(statement.Pos, err)
+		}
 	}
 
 	switch {
 	case statement.Block != nil:
-		return errors.Error(statement.Pos, e.Statements(statement.Block))
+		return e.Statements(statement.Block).WithPos(statement.Pos)
 
 	case statement.Expression != nil:
 		// Wrap visit to Expression, so we don't leak return values on the stack
 		_, _, err := e.calculator.Calculate(func() error {
 			return errors.Error(statement.Pos, e.Expression(statement.Expression))
 		})
-		return err
+		return errorCompletion(statement.Pos, err)
 
 	case statement.For != nil:
-		return errors.Error(statement.Pos, e.forStatement(statement.For))
+		return e.forStatement(statement.For).WithPos(statement.Pos)
 
 	case statement.ForRange != nil:
-		return errors.Error(statement.Pos, e.forRange(statement.ForRange))
+		return e.forRange(statement.ForRange).WithPos(statement.Pos)
 
 	case statement.IfStmt != nil:
-		return errors.Error(statement.Pos, e.ifStatement(statement.IfStmt))
+		return e.ifStatement(statement.IfStmt).WithPos(statement.Pos)
 
 	case statement.DoWhile != nil:
-		return errors.Error(statement.Pos, e.doWhile(statement.DoWhile))
+		return e.doWhile(statement.DoWhile).WithPos(statement.Pos)
 
 	case statement.Repeat != nil:
-		return errors.Error(statement.Pos, e.repeatUntil(statement.Repeat))
+		return e.repeatUntil(statement.Repeat).WithPos(statement.Pos)
 
 	case statement.While != nil:
-		return errors.Error(statement.Pos, e.while(statement.While))
+		return e.while(statement.While).WithPos(statement.Pos)
 
 	case statement.Return != nil:
-		return errors.Error(statement.Pos, e.returnStatement(statement.Return))
+		return e.returnStatement(statement.Return).WithPos(statement.Pos)
+
+	case statement.Defer != nil:
+		return e.deferStatement(statement.Defer).WithPos(statement.Pos)
+
+	case statement.Yield != nil:
+		return e.yieldStatement(statement.Yield).WithPos(statement.Pos)
+
+	case statement.Go != nil:
+		return e.goStatement(statement.Go).WithPos(statement.Pos)
+
+	case statement.Select != nil:
+		return e.selectStatement(statement.Select).WithPos(statement.Pos)
 
 	case statement.Break:
-		return errors.Break()
+		return Completion{Type: Break}
 
 	case statement.Continue:
-		return errors.Continue()
+		return Completion{Type: Continue}
 
 	case statement.Switch != nil:
-		return errors.Error(statement.Pos, e.switchStatement(statement.Switch))
+		return e.switchStatement(statement.Switch).WithPos(statement.Pos)
 
 	case statement.Try != nil:
-		return errors.Error(statement.Pos, e.try(statement.Try))
+		return e.try(statement.Try).WithPos(statement.Pos)
 
 	default:
 		// This will fail if we add a new statement, but it's not yet implemented.
-		return errors.Errorf(statement.Pos, "unimplemented statement reached")
+		return errorCompletion(statement.Pos, errors.Errorf(statement.Pos, "unimplemented statement reached"))
 	}
 }
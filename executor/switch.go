@@ -2,52 +2,52 @@ package executor
 
 import (
 	"github.com/peter-mount/go-script/calculator"
-	"github.com/peter-mount/go-script/errors"
 	"github.com/peter-mount/go-script/script"
 )
 
-func (e *executor) switchStatement(op *script.Switch) (err error) {
+func (e *executor) switchStatement(op *script.Switch) Completion {
 	// if present calculate the first Expression which we will compare against the case's
 	var left interface{}
 	hasLeft := op.Expression != nil
 	if hasLeft {
+		var err error
 		left, err = e.calculator.MustCalculate(func() error { return e.Expression(op.Expression) })
 		if err != nil {
-			return
+			return errorCompletion(op.Pos, err)
 		}
 	}
 
 	for _, c := range op.Case {
 		for _, expr := range c.Expression {
 			var right interface{}
-			b := false
 
 			switch {
 			case expr.String != nil:
 				right = *expr.String
 
 			case expr.Expression != nil:
+				var err error
 				right, err = e.calculator.MustCalculate(func() error { return e.Expression(expr.Expression) })
 				if err != nil {
-					return
+					return errorCompletion(op.Pos, err)
 				}
 			}
 
-			b, err = e.switchCase(c, hasLeft, left, right)
-			if b || err != nil {
-				return err
+			if e.switchCase(hasLeft, left, right) {
+				return e.Statement(c.Statement).WithPos(c.Pos)
 			}
 		}
 	}
 
 	// Default clause if we get to this point
 	if op.Default != nil {
-		return errors.Error(op.Pos, e.Statement(op.Default))
+		return e.Statement(op.Default).WithPos(op.Pos)
 	}
-	return nil
+	return normal()
 }
 
-func (e *executor) switchCase(c *script.SwitchCase, hasLeft bool, left, right interface{}) (bool, error) {
+// switchCase returns true if the case matches
+func (e *executor) switchCase(hasLeft bool, left, right interface{}) bool {
 	// Ignore errors here, they will be treated as false
 	b := false
 	if hasLeft {
@@ -55,10 +55,5 @@ func (e *executor) switchCase(c *script.SwitchCase, hasLeft bool, left, right in
 	} else {
 		b, _ = calculator.GetBool(right)
 	}
-
-	if b {
-		return true, errors.Error(c.Pos, e.Statement(c.Statement))
-	}
-
-	return false, nil
+	return b
 }
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test_compare tests equality and ordering of collections, null and time.Time,
+// and the in operator
+func Test_compare(t *testing.T) {
+	now := time.Now()
+
+	params := map[string]interface{}{
+		"a":     []int{1, 2, 3},
+		"b":     []int{1, 2, 3},
+		"c":     []interface{}{1, 2},
+		"m1":    map[string]interface{}{"x": 1, "y": []int{1}},
+		"m2":    map[string]interface{}{"x": 1, "y": []int{1}},
+		"m3":    map[string]interface{}{"x": 2},
+		"empty": nil,
+		"now":   now,
+		"later": now.Add(time.Minute),
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    bool
+		wantErr string
+	}{
+		{name: "slice ==", script: `a == b`, want: true},
+		{name: "slice !=", script: `a != c`, want: true},
+		{name: "map ==", script: `m1 == m2`, want: true},
+		{name: "map !=", script: `m1 != m3`, want: true},
+		{name: "null == null", script: `null == null`, want: true},
+		{name: "value == null", script: `a == null`, want: false},
+		{name: "value != null", script: `a != null`, want: true},
+		{name: "null var == null", script: `empty == null`, want: true},
+		{name: "null == value", script: `null == 1`, want: false},
+		{name: "null field", script: `m3.x != null && m3.x > 1`, want: true},
+		{name: "time ==", script: `now == now`, want: true},
+		{name: "time <", script: `now < later`, want: true},
+		{name: "time >", script: `now > later`, want: false},
+		{name: "time >=", script: `later >= now`, want: true},
+		{name: "time <=", script: `now <= now`, want: true},
+		{name: "null <", script: `null < 1`, wantErr: "unsupported"},
+
+		{name: "in slice", script: `2 in a`, want: true},
+		{name: "in slice missing", script: `4 in a`, want: false},
+		{name: "in map", script: `"x" in m1`, want: true},
+		{name: "in map missing", script: `"z" in m1`, want: false},
+		{name: "in string", script: `"ell" in "hello"`, want: true},
+		{name: "in string missing", script: `"x" in "hello"`, want: false},
+		{name: "in null", script: `1 in empty`, want: false},
+		{name: "not in", script: `!(4 in a)`, want: true},
+		{name: "in precedence", script: `1 + 1 in a && "y" in m1`, want: true},
+		{name: "in int", script: `1 in 1`, wantErr: "operation \"int in int\" unsupported"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := parser.New().ParseString(test.name, "main() { result = "+test.script+" }")
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			for k, v := range params {
+				globals.Declare(k)
+				globals.Set(k, v)
+			}
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if result != test.want {
+				t.Errorf("expected %v got %v", test.want, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"testing"
+)
+
+// Test_completion tests that break, continue and return complete correctly
+// when they pass through other statements
+func Test_completion(t *testing.T) {
+	tests := []struct {
+		name           string
+		script         string
+		expectedResult interface{}
+	}{
+		{
+			// return from within nested loops
+			name:           "return nested loops",
+			script:         `f() { for i:=0;i<10;i++ { for j:=0;j<10;j++ { if i*j==12 return i*100+j } } return -1 } main() { result = f() }`,
+			expectedResult: 206,
+		},
+		{
+			// break within a try must not be consumed by the catch block
+			name:           "break in try",
+			script:         `main() { result=0 for i:=0;i<10;i++ { try { if i==3 break result=i } catch(e) { result=-1 } } }`,
+			expectedResult: 2,
+		},
+		{
+			// continue within a try must not be consumed by the catch block
+			name:           "continue in try",
+			script:         `main() { result=0 for i:=0;i<10;i++ { try { if i>3 continue result=i } catch(e) { result=-1 } } }`,
+			expectedResult: 3,
+		},
+		{
+			// return within a try still runs finally
+			name:           "return in try",
+			script:         `f() { try { return 1 } finally { result=2 } } main() { result = 0 f() }`,
+			expectedResult: 2,
+		},
+		{
+			// return within finally takes precedence
+			name:           "return in finally",
+			script:         `f() { try { return 1 } finally { return 3 } } main() { result = f() }`,
+			expectedResult: 3,
+		},
+		{
+			// break within a switch within a loop exits the loop
+			name:           "break in switch",
+			script:         `main() { for i:=0;i<10;i++ { result=i switch i { case 4: break } } }`,
+			expectedResult: 4,
+		},
+		{
+			// function without a return
+			name:           "no return",
+			script:         `f() { for i:=0;i<10;i++ { if i==5 break } } main() { f() result=1 }`,
+			expectedResult: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+
+			err = exec.Run()
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			result, ok := globals.Get("result")
+			if !ok {
+				t.Errorf("result not returned")
+			} else if result != test.expectedResult {
+				t.Errorf("expected %v %T got %v %T", test.expectedResult, test.expectedResult, result, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/math"
+	"strings"
+	"testing"
+)
+
+// Test_complex tests complex number literals, operators and the cmplx package
+func Test_complex(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{name: "imaginary", script: `main() { result = 4i }`, want: "complex128 (0+4i)"},
+		{name: "imaginary float", script: `main() { result = 1.5i }`, want: "complex128 (0+1.5i)"},
+		{name: "literal", script: `main() { result = 3+4i }`, want: "complex128 (3+4i)"},
+		{name: "literal negative", script: `main() { result = 3-4i }`, want: "complex128 (3-4i)"},
+		{name: "precedence", script: `main() { result = 1+2i*2 }`, want: "complex128 (1+4i)"},
+		{name: "ident", script: `main() { i := 2 result = i*1i }`, want: "complex128 (0+2i)"},
+		{name: "multiply", script: `main() { z := 3+4i result = z * (3-4i) }`, want: "complex128 (25+0i)"},
+		{name: "power", script: `main() { result = 1i ** 2 }`, want: "complex128 (-1+0i)"},
+		{name: "equal", script: `main() { result = 2+0i == 2 }`, want: "bool true"},
+		{name: "compare", script: `main() { result = 1i < 2i }`, wantErr: "unsupported"},
+		{name: "complex", script: `main() { result = complex(1, 2) }`, want: "complex128 (1+2i)"},
+		{name: "real", script: `main() { result = real(3+4i) }`, want: "float64 3"},
+		{name: "imag", script: `main() { result = imag(3+4i) }`, want: "float64 4"},
+		{name: "abs", script: `main() { result = cmplx.Abs(3+4i) }`, want: "float64 5"},
+		{name: "sqrt", script: `main() { result = cmplx.Sqrt(-4) }`, want: "complex128 (0+2i)"},
+		{name: "conj", script: `main() { result = cmplx.Conj(3+4i) }`, want: "complex128 (3-4i)"},
+		{name: "rect", script: `main() { result = cmplx.Rect(2, 0) }`, want: "complex128 (2+0i)"},
+		{name: "phase", script: `main() { result = cmplx.Phase(-1) }`, want: "float64 3.141592653589793"},
+		{name: "float arg", script: `main() { result = math.Sqrt(4+0i) }`, want: "float64 2"},
+		{name: "float arg imaginary", script: `main() { result = math.Sqrt(4+1i) }`, wantErr: "not float"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if got := fmt.Sprintf("%T %v", result, result); got != test.want {
+				t.Errorf("expected %s got %s", test.want, got)
+			}
+		})
+	}
+}
+
+// Test_complex_error tests complex() does not leave a value on the stack when an argument is invalid
+func Test_complex_error(t *testing.T) {
+	p, err := parser.New().ParseString("complex error", `main() { complex(1, 2i) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := executor.Lookup("complex")
+	call := p.FunDec[0].FunBody.Statements[0].Expression.Right.Left.Primary().CallFunc
+
+	// The stack is only available within the calculation
+	var stack []interface{}
+	calc := exec.Calculator()
+	err = calc.Exec(func() error {
+		err := f(exec, call)
+		stack = calc.Stack()
+		return err
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "not float") {
+		t.Fatalf("expected not float error got %v", err)
+	}
+	if len(stack) != 0 {
+		t.Errorf("expected an empty stack got %v", stack)
+	}
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/sync"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type concurrencyAdder struct{}
+
+func (concurrencyAdder) Add(ch chan interface{}, a, b int) { ch <- a + b }
+
+func (concurrencyAdder) Recv() <-chan interface{} { return make(chan interface{}) }
+
+// Test_concurrency tests go statements, channels and the sync package
+func Test_concurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		// Channels
+		{name: "buffered", script: `main() { ch := chan(2) ch <- 1 ch <- 2 result = <-ch + <-ch }`, want: 3},
+		{name: "len", script: `main() { ch := chan(3) ch <- 1 result = len(ch) }`, want: 1},
+		{name: "closed", script: `main() { ch := chan(1) ch <- 1 close(ch) a := <-ch b := <-ch result = a == 1 && b == null }`, want: true},
+		{name: "send closed", script: `main() { ch := chan(1) close(ch) ch <- 1 }`, wantErr: "send on closed channel"},
+		{name: "close closed", script: `main() { ch := chan() close(ch) close(ch) }`, wantErr: "close of closed channel"},
+		{name: "not channel", script: `main() { ch := 1 ch <- 1 }`, wantErr: "int is not a channel"},
+		{name: "receive not channel", script: `main() { result = <-"a" }`, wantErr: "string is not a channel"},
+		{name: "null channel", script: `main() { ch := null ch <- 1 }`, wantErr: "send to null channel"},
+		{name: "receive only", script: `main() { ch := adder.Recv() ch <- 1 }`, wantErr: "cannot send to receive-only channel"},
+		{name: "negative size", script: `main() { ch := chan(-1) }`, wantErr: "negative buffer size -1"},
+
+		// go statements
+		{
+			name:   "unbuffered",
+			script: `send(ch, v) { ch <- v * 2 } main() { ch := chan() go send(ch, 21) result = <-ch }`,
+			want:   42,
+		},
+		{
+			name: "wait group",
+			script: `worker(wg, mu, i) {
+				defer wg.Done()
+				mu.Lock()
+				defer mu.Unlock()
+				result = result + i
+			}
+			main() {
+				result = 0
+				wg := sync.WaitGroup()
+				mu := sync.Mutex()
+				for i := 1; i <= 10; i++ {
+					wg.Add(1)
+					go worker(wg, mu, i)
+				}
+				wg.Wait()
+			}`,
+			want: 55,
+		},
+		{
+			name: "pipeline",
+			script: `produce(out, n) { for i := 1; i <= n; i++ { out <- i } close(out) }
+			square(in, out) {
+				for ;; {
+					v := <-in
+					if v == null { break }
+					out <- v * v
+				}
+				close(out)
+			}
+			main() {
+				a := chan() b := chan()
+				go produce(a, 4)
+				go square(a, b)
+				result = 0
+				for v := <-b; v != null; v = <-b { result += v }
+			}`,
+			want: 30,
+		},
+		{
+			name:   "arguments evaluated",
+			script: `f(ch, v) { ch <- v } main() { ch := chan(3) for i := 0; i < 3; i++ { go f(ch, i) } result = <-ch + <-ch + <-ch }`,
+			want:   3,
+		},
+		{
+			name:   "globals shared",
+			script: `f(ch) { result = "set" ch <- true } main() { ch := chan() go f(ch) <-ch }`,
+			want:   "set",
+		},
+		{
+			name:   "method",
+			script: `type C struct { Ch } (c C) Send(v) { c.Ch <- v } main() { c := C(chan()) go c.Send(7) result = <-c.Ch }`,
+			want:   7,
+		},
+		{
+			name:   "go method",
+			script: `main() { ch := chan() go adder.Add(ch, 1, 2) result = <-ch }`,
+			want:   3,
+		},
+		{
+			name:   "optional",
+			script: `main() { a := null go a?.Add(1) result = 1 }`,
+			want:   1,
+		},
+		{name: "undefined", script: `main() { go f() }`, wantErr: `function "f" not defined`},
+		{name: "builtin", script: `main() { go print(1) }`, wantErr: `go cannot call builtin "print"`},
+		{name: "no method", script: `main() { go adder.Sub() }`, wantErr: `has no function "Sub"`},
+		{
+			name:    "goroutine error",
+			script:  `f(wg) { defer wg.Done() throw("failed") } main() { wg := sync.WaitGroup() wg.Add(1) go f(wg) wg.Wait() }`,
+			wantErr: "failed",
+		},
+		{
+			name:   "goroutine error recovered",
+			script: `f(wg) { defer wg.Done() defer recover() throw("failed") } main() { wg := sync.WaitGroup() wg.Add(1) go f(wg) wg.Wait() result = 1 }`,
+			want:   1,
+		},
+
+		// select
+		{
+			name:   "select receive",
+			script: `main() { a := chan(1) b := chan(1) b <- 5 select { case v := <-a: result = v case v := <-b: result = v * 2 } }`,
+			want:   10,
+		},
+		{
+			name:   "select assign",
+			script: `main() { a := chan(1) a <- 5 select { case result = <-a: } }`,
+			want:   5,
+		},
+		{
+			name:   "select send",
+			script: `main() { a := chan(1) select { case a <- 3: result = <-a } }`,
+			want:   3,
+		},
+		{
+			name:   "select bare receive",
+			script: `main() { a := chan(1) a <- 3 select { case <-a: result = "r" default: result = "d" } }`,
+			want:   "r",
+		},
+		{
+			name:   "select default",
+			script: `main() { a := chan() select { case <-a: result = "r" case a <- 1: result = "s" default: result = "d" } }`,
+			want:   "d",
+		},
+		{
+			name:   "select null channel",
+			script: `main() { a := null b := chan(1) b <- 1 select { case <-a: result = "a" case <-b: result = "b" } }`,
+			want:   "b",
+		},
+		{
+			name:   "select scope",
+			script: `main() { a := chan(1) a <- 1 v := 2 select { case v := <-a: } result = v }`,
+			want:   2,
+		},
+		{
+			name:   "select in loop",
+			script: `main() { a := chan(3) a <- 1 a <- 2 a <- 3 result = 0 for ;; { select { case v := <-a: result += v default: break } } }`,
+			want:   6,
+		},
+		{
+			name: "select closed",
+			script: `main() {
+				done := chan()
+				work := chan()
+				go func1(done)
+				select {
+					case <-work: result = "work"
+					case <-done: result = "done"
+				}
+			}
+			func1(done) { close(done) }`,
+			want: "done",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("adder")
+			globals.Set("adder", concurrencyAdder{})
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
+
+// Test_concurrency_parse tests go and select statements which are rejected by the parser
+func Test_concurrency_parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{name: "go not call", script: `main() { go a.b }`, wantErr: "go requires a function call"},
+		{name: "select expression", script: `main() { select { case a == 1: b = 2 } }`, wantErr: "select case must be a channel send or receive"},
+		{name: "select assign", script: `main() { select { case a = 1: b = 2 } }`, wantErr: "select case must be a channel send or receive"},
+		{name: "select indexed", script: `main() { select { case a[0] = <-c: b = 2 } }`, wantErr: "select case must be a channel send or receive"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parser.New().ParseString(test.name, test.script)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Expected error %q got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test_concurrency_warnings tests sends which were probably meant to be a comparison, e.g. a<-1
+func Test_concurrency_warnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		warnings []string
+	}{
+		{name: "send", script: `main() { a := chan(1) a<-1 }`},
+		{name: "send field", script: `main() { a.b <- 1 }`},
+		{name: "send call", script: `main() { f() <- 1 }`},
+		{name: "send received", script: `main() { <-a <- 1 }`},
+		{name: "send ternary", script: `main() { c ? a : b <- 1 }`},
+		{name: "select send", script: `main() { select { case a <- 1: b = 2 } }`},
+		{name: "comparison", script: `main() { if a < -1 { b = 2 } }`},
+		{name: "literal", script: `main() { 1<-2 }`, warnings: []string{"send to a value which cannot be a channel"}},
+		{name: "operator", script: `main() { a+1<-2 }`, warnings: []string{"send to a value which cannot be a channel"}},
+		{name: "negated", script: `main() { -a<-2 }`, warnings: []string{"send to a value which cannot be a channel"}},
+		{name: "if", script: `main() { if a<-1 { b = 2 } }`, warnings: []string{"channel send has no value"}},
+		{name: "while", script: `main() { while a<-1 { b = 2 } }`, warnings: []string{"channel send has no value"}},
+		{name: "for", script: `main() { for i := 0; i<-1; i++ { b = 2 } }`, warnings: []string{"channel send has no value"}},
+		{name: "return", script: `f() { return a<-1 } main() { f() }`, warnings: []string{"channel send has no value"}},
+		{name: "assigned", script: `main() { b := a<-1 }`, warnings: []string{"channel send has no value"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var warnings []string
+			for _, w := range p.Warnings {
+				warnings = append(warnings, w.Error())
+			}
+
+			if len(warnings) != len(test.warnings) {
+				t.Fatalf("expected %q got %q", test.warnings, warnings)
+			}
+			for i, w := range test.warnings {
+				if !strings.Contains(warnings[i], w) {
+					t.Errorf("expected %q got %q", w, warnings[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_debug tests the DebugHook is called before each statement with the functions being executed
+func Test_debug(t *testing.T) {
+	p, err := parser.New().ParseString("test", `add(a, b) {
+    c := a + b
+    return c
+}
+main() {
+    x := 1
+    if x > 0 {
+        x = 10 + add(x, 2)
+    }
+    result = x
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trace []string
+	var locals, stack string
+	exec, err := executor.New(p, executor.WithDebugHook(func(d *executor.DebugState) error {
+		var names []string
+		for _, f := range d.Frames {
+			names = append(names, f.Function.Name)
+		}
+		trace = append(trace, fmt.Sprintf("%d %s", d.Current().Statement.Pos.Line, strings.Join(names, "<")))
+
+		if d.Current().Function.Name == "add" && d.Current().Statement.Pos.Line == 3 {
+			scope := d.Current().Scope
+			a, _ := scope.Get("a")
+			c, _ := scope.Get("c")
+			locals = fmt.Sprintf("a=%v c=%v", a, c)
+			stack = fmt.Sprintf("%v", d.Frames[1].Stack)
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.GlobalScope().Declare("result")
+
+	if err := exec.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"6 main", "7 main", "8 main", "2 add<main", "3 add<main", "10 main"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("expected %q got %q", want, trace)
+	}
+	if want := "a=1 c=3"; locals != want {
+		t.Errorf("expected locals %q got %q", want, locals)
+	}
+	if want := "[10]"; stack != want {
+		t.Errorf("expected caller stack %q got %q", want, stack)
+	}
+	if result, _ := exec.GlobalScope().Get("result"); result != 13 {
+		t.Errorf("expected 13 got %v", result)
+	}
+}
+
+// Test_debug_stop tests an error from the DebugHook stopping the script
+func Test_debug_stop(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{name: "error", err: fmt.Errorf("stopped"), wantErr: "stopped"},
+		{name: "exit", err: errors.Exit(3), wantErr: "exit status 3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString("test", `main() {
+    result = 1
+    try {
+        result = 2
+    } catch (e) {
+        result = 3
+    }
+}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p, executor.WithDebugHook(func(d *executor.DebugState) error {
+				if d.Current().Statement.Pos.Line == 4 {
+					return test.err
+				}
+				return nil
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			exec.GlobalScope().Declare("result")
+
+			err = exec.Run()
+			result, _ := exec.GlobalScope().Get("result")
+
+			// An error is caught like any other, but an exit ends the script
+			if errors.IsExit(test.err) {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) || result != 1 {
+					t.Errorf("expected %q with result 1 got %v %v", test.wantErr, err, result)
+				}
+			} else if err != nil || result != 3 {
+				t.Errorf("expected error to be caught got %v %v", err, result)
+			}
+		})
+	}
+}
+
+// Test_debug_threads tests goroutines are reported as separate threads
+func Test_debug_threads(t *testing.T) {
+	p, err := parser.New().ParseString("test", `worker(ch) {
+    ch <- 1
+}
+main() {
+    ch := chan(0)
+    go worker(ch)
+    result = <-ch
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	threads := make(chan string, 10)
+	exec, err := executor.New(p, executor.WithDebugHook(func(d *executor.DebugState) error {
+		threads <- fmt.Sprintf("%s %d %d", d.Current().Function.Name, d.Thread, d.Depth())
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.GlobalScope().Declare("result")
+
+	if err := exec.Run(); err != nil {
+		t.Fatal(err)
+	}
+	close(threads)
+
+	got := map[string]bool{}
+	for s := range threads {
+		got[s] = true
+	}
+	for _, want := range []string{"main 0 1", "worker 1 1"} {
+		if !got[want] {
+			t.Errorf("expected %q got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type deferPanic struct{}
+
+func (deferPanic) Panic() { panic("deferPanic") }
+
+// Test_defer tests the defer statement
+func Test_defer(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name:   "lifo",
+			script: `add(s) { result = result + s } main() { result = "" defer add("a") defer add("b") result = "c" }`,
+			want:   "cba",
+		},
+		{
+			name:   "after return",
+			script: `add(s) { result = result + s } f() { defer add("d") return "r" } main() { result = "" result = f() + result }`,
+			want:   "rd",
+		},
+		{
+			name:   "arguments evaluated",
+			script: `set(v) { result = v } main() { x := 1 defer set(x) x = 5 }`,
+			want:   1,
+		},
+		{
+			name:   "loop variable",
+			script: `add(s) { result = result + s } main() { result = "" for i := 0; i < 3; i++ { defer add(string(i)) } }`,
+			want:   "210",
+		},
+		{
+			name:   "receiver evaluated",
+			script: `type T struct { A } (t T) Get() { result = t.A } main() { t := T(1) defer t.Get() t = T(2) }`,
+			want:   1,
+		},
+		{
+			name:   "builtin",
+			script: `f(ch) { defer close(ch) } main() { ch := chan(1) f(ch) result = <-ch }`,
+			want:   nil,
+		},
+		{
+			name:   "builtin arguments evaluated",
+			script: `f(ch) { c := ch defer close(c) c = null } main() { ch := chan(1) f(ch) result = <-ch }`,
+			want:   nil,
+		},
+		{
+			name:   "optional",
+			script: `main() { t := null defer t?.Get() result = 1 }`,
+			want:   1,
+		},
+		{
+			name:    "not a call",
+			script:  `main() { x := 1 defer x }`,
+			wantErr: "defer requires a function call",
+		},
+		{
+			name:   "return value ignored",
+			script: `two() { return 2 } f() { defer two() return 1 } main() { result = f() }`,
+			want:   1,
+		},
+		{
+			name:   "override return",
+			script: `f() { defer setReturn(2) return 1 } main() { result = f() }`,
+			want:   2,
+		},
+		{
+			name:   "conditional override",
+			script: `check(x) { if x < 0 { setReturn(0) } } f(a) { defer check(a) return a } main() { result = f(-4) + f(3) }`,
+			want:   3,
+		},
+		{
+			name:    "setReturn outside defer",
+			script:  `main() { setReturn(1) }`,
+			wantErr: "setReturn outside of a deferred call",
+		},
+		{
+			name:   "on error",
+			script: `set(v) { result = v } f() { defer set("cleaned") throw("boom") } main() { try { f() } catch (e) { result = result + " " + e } }`,
+			want:   "cleaned test:1:50 boom",
+		},
+		{
+			name:    "error not overridden",
+			script:  `two() { return 2 } f() { defer two() throw("boom") } main() { result = f() }`,
+			wantErr: "boom",
+		},
+		{
+			name:    "setReturn keeps error",
+			script:  `f() { defer setReturn(2) throw("boom") } main() { result = f() }`,
+			wantErr: "boom",
+		},
+		{
+			name:   "override error",
+			script: `recovered() { e := recover() if e != null { setReturn("recovered " + e) } } f() { defer recovered() throw("boom") } main() { result = f() }`,
+			want:   "recovered test:1:101 boom",
+		},
+		{
+			name:   "recover without error",
+			script: `check() { result = isNull(recover()) } f() { defer check() return 1 } main() { f() }`,
+			want:   true,
+		},
+		{
+			name:   "recover deferred directly",
+			script: `f() { defer recover() throw("boom") } main() { f() result = "recovered" }`,
+			want:   "recovered",
+		},
+		{
+			name:    "error in defer",
+			script:  `f() { defer throw("second") return 1 } main() { result = f() }`,
+			wantErr: "second",
+		},
+		{
+			name:   "on panic",
+			script: `set(v) { result = v } f() { defer set("cleaned") p.Panic() } main() { try { f() } catch (e) { } }`,
+			want:   "cleaned",
+		},
+		{
+			name:    "panic in defer",
+			script:  `f() { defer p.Panic() return 1 } main() { result = f() }`,
+			wantErr: "deferPanic",
+		},
+		{
+			name:   "per call",
+			script: `add(s) { result = result + s } f(n) { defer add(string(n)) if n > 0 { f(n - 1) } } main() { result = "" f(2) }`,
+			want:   "012",
+		},
+		{
+			name:   "method",
+			script: `type T struct { A } (t T) SetA(v) { t.A = v } (t T) Set() { defer t.SetA(2) t.A = 1 } main() { t := T(0) t.Set() result = t.A }`,
+			want:   2,
+		},
+		{
+			name:   "not run without call",
+			script: `set(v) { result = v } main() { result = 1 if false { defer set(2) } }`,
+			want:   1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString("test", test.script)
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("p")
+			globals.Set("p", deferPanic{})
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
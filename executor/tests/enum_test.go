@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_enum tests enums declared within a script
+func Test_enum(t *testing.T) {
+	const enums = `
+enum Status { Running, Cancelled, Delayed }
+enum Colour {
+	Red
+	Green
+}
+`
+
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "string", script: `main() { result = string(Delayed) }`, want: "Delayed"},
+		{name: "qualified", script: `main() { result = Status.Cancelled.String() }`, want: "Cancelled"},
+		{name: "ordinal", script: `main() { result = Delayed.Ordinal() }`, want: 2},
+		{name: "concat", script: `main() { result = "status " + Running }`, want: "status Running"},
+		{name: "typeof", script: `main() { result = typeof(Red) }`, want: "Colour"},
+		{name: "equal", script: `main() { result = Running == Status.Running }`, want: true},
+		{name: "not equal", script: `main() { result = Running == Delayed }`, want: false},
+		{name: "other enum", script: `main() { result = Running == Red }`, want: false},
+		{name: "equal name", script: `main() { result = Running == "Running" }`, want: true},
+		{name: "values", script: `main() { result = len(Status.Values()) }`, want: 3},
+		{name: "parse", script: `main() { result = Status.Parse("Delayed") == Delayed }`, want: true},
+		{name: "parse invalid", script: `main() { result = Status.Parse("Late") }`, wantErr: `"Late" is not a member of Status`},
+		{name: "not member", script: `main() { result = Status.Late }`, wantErr: `"Late" is not a member of Status`},
+		{name: "shadowed", script: `main() { Running := 1 result = Running }`, want: 1},
+		{
+			name: "switch",
+			script: `main() {
+				s := Cancelled
+				switch s {
+					case Running: result = "r"
+					case Status.Cancelled, Delayed: result = "c"
+				}
+			}`,
+			want: "c",
+		},
+		{
+			name: "switch default",
+			script: `f(s) {
+				switch s {
+					case Running: return 1
+					case Cancelled: return 2
+					default: return 3
+				}
+			}
+			main() { result = f(Delayed) }`,
+			want: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, enums+test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
+
+// Test_enum_switch tests the warnings for a switch over an enum which does not handle every member
+func Test_enum_switch(t *testing.T) {
+	const enums = `enum Status { Running, Cancelled, Delayed } enum Colour { Red, Green } `
+
+	tests := []struct {
+		name     string
+		script   string
+		warnings []string
+	}{
+		{
+			name:     "missing",
+			script:   `main() { switch s { case Running: x = 1 } }`,
+			warnings: []string{"missing:1:81 switch over Status is missing Cancelled, Delayed and has no default"},
+		},
+		{
+			name:     "qualified",
+			script:   `main() { switch s { case Status.Running, Status.Delayed: x = 1 } }`,
+			warnings: []string{"switch over Status is missing Cancelled and has no default"},
+		},
+		{
+			name:     "nested",
+			script:   `main() { if true { for ;; { switch s { case Red: x = 1 } } } }`,
+			warnings: []string{"switch over Colour is missing Green and has no default"},
+		},
+		{
+			name:     "in case",
+			script:   `main() { switch s { case Red, Green: switch t { case Delayed: x = 1 } } }`,
+			warnings: []string{"switch over Status is missing Running, Cancelled and has no default"},
+		},
+		{
+			name:   "all members",
+			script: `main() { switch s { case Running, Cancelled: x = 1 case Delayed: x = 2 } }`,
+		},
+		{
+			name:   "default",
+			script: `main() { switch s { case Running: x = 1 default: x = 2 } }`,
+		},
+		{
+			name:   "not enum",
+			script: `main() { switch s { case Running: x = 1 case "Delayed": x = 2 } }`,
+		},
+		{
+			name:   "mixed enums",
+			script: `main() { switch s { case Running: x = 1 case Red: x = 2 } }`,
+		},
+		{
+			name:   "no expression",
+			script: `main() { switch { case s == Running: x = 1 } }`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, enums+test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var warnings []string
+			for _, w := range exec.Warnings() {
+				warnings = append(warnings, w.Error())
+			}
+
+			if len(warnings) != len(test.warnings) {
+				t.Fatalf("expected %q got %q", test.warnings, warnings)
+			}
+			for i, w := range test.warnings {
+				if !strings.Contains(warnings[i], w) {
+					t.Errorf("expected %q got %q", w, warnings[i])
+				}
+			}
+		})
+	}
+}
+
+// Test_enum_declaration tests errors in enum declarations
+func Test_enum_declaration(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{name: "duplicate enum", script: `enum A { X } enum A { Y } main() {}`, wantErr: `enum "A" already defined`},
+		{name: "duplicate member", script: `enum A { X, X } main() {}`, wantErr: `enum member "X" already defined in A`},
+		{name: "member in other enum", script: `enum A { X } enum B { X } main() {}`, wantErr: `enum member "X" already defined in A`},
+		{name: "type", script: `type A struct { X } enum A { Y } main() {}`, wantErr: `enum "A" already defined as a type`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = executor.New(p)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Expected error %q got %v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/sync"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_exit tests the status returned by RunStatus from exit() and main()
+func Test_exit(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		args    []string
+		want    int
+		result  interface{}
+		wantErr string
+	}{
+		{name: "no status", script: `main() { result = 1 }`, want: 0, result: 1},
+		{name: "main returns", script: `main() { return 3 }`, want: 3},
+		{name: "main returns string", script: `main() { return "a" }`, want: 0},
+		{name: "exit", script: `main() { exit(2) result = 1 }`, want: 2},
+		{name: "exit no code", script: `main() { result = 1 exit() }`, want: 0, result: 1},
+		{name: "exit in function", script: `f() { exit(4) } main() { f() return 1 }`, want: 4},
+		{name: "exit not caught", script: `main() { try { exit(5) } catch (e) { result = "caught" } }`, want: 5},
+		{name: "exit runs finally", script: `main() { try { exit(5) } finally { result = "finally" } }`, want: 5, result: "finally"},
+		{name: "exit not replaced", script: `main() { try { exit(5) } finally { throw("failed") } }`, want: 5},
+		{name: "exit runs defer", script: `set(v) { result = v } main() { defer set("deferred") exit(6) }`, want: 6, result: "deferred"},
+		{name: "exit not overridden", script: `zero() { return 0 } main() { defer zero() exit(6) }`, want: 6},
+		{name: "exit not recovered", script: `zero() { recover() setReturn(0) } main() { defer zero() exit(6) }`, want: 6},
+		{name: "exit in goroutine", script: `f(wg) { defer wg.Done() exit(7) } main() { wg := sync.WaitGroup() wg.Add(1) go f(wg) wg.Wait() }`, want: 7},
+		{name: "exit not int", script: `main() { exit("a") }`, wantErr: "a"},
+		{name: "args", script: `main(args) { result = string(len(args)) + ":" + args[1] }`, args: []string{"a", "b"}, result: "2:b"},
+		{name: "no args", script: `main(args) { result = len(args) }`, result: 0},
+		{name: "too many parameters", script: `main(a, b) {}`, wantErr: "main() can only declare a parameter for its arguments"},
+		{name: "error", script: `main() { throw("failed") }`, wantErr: "failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p, executor.WithArgs(test.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+
+			code, err := exec.RunStatus()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			if code != test.want {
+				t.Errorf("expected status %d got %d", test.want, code)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.result) {
+				t.Errorf("expected %T %v got %T %v", test.result, test.result, result, result)
+			}
+		})
+	}
+}
+
+// Test_exit_run tests Run returns an ExitError when the script calls exit()
+func Test_exit_run(t *testing.T) {
+	p, err := parser.New().ParseString("test", `main() { exit(3) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = exec.Run()
+	if exit, ok := errors.GetExit(err); !ok || exit.Code() != 3 {
+		t.Errorf("expected exit status 3 got %v", err)
+	}
+}
+
+// Test_call tests calling functions declared in a script from go
+func Test_call(t *testing.T) {
+	p, err := parser.New().ParseString("test", `add(a, b) { return a + b } count(n) { for i := 0; i < n; i++ { yield i } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := exec.Call("add", 1, 2); err != nil || v != 3 {
+		t.Errorf("expected 3 got %v %v", v, err)
+	}
+
+	v, err := exec.Call("count", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := v.(*executor.Generator)
+	if !ok {
+		t.Fatalf("expected generator got %T", v)
+	}
+	sum := 0
+	g.ForEach(func(v interface{}) { sum += v.(int) })
+	if sum != 3 {
+		t.Errorf("expected 3 got %d", sum)
+	}
+
+	if _, err := exec.Call("add", 1); err == nil || !strings.Contains(err.Error(), "parameter mismatch") {
+		t.Errorf("expected parameter mismatch got %v", err)
+	}
+	if _, err := exec.Call("missing"); err == nil || !strings.Contains(err.Error(), `function "missing" not defined`) {
+		t.Errorf("expected not defined got %v", err)
+	}
+}
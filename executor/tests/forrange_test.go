@@ -28,6 +28,43 @@ func (i *forRangeIterator) Next() interface{} {
 	return r
 }
 
+// forRangeSeq matches go's iter.Seq[int], yielding 0..n-1
+func forRangeSeq(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// forRangeSeq2 matches go's iter.Seq2[string, int]
+func forRangeSeq2(yield func(string, int) bool) {
+	for i, k := range []string{"a", "b", "c"} {
+		if !yield(k, i) {
+			return
+		}
+	}
+}
+
+// forRangeBadSeq ignores yield returning false
+func forRangeBadSeq(yield func(int) bool) {
+	for i := 0; i < 3; i++ {
+		yield(i)
+	}
+}
+
+// forRangeChannel returns a closed channel containing values 1..n
+func forRangeChannel(n int) chan int {
+	ch := make(chan int, n)
+	for i := 1; i <= n; i++ {
+		ch <- i
+	}
+	close(ch)
+	return ch
+}
+
 type nonIterator struct{}
 
 func (ni *nonIterator) HasNext() bool { return true }
@@ -419,6 +456,132 @@ func Test_forrange(t *testing.T) {
 			expectedError: "cannot range over *tests.nonIterator",
 		},
 
+		// ===============
+		// single and no variables
+		// ===============
+		{
+			name:           "single slice",
+			script:         `main() { for i := range it { result = i } }`,
+			params:         map[string]interface{}{"it": []string{"a", "b", "c"}},
+			expectedResult: 2,
+		},
+		{
+			name:           "single map",
+			script:         `main() { for k = range it { result = k } }`,
+			params:         map[string]interface{}{"it": map[string]int{"a": 1}},
+			expectedResult: "a",
+		},
+		{
+			name:           "single integer",
+			script:         `main() { result = 0 for i := range it { result += i } }`,
+			params:         map[string]interface{}{"it": 4},
+			expectedResult: 6,
+		},
+		{
+			name:           "single iterator",
+			script:         `main() { for v := range it { result = v } }`,
+			params:         map[string]interface{}{"it": &forRangeIterator{Value: 10, End: 13, Inc: 1}},
+			expectedResult: 12,
+		},
+		{
+			name:           "single existing",
+			script:         `main() { v := 0 for v = range it { } result = v }`,
+			params:         map[string]interface{}{"it": 3},
+			expectedResult: 2,
+		},
+		{
+			name:           "no variables",
+			script:         `main() { result = 0 for range it { result++ } }`,
+			params:         map[string]interface{}{"it": []int{5, 6, 7}},
+			expectedResult: 3,
+		},
+		{
+			name:           "scoped",
+			script:         `main() { i := "outer" for i := range it { } result = i }`,
+			params:         map[string]interface{}{"it": 3},
+			expectedResult: "outer",
+		},
+
+		// ===============
+		// channels
+		// ===============
+		{
+			name:           "channel",
+			script:         `main() { result = 0 for v := range it { result += v } }`,
+			params:         map[string]interface{}{"it": forRangeChannel(4)},
+			expectedResult: 10,
+		},
+		{
+			name:           "channel index",
+			script:         `main() { for i, v := range it { result = i * 10 + v } }`,
+			params:         map[string]interface{}{"it": forRangeChannel(3)},
+			expectedResult: 23,
+		},
+		{
+			name:           "channel break",
+			script:         `main() { for v := range it { result = v if v == 2 { break } } }`,
+			params:         map[string]interface{}{"it": forRangeChannel(4)},
+			expectedResult: 2,
+		},
+		{
+			name:          "channel send only",
+			script:        `main() { for v := range it { } }`,
+			params:        map[string]interface{}{"it": make(chan<- int)},
+			expectedError: "cannot receive from send-only channel",
+		},
+
+		// ===============
+		// iter.Seq and iter.Seq2
+		// ===============
+		{
+			name:           "seq",
+			script:         `main() { result = 0 for v := range it { result += v } }`,
+			params:         map[string]interface{}{"it": forRangeSeq(5)},
+			expectedResult: 10,
+		},
+		{
+			name:           "seq index",
+			script:         `main() { for i, v := range it { result = i * 10 + v } }`,
+			params:         map[string]interface{}{"it": forRangeSeq(3)},
+			expectedResult: 22,
+		},
+		{
+			name:           "seq break",
+			script:         `main() { for v := range it { if v > 2 { break } result = v } }`,
+			params:         map[string]interface{}{"it": forRangeSeq(1000)},
+			expectedResult: 2,
+		},
+		{
+			name:           "seq return",
+			script:         `f() { for v := range it { if v == 3 { return v } } } main() { result = f() }`,
+			params:         map[string]interface{}{"it": forRangeSeq(10)},
+			expectedResult: 3,
+		},
+		{
+			name:           "seq2",
+			script:         `main() { result = "" for k, v := range it { result = result + k + string(v) } }`,
+			params:         map[string]interface{}{"it": forRangeSeq2},
+			expectedResult: "a0b1c2",
+		},
+		{
+			name:           "seq2 single",
+			script:         `main() { result = "" for k := range it { result = result + k } }`,
+			params:         map[string]interface{}{"it": forRangeSeq2},
+			expectedResult: "abc",
+		},
+		{
+			name:          "seq continued",
+			script:        `main() { for v := range it { break } }`,
+			params:        map[string]interface{}{"it": forRangeBadSeq},
+			expectedError: "range function continued iteration after loop exit",
+		},
+		{
+			name:          "not seq",
+			script:        `main() { for v := range it { } }`,
+			params:        map[string]interface{}{"it": func(int) {}},
+			expectedError: "cannot range over func(int)",
+		},
+
 		// ===============
 		// integer ranges
 		// ===============
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-kernel/v2/util"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_generator tests functions which yield values
+func Test_generator(t *testing.T) {
+	const generators = `
+count(n) { for i := 0; i < n; i++ { yield i } }
+naturals() { for i := 1; ; i++ { yield i } }
+addLog(s) { log = log + s }
+logged(n) {
+	defer addLog("d")
+	try {
+		for i := 0; i < n; i++ {
+			log = log + string(i)
+			yield i
+		}
+	} finally {
+		log = log + "f"
+	}
+}
+type Range struct { From, To }
+(r Range) Each() { for i := r.From; i <= r.To; i++ { yield i } }
+`
+
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "range", script: `main() { result = "" for i, v := range count(3) { result = result + string(i) + string(v) } }`, want: "001122"},
+		{name: "empty", script: `main() { result = 0 for _, v := range count(0) { result = 1 } }`, want: 0},
+		{name: "infinite", script: `main() { result = 0 for _, v := range naturals() { if v > 4 { break } result += v } }`, want: 10},
+		{name: "lazy", script: `main() { log = "" g := logged(3) result = log }`, want: ""},
+		{name: "complete", script: `main() { log = "" for _, v := range logged(2) { log = log + "." } result = log }`, want: "0.1.fd"},
+		{name: "break", script: `main() { log = "" for _, v := range logged(5) { if v == 1 { break } } result = log }`, want: "01fd"},
+		{name: "return in loop", script: `f() { for _, v := range logged(5) { return v } } main() { log = "" f() result = log }`, want: "0fd"},
+		{name: "next", script: `main() { g := count(2) a := g.Next() b := g.Next() result = string(a) + string(b) + string(g.HasNext()) }`, want: "01false"},
+		{name: "method", script: `main() { result = 0 for _, v := range Range(2, 4).Each() { result += v } }`, want: 9},
+		{
+			name:   "deferred generator",
+			script: `inner() { yield 9 } g() { defer inner() yield 1 yield 2 } main() { result = "" for _, v := range g() { result = result + string(v) } }`,
+			want:   "12",
+		},
+		{name: "go generator", script: `main() { go count(2) result = 1 }`, want: 1},
+		{name: "go generator method", script: `main() { go Range(2, 4).Each() result = 1 }`, want: 1},
+		{
+			name:   "return ends",
+			script: `g() { yield 1 return 5 yield 2 } main() { result = 0 for _, v := range g() { result += v } }`,
+			want:   1,
+		},
+		{
+			name:   "nested",
+			script: `pairs(n) { for _, a := range count(n) { for _, b := range count(n) { yield string(a) + string(b) } } } main() { result = "" for _, v := range pairs(2) { result = result + v + " " } }`,
+			want:   "00 01 10 11 ",
+		},
+		{
+			name:   "locals",
+			script: `g() { x := 1 yield x x = x + 1 yield x } main() { x := 10 result = 0 for _, v := range g() { result += v } result += x }`,
+			want:   13,
+		},
+		{
+			name:    "error",
+			script:  `g() { yield 1 throw("failed") } main() { for _, v := range g() { result = v } }`,
+			wantErr: "failed",
+		},
+		{
+			name:   "error caught",
+			script: `g() { yield 1 throw("failed") } main() { try { for _, v := range g() { result = v } } catch (e) { result = result + 1 } }`,
+			want:   2,
+		},
+		{
+			name:    "next exhausted",
+			script:  `main() { g := count(0) result = g.Next() }`,
+			wantErr: "iterator out of bounds",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, generators+test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("log")
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
+
+// Test_generator_host tests a generator being consumed by go code
+func Test_generator_host(t *testing.T) {
+	p, err := parser.New().ParseString("test", `
+stop() { stopped = true }
+squares() { defer stop() for i := 1; ; i++ { yield i * i } }
+main() { stopped = false result = squares() }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	globals := exec.GlobalScope()
+	globals.Declare("result")
+	globals.Declare("stopped")
+
+	if err := exec.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := globals.Get("result")
+	it, ok := result.(util.Iterator[interface{}])
+	if !ok {
+		t.Fatalf("expected iterator got %T", result)
+	}
+
+	var got []interface{}
+	for it.HasNext() && len(got) < 4 {
+		got = append(got, it.Next())
+	}
+	if want := []interface{}{1, 4, 9, 16}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+
+	result.(*executor.Generator).Stop()
+	if stopped, _ := globals.Get("stopped"); stopped != true {
+		t.Errorf("expected generator to be stopped")
+	}
+}
+
+// Test_generator_parse tests functions containing yield are marked as generators
+func Test_generator_parse(t *testing.T) {
+	p, err := parser.New().ParseString("test", `g() { yield 1 } f() { return 1 } main() {}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range p.FunDec {
+		if want := f.Name == "g"; f.Generator != want {
+			t.Errorf("%s expected Generator %v", f.Name, want)
+		}
+	}
+}
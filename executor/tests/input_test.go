@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"github.com/peter-mount/go-script/script"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_input tests entries in an interactive session, each of which is evaluated
+// in turn against the same executor
+func Test_input(t *testing.T) {
+	type entry struct {
+		src     string
+		want    interface{}
+		wantErr string
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+	}{
+		{
+			name: "variables",
+			entries: []entry{
+				{src: `x := 1`},
+				{src: `x + 2`, want: 3},
+				{src: `x = 5`},
+				{src: `x`, want: 5},
+				{src: `y := x * 2 y`, want: 10},
+			},
+		},
+		{
+			name: "functions",
+			entries: []entry{
+				{src: `f(a) { return a * 2 }`},
+				{src: `f(3)`, want: 6},
+				{src: `f(a) { return a * 3 }`},
+				{src: `f(3)`, want: 9},
+				{src: `g(a) {
+					return f(a) + 1
+				}
+				g(1)`, want: 4},
+			},
+		},
+		{
+			name: "statements",
+			entries: []entry{
+				{src: `x := 0`},
+				{src: `for i := 1; i <= 4; i++ { x += i }`},
+				{src: `if (x == 10) { x = "ten" }`},
+				{src: `x`, want: "ten"},
+				{src: `return 7`, want: 7},
+				{src: `print("")`},
+			},
+		},
+		{
+			name: "types",
+			entries: []entry{
+				{src: `type P struct { A }`},
+				{src: `(p P) Twice() { return p.A * 2 }`},
+				{src: `P(4).Twice()`, want: 8},
+				{src: `type P struct { B }`, wantErr: `type "P" already defined`},
+			},
+		},
+		{
+			name: "imports",
+			entries: []entry{
+				{src: `import ( "math" )`},
+				{src: `math.Sqrt(16.0)`, want: 4.0},
+				{src: `import ( "math" )`},
+			},
+		},
+		{
+			name: "errors",
+			entries: []entry{
+				{src: `x := 1`},
+				{src: `nope()`, wantErr: `function "nope" not defined`},
+				{src: `x`, want: 1},
+				{src: `yield 1`, wantErr: "yield outside of a function"},
+				{src: `defer println(x)`, wantErr: "defer outside of a function"},
+				{src: `f(a) {`, wantErr: "unexpected"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := executor.New(&script.Script{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := parser.New()
+			for _, e := range test.entries {
+				in, err := p.ParseInput(test.name, e.src)
+				if err == nil {
+					var got interface{}
+					got, _, err = exec.Evaluate(in)
+					if err == nil {
+						if e.wantErr != "" {
+							t.Fatalf("%s: expected error %q got none", e.src, e.wantErr)
+						}
+						if !reflect.DeepEqual(got, e.want) {
+							t.Errorf("%s: expected %T %v got %T %v", e.src, e.want, e.want, got, got)
+						}
+						continue
+					}
+				}
+
+				if e.wantErr == "" || !strings.Contains(err.Error(), e.wantErr) {
+					t.Fatalf("%s: %v", e.src, err)
+				}
+			}
+		})
+	}
+}
+
+// Test_input_load tests declarations loaded into an executor from another script
+func Test_input_load(t *testing.T) {
+	p := parser.New()
+
+	s, err := p.ParseString("main", `main() { result = double(21) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.GlobalScope().Declare("result")
+
+	lib, err := p.ParseString("lib", `double(a) { return a * 2 }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Load(lib); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exec.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := exec.GlobalScope().Get("result"); result != 42 {
+		t.Errorf("expected 42 got %v", result)
+	}
+
+	if want := []string{"double", "main"}; !reflect.DeepEqual(exec.Functions(), want) {
+		t.Errorf("expected %v got %v", want, exec.Functions())
+	}
+
+	prog, err := executor.Compile(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.New().Load(lib); err == nil {
+		t.Errorf("expected error loading into a Program's executor")
+	}
+}
+
+// Test_input_main tests running an entry as the body of an implicit main(), as goscript -e does
+func Test_input_main(t *testing.T) {
+	in, err := parser.New().ParseInput("test", `double(a) { return a * 2 } inc() { result = result + 1 } defer inc() result = double(21)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exec, err := executor.New(in.Main())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.GlobalScope().Declare("result")
+
+	if err := exec.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := exec.GlobalScope().Get("result"); result != 43 {
+		t.Errorf("expected 43 got %v", result)
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/math"
+	"math"
+	"strings"
+	"testing"
+)
+
+// Test_numeric tests the integer kinds, math/big values and arithmetic errors within scripts
+func Test_numeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		mode    calculator.Mode
+		want    string
+		wantErr string
+	}{
+		{name: "int pow", script: `main() { result = 3 ** 4 }`, want: "int 81"},
+		{name: "int pow negative", script: `main() { result = 2 ** -2 }`, want: "float64 0.25"},
+		{name: "int64", script: `main() { result = i64 + 1 }`, want: "int64 9223372036854775807"},
+		{name: "uint64", script: `main() { result = u64 - 1 }`, want: "uint64 18446744073709551614"},
+		{name: "uint64 const", script: `main() { result = math.MaxUint64 / 5 }`, want: fmt.Sprintf("uint64 %d", uint64(math.MaxUint64/5))},
+		{name: "uint64 index", script: `main() { result = list[idx] }`, want: "int 30"},
+		{name: "big int", script: `main() { result = big.Int("123456789012345678901234567890") * 10 }`, want: "*big.Int 1234567890123456789012345678900"},
+		{name: "big int hex", script: `main() { result = big.Int("0xff") + 1 }`, want: "*big.Int 256"},
+		{name: "big int pow", script: `main() { result = big.Int(2) ** 64 }`, want: "*big.Int 18446744073709551616"},
+		{name: "big rat", script: `main() { result = big.Rat("1/3") + big.NewRat(1, 6) }`, want: "*big.Rat 1/2"},
+		{name: "big rat decimal", script: `main() { result = big.Rat("0.1") + big.Rat("0.2") == big.Rat("0.3") }`, want: "bool true"},
+		{name: "big float", script: `main() { result = big.FloatPrec(1, 100) / 4 }`, want: "*big.Float 0.25"},
+		{name: "big increment", script: `main() { result = big.Int(5) result++ }`, want: "*big.Int 6"},
+		{name: "big augmented", script: `main() { result = big.Int(5) result *= 3 }`, want: "*big.Int 15"},
+		{name: "big compare", script: `main() { result = big.Int(5) > 4 && big.Int(5) < 5.5 }`, want: "bool true"},
+		{name: "big in", script: `main() { result = big.Int(20) in list }`, want: "bool true"},
+		{name: "big go func", script: `main() { result = math.Sqrt(big.Int(16)) }`, want: "float64 4"},
+		{name: "wraps", script: `main() { result = i64 + 1 }`, want: "int64 9223372036854775807"},
+		{name: "overflow", script: `main() { result = i64 + 2 }`, mode: calculator.CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow increment", script: `main() { result = math.MaxInt result++ }`, mode: calculator.CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow augmented", script: `main() { result = u64 result += u64 }`, mode: calculator.CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow pow", script: `main() { result = 10 ** 19 }`, mode: calculator.CheckOverflow, wantErr: "integer overflow"},
+		{name: "overflow big", script: `main() { result = big.Int(10) ** 19 }`, mode: calculator.CheckOverflow, want: "*big.Int 10000000000000000000"},
+		{name: "div zero", script: `main() { result = 1 / 0 }`, wantErr: "division by zero"},
+		{name: "mod zero", script: `main() { result = i64 % 0 }`, wantErr: "division by zero"},
+		{name: "augmented div zero", script: `main() { result = 1 result /= 0 }`, wantErr: "division by zero"},
+		{name: "negative shift", script: `main() { result = 1 << -1 }`, wantErr: "negative shift amount"},
+		{name: "float div zero", script: `main() { result = 1.0 / 0 }`, want: "float64 +Inf"},
+		{name: "strict div zero", script: `main() { result = 1.0 / 0 }`, mode: calculator.StrictFloat, wantErr: "division by zero"},
+		{name: "strict nan", script: `main() { result = math.Sqrt(-1) * 2 }`, mode: calculator.StrictFloat, wantErr: "result is NaN"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var opts []executor.Option
+			if test.mode != 0 {
+				opts = append(opts, executor.WithCalculatorMode(test.mode))
+			}
+
+			exec, err := executor.New(p, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			for k, v := range map[string]interface{}{
+				"result": nil,
+				"i64":    int64(math.MaxInt64 - 1),
+				"u64":    uint64(math.MaxUint64),
+				"idx":    uint64(2),
+				"list":   []int{10, 20, 30},
+			} {
+				globals.Declare(k)
+				globals.Set(k, v)
+			}
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				// Errors must include the position in the script
+				if !strings.HasPrefix(err.Error(), test.name+":1:") {
+					t.Errorf("error without position %q", err.Error())
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if got := fmt.Sprintf("%T %v", result, result); got != test.want {
+				t.Errorf("expected %s got %s", test.want, got)
+			}
+		})
+	}
+}
@@ -167,11 +167,12 @@ func Test_operations(t *testing.T) {
 		// --------------------------------------------------
 
 		// Test ** multiplication
-		{expr: "%d ** %d", args: []any{1, 1}, want: math.Pow(1.0, 1.0)},
+		{expr: "%d ** %d", args: []any{1, 1}, want: 1},
 		{expr: "%f ** %d", args: []any{1.0, 2}, want: math.Pow(1.0, 2.0)},
 		{expr: "%d ** %f", args: []any{1, 3.0}, want: math.Pow(1.0, 3.0)},
-		{expr: "%d ** %d", args: []any{-2, 2}, want: math.Pow(-2.0, 2.0)},
-		{expr: "%d ** %d", args: []any{-2, 3}, want: math.Pow(-2.0, 3.0)},
+		{expr: "%d ** %d", args: []any{-2, 2}, want: 4},
+		{expr: "%d ** %d", args: []any{-2, 3}, want: -8},
+		{expr: "%d ** %d", args: []any{2, -2}, want: math.Pow(2.0, -2.0)},
 		// --------------------------------------------------
 
 		// Test && logical and
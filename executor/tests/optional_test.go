@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type optionalPerson struct {
+	Name    string
+	Address *optionalAddress
+}
+
+type optionalAddress struct {
+	Town string
+}
+
+// Test_optional tests the null-coalescing ?? and optional chaining ?. operators
+func Test_optional(t *testing.T) {
+	// value() counts the number of times it's called
+	functions := `value() { calls++ return "default" } `
+
+	data := map[string]interface{}{
+		"name": "test",
+		"address": map[string]interface{}{
+			"town": "Maidstone",
+		},
+		"tags":  []interface{}{"a", "b"},
+		"empty": nil,
+	}
+
+	person := &optionalPerson{Name: "Fred"}
+
+	tests := []struct {
+		name           string
+		script         string
+		expectedResult interface{}
+		expectedCalls  int
+		wantErr        string
+	}{
+		// ??
+		{name: "null ?? value", script: `main() { result = null ?? 1 }`, expectedResult: 1},
+		{name: "value ?? value", script: `main() { result = 2 ?? 1 }`, expectedResult: 2},
+		{name: "false ?? value", script: `main() { result = false ?? 1 }`, expectedResult: false},
+		{name: "chain ??", script: `main() { result = null ?? null ?? 3 ?? 4 }`, expectedResult: 3},
+		{name: "all null ??", script: `main() { result = null ?? null }`, expectedResult: nil},
+		{name: "?? lazy", script: `main() { result = data.name ?? value() }`, expectedResult: "test"},
+		{name: "?? evaluated", script: `main() { result = data.empty ?? value() }`, expectedResult: "default", expectedCalls: 1},
+		{name: "?? lower than ||", script: `main() { result = null ?? false || true }`, expectedResult: true},
+		{name: "?? in ternary", script: `main() { result = null ?? true ? "a" : "b" }`, expectedResult: "a"},
+
+		// ?.
+		{name: "?. present", script: `main() { result = data?.address?.town }`, expectedResult: "Maidstone"},
+		{name: "?. absent", script: `main() { result = data?.address?.postcode }`, expectedResult: nil},
+		{name: "?. absent parent", script: `main() { result = data?.location?.town }`, expectedResult: nil},
+		{name: "?. null receiver", script: `main() { result = data.empty?.town }`, expectedResult: nil},
+		{name: "?. short circuit", script: `main() { result = data.empty?.town.postcode }`, expectedResult: nil},
+		{name: "?. with ??", script: `main() { result = data?.location?.town ?? "unknown" }`, expectedResult: "unknown"},
+		{name: "?. struct", script: `main() { result = person?.Name }`, expectedResult: "Fred"},
+		{name: "?. struct nil", script: `main() { result = person.Address?.Town ?? "none" }`, expectedResult: "none"},
+		{name: "?. struct absent", script: `main() { result = person?.Age }`, expectedResult: nil},
+		{name: ". absent", script: `main() { result = data.location.town }`, wantErr: "has no field"},
+
+		// ?.[i]
+		{name: "?.[i] present", script: `main() { result = data.tags?.[1] }`, expectedResult: "b"},
+		{name: "?.[i] out of range", script: `main() { result = data.tags?.[5] }`, expectedResult: nil},
+		{name: "?.[i] null", script: `main() { result = data.empty?.[0] }`, expectedResult: nil},
+		{name: "?.[i] map", script: `main() { result = data?.["name"] }`, expectedResult: "test"},
+		{name: "?.[i] map absent", script: `main() { result = data?.["other"] ?? "x" }`, expectedResult: "x"},
+		{name: "[i] out of range", script: `main() { result = data.tags[5] }`, wantErr: "Index out of bounds"},
+
+		{name: "assign optional", script: `main() { data?.name = "x" }`, wantErr: "Cannot assign to optional chain"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := parser.New().ParseString(test.name, functions+test.script)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("calls")
+			globals.Set("calls", 0)
+			globals.Declare("data")
+			globals.Set("data", data)
+			globals.Declare("person")
+			globals.Set("person", person)
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.expectedResult) {
+				t.Errorf("expected %v got %v", test.expectedResult, result)
+			}
+
+			calls, _ := globals.Get("calls")
+			if calls != test.expectedCalls {
+				t.Errorf("expected %d calls got %v", test.expectedCalls, calls)
+			}
+		})
+	}
+}
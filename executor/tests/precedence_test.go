@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// binaryOperators are the binary operators shared with go
+var binaryOperators = []string{
+	"||", "&&",
+	"==", "!=", "<", "<=", ">", ">=",
+	"+", "-", "|", "^",
+	"*", "/", "%", "<<", ">>", "&", "&^",
+}
+
+// evalScript runs expr within a script with a=7, b=3 and c=2 returning the result
+func evalScript(t *testing.T, expr string) (interface{}, error) {
+	src := "main() { a=7 b=3 c=2 result = " + expr + " }"
+
+	s, err := parser.New().ParseString(expr, src)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := executor.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	globals := e.GlobalScope()
+	globals.Declare("result")
+
+	err = e.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	result, _ := globals.Get("result")
+	return result, nil
+}
+
+// evalGo evaluates expr as a go constant expression with a=7, b=3 and c=2.
+// Returns false if go would reject the expression, e.g. 7 && 3
+func evalGo(expr string) (interface{}, bool) {
+	pkg := types.NewPackage("tests", "tests")
+	for n, v := range map[string]int64{"a": 7, "b": 3, "c": 2} {
+		pkg.Scope().Insert(types.NewConst(token.NoPos, pkg, n, types.Typ[types.Int], constant.MakeInt64(v)))
+	}
+
+	tv, err := types.Eval(token.NewFileSet(), pkg, token.NoPos, expr)
+	if err != nil || tv.Value == nil {
+		return nil, false
+	}
+
+	switch tv.Value.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(tv.Value), true
+	case constant.Int:
+		v, _ := constant.Int64Val(tv.Value)
+		return int(v), true
+	default:
+		return nil, false
+	}
+}
+
+func testAgainstGo(t *testing.T, expr string) {
+	want, ok := evalGo(expr)
+	if !ok {
+		t.Skipf("%q is invalid in go", expr)
+		return
+	}
+
+	got, err := evalScript(t, expr)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%q got %T %v wanted %T %v", expr, got, got, want, want)
+	}
+}
+
+// Test_precedence_binary ensures every pair of binary operators has the same precedence
+// and associativity as go
+func Test_precedence_binary(t *testing.T) {
+	for _, op1 := range binaryOperators {
+		for _, op2 := range binaryOperators {
+			expr := fmt.Sprintf("a %s b %s c", op1, op2)
+			t.Run(expr, func(t *testing.T) {
+				testAgainstGo(t, expr)
+			})
+		}
+	}
+}
+
+// Test_precedence_logical tests the logical operators combined with comparisons
+func Test_precedence_logical(t *testing.T) {
+	for _, op1 := range []string{"||", "&&"} {
+		for _, op2 := range []string{"||", "&&"} {
+			for _, cmp := range []string{"==", "!=", "<", "<=", ">", ">="} {
+				for _, expr := range []string{
+					fmt.Sprintf("a %s b %s b %s c %s c %s a", cmp, op1, cmp, op2, cmp),
+					fmt.Sprintf("!(a %s b) %s b %s c %s !(c %s a)", cmp, op1, cmp, op2, cmp),
+					fmt.Sprintf("a+b %s c*b %s a-c %s b<<c %s a&^c %s b", cmp, op1, cmp, op2, cmp),
+				} {
+					t.Run(expr, func(t *testing.T) {
+						testAgainstGo(t, expr)
+					})
+				}
+			}
+		}
+	}
+}
+
+func Test_precedence_unary(t *testing.T) {
+	for _, expr := range []string{
+		"-a",
+		"+a",
+		"^a",
+		"-a + b",
+		"-a * -b",
+		"^a & b",
+		"^a | b",
+		"^-a",
+		"-^a",
+		// Note: "a - -b" would be a - (--b) as, unlike go, -- is not a distinct token
+		"+a - (-b)",
+		"-(a - b) * c",
+		"^(a ^ b) &^ c",
+		"!(a < b)",
+		"!(a < b) && !(b < c)",
+		"!(a < b) || b < c",
+		"-a << c",
+		"a - (-b) << c",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			testAgainstGo(t, expr)
+		})
+	}
+}
+
+func Test_precedence_power(t *testing.T) {
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		// ** is right associative
+		{expr: "c ** b ** c", want: 512},
+		{expr: "c ** c ** c ** 1", want: 16},
+		// ** of integers is an integer unless the exponent is negative
+		{expr: "c ** c ** b", want: 256},
+		// unary binds tighter than **
+		{expr: "-c ** c", want: 4},
+		{expr: "-(c ** c)", want: -4},
+		{expr: "c ** -c", want: math.Pow(2, -2)},
+		// ** binds tighter than the multiplicative operators
+		{expr: "a * c ** b", want: 56},
+		{expr: "c ** b * a", want: 56},
+		{expr: "c ** b / c", want: 4},
+		{expr: "a + c ** b", want: 15},
+		{expr: "a - c ** b - c", want: -3},
+		{expr: "c ** b > a", want: true},
+		{expr: "(a + b) ** c", want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalScript(t, tt.expr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q got %T %v wanted %T %v", tt.expr, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"sync"
+	"testing"
+)
+
+// Test_program tests a compiled script being run concurrently by executors from a Pool
+func Test_program(t *testing.T) {
+	p, err := parser.New().ParseString("test", `
+type Pair struct { A, B }
+(p Pair) Sum() { return p.A + p.B }
+square(v) { return v * v }
+main() {
+	runs = runs + 1
+	result = Pair(square(input), factor).Sum() + runs
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, err := executor.Compile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	globals := prog.Globals()
+	globals.Declare("factor")
+	globals.Set("factor", 100)
+	globals.Declare("runs")
+	globals.Set("runs", 0)
+
+	pool := executor.NewPool(prog)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			exec := pool.Get()
+			defer pool.Put(exec)
+
+			scope := exec.GlobalScope()
+			scope.Declare("input")
+			scope.Set("input", i)
+			scope.Declare("result")
+
+			if err := exec.Run(); err != nil {
+				errs <- err
+				return
+			}
+
+			// runs is copied into the executor so is always 1
+			result, _ := scope.Get("result")
+			if want := i*i + 100 + 1; result != want {
+				errs <- fmt.Errorf("input %d expected %d got %v", i, want, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The shared globals are unchanged
+	if runs, _ := globals.Get("runs"); runs != 0 {
+		t.Errorf("expected shared runs 0 got %v", runs)
+	}
+}
+
+// Test_program_reuse tests that an executor returned to a Pool does not keep its variables
+func Test_program_reuse(t *testing.T) {
+	p, err := parser.New().ParseString("test", `main() { if seen == null { seen = 0 } seen = seen + 1 }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, err := executor.Compile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog.Globals().Declare("seen")
+
+	pool := executor.NewPool(prog)
+	for i := 0; i < 3; i++ {
+		exec := pool.Get()
+		if err := exec.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		if seen, _ := exec.GlobalScope().Get("seen"); seen != 1 {
+			t.Errorf("run %d expected seen 1 got %v", i, seen)
+		}
+		pool.Put(exec)
+	}
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordPoint struct {
+	X, Y float64
+}
+
+type recordGeo struct{}
+
+func (recordGeo) Length(p recordPoint) float64 { return math.Hypot(p.X, p.Y) }
+
+func (recordGeo) Origin(p *recordPoint) bool { return p.X == 0 && p.Y == 0 }
+
+func (recordGeo) Keys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Test_record tests types and methods declared within a script
+func Test_record(t *testing.T) {
+	const types = `
+type Point struct { X, Y }
+type Line struct {
+	A
+	B
+}
+(p Point) Dist() { return math.Sqrt(p.X*p.X + p.Y*p.Y) }
+(p Point) Scale(f) {
+	p.X *= f
+	p.Y = p.Y * f
+}
+(p Point) Add(b) { return Point(p.X+b.X, p.Y+b.Y) }
+(l Line) Len() { return Point(l.B.X-l.A.X, l.B.Y-l.A.Y).Dist() }
+`
+
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		// Constructors
+		{name: "positional", script: `main() { p := Point(1, 2) result = p.X + p.Y }`, want: 3},
+		{name: "named", script: `main() { p := Point("Y": 2) result = p.Y }`, want: 2},
+		{name: "named unset", script: `main() { p := Point("Y": 2) result = p.X }`, want: nil},
+		{name: "empty", script: `main() { result = Point().X }`, want: nil},
+		{name: "too few", script: `main() { result = Point(1) }`, wantErr: "too few values in Point"},
+		{name: "too many", script: `main() { result = Point(1, 2, 3) }`, wantErr: "too many values in Point"},
+		{name: "unknown field", script: `main() { result = Point("Z": 1) }`, wantErr: `unknown field "Z" in Point`},
+		{name: "mixture", script: `main() { result = Point(1, "Y": 2) }`, wantErr: "mixture of field:value and value initializers"},
+
+		// Fields
+		{name: "set", script: `main() { p := Point(1, 2) p.X = 5 result = p.X }`, want: 5},
+		{name: "augmented", script: `main() { p := Point(1, 2) p.Y += 5 result = p.Y }`, want: 7},
+		{name: "index", script: `main() { p := Point(1, 2) result = p["Y"] }`, want: 2},
+		{name: "set index", script: `main() { p := Point(1, 2) p["Y"] = 4 result = p.Y }`, want: 4},
+		{name: "nested", script: `main() { l := Line(Point(0, 0), Point(1, 1)) l.B.X = 3 result = l.B.X }`, want: 3},
+		{name: "no field", script: `main() { result = Point(1, 2).Z }`, wantErr: `Point has no field "Z"`},
+		{name: "set no field", script: `main() { p := Point(1, 2) p.Z = 1 }`, wantErr: `Point has no field "Z"`},
+		{name: "optional", script: `main() { p := Point(1, 2) result = p?.Z }`, want: nil},
+		{name: "reference", script: `main() { p := Point(1, 2) q := p q.X = 9 result = p.X }`, want: 9},
+
+		// Methods
+		{name: "method", script: `main() { result = Point(3, 4).Dist() }`, want: 5.0},
+		{name: "method mutates", script: `main() { p := Point(3, 4) p.Scale(2) result = p.Dist() }`, want: 10.0},
+		{name: "method args", script: `main() { result = Point(1, 2).Add(Point(3, 4)).Y }`, want: 6},
+		{name: "method calls method", script: `main() { result = Line(Point(0, 0), Point(3, 4)).Len() }`, want: 5.0},
+		{name: "no method", script: `main() { result = Point(1, 2).Len() }`, wantErr: `Point has no method "Len"`},
+		{name: "method arg count", script: `main() { result = Point(1, 2).Scale() }`, wantErr: "parameter mismatch"},
+		{name: "operator method", script: `main() { result = Point(1, 2) + Point(3, 4) }`, wantErr: "unsupported"},
+
+		// Comparison and introspection
+		{name: "equal", script: `main() { result = Point(1, 2) == Point(1, 2) }`, want: true},
+		{name: "not equal", script: `main() { result = Point(1, 2) == Point(2, 1) }`, want: false},
+		{name: "typeof", script: `main() { result = typeof(Point(1, 2)) }`, want: "Point"},
+		{name: "fields", script: `main() { result = fields(Line()) }`, want: []string{"A", "B"}},
+		{name: "methods", script: `main() { result = methods(Point()) }`, want: []string{"Add", "Dist", "Scale"}},
+
+		// Passing to go functions
+		{name: "go struct", script: `main() { result = geo.Length(Point(3, 4)) }`, want: 5.0},
+		{name: "go struct pointer", script: `main() { result = geo.Origin(Point(0, 0)) }`, want: true},
+		{name: "go map", script: `main() { result = geo.Keys(Line()) }`, want: []string{"A", "B"}},
+		{name: "go struct invalid", script: `main() { result = geo.Length(Point("a", 4)) }`, wantErr: `field "X"`},
+
+		// Dotted assignment to existing map entries
+		{name: "map set", script: `main() { m := map() m.a = 1 m.a = 2 result = m.a }`, want: 2},
+		{name: "map augmented", script: `main() { m := map() m.a = 1 m.a += 2 result = m.a }`, want: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, `import ("math")`+types+test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("geo")
+			globals.Set("geo", recordGeo{})
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
+
+// Test_record_declaration tests errors in type and method declarations
+func Test_record_declaration(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{name: "duplicate type", script: `type A struct { X } type A struct { Y } main() {}`, wantErr: `type "A" already defined`},
+		{name: "duplicate field", script: `type A struct { X, X } main() {}`, wantErr: `duplicate field "X"`},
+		{name: "unknown receiver", script: `(a A) F() {} main() {}`, wantErr: `type "A" not defined`},
+		{name: "duplicate method", script: `type A struct { X } (a A) F() {} (a A) F() {} main() {}`, wantErr: "method A.F already defined"},
+		{name: "method field", script: `type A struct { X } (a A) X() {} main() {}`, wantErr: `both field and method named "X"`},
+		{name: "function type", script: `type A struct { X } A() {} main() {}`, wantErr: `function "A" already defined as a type`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = executor.New(p)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Expected error %q got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test_record_concurrent tests executors created concurrently from one script do not share its method tables
+func Test_record_concurrent(t *testing.T) {
+	p, err := parser.New().ParseString("concurrent", `
+import ( "math" )
+type Point struct { X, Y }
+(p Point) Length() { return math.Hypot(p.X, p.Y) }
+main() {
+	result = Point(3, 4).Length()
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			exec, err := executor.New(p)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			if err := exec.Run(); err != nil {
+				errs <- err
+				return
+			}
+
+			if result, _ := globals.Get("result"); result != 5.0 {
+				errs <- fmt.Errorf("expected 5 got %v", result)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The script is unchanged so it can be formatted as written
+	if alias := p.Import[0].Packages[0].As; alias != "" {
+		t.Errorf("expected no import alias got %q", alias)
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib/math"
+	_ "github.com/peter-mount/go-script/stdlib/time"
+	"testing"
+	"time"
+)
+
+type benchPoint struct {
+	X, Y float64
+}
+
+func (p benchPoint) Dist(o benchPoint) float64 {
+	dx, dy := p.X-o.X, p.Y-o.Y
+	return dx*dx + dy*dy
+}
+
+// benchmarkScript runs main() in a script b.N times.
+//
+// The script is parsed once, so the benchmark only measures the execution of the script,
+// where the reflective lookups of fields & methods dominate.
+func benchmarkScript(b *testing.B, src string, globals map[string]interface{}) {
+	s, err := parser.New().ParseString(b.Name(), src)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	exec, err := executor.New(s)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	scope := exec.GlobalScope()
+	for k, v := range globals {
+		scope.Declare(k)
+		scope.Set(k, v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := exec.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_fieldAccess accesses fields of a package and a go struct within a loop
+func Benchmark_fieldAccess(b *testing.B) {
+	benchmarkScript(b,
+		`main() { t:=0.0 for i:=0;i<100;i++ { t = t + math.Pi + p.X + p.Y } }`,
+		map[string]interface{}{"p": &benchPoint{X: 1, Y: 2}},
+	)
+}
+
+// Benchmark_methodCall calls methods on a package and a go struct within a loop
+func Benchmark_methodCall(b *testing.B) {
+	benchmarkScript(b,
+		`main() { t:=0.0 for i:=0;i<100;i++ { t = t + math.Sqrt(p.Dist(o)) + math.Abs(-1.0) } }`,
+		map[string]interface{}{
+			"p": benchPoint{X: 1, Y: 2},
+			"o": benchPoint{X: 4, Y: 6},
+		},
+	)
+}
+
+// Benchmark_timeMethods calls methods on values returned from the time package
+func Benchmark_timeMethods(b *testing.B) {
+	benchmarkScript(b,
+		`main() { for i:=0;i<100;i++ { d := time.Now().Sub(start) s := d.Seconds() } }`,
+		map[string]interface{}{"start": benchStart},
+	)
+}
+
+var benchStart = time.Now()
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"testing"
+)
+
+// Test_shortCircuit tests that && and || only evaluate their right hand side when required
+func Test_shortCircuit(t *testing.T) {
+	// yes() and no() count the number of times they are called
+	functions := `yes() { calls++ return true } no() { calls++ return false } `
+
+	tests := []struct {
+		name           string
+		script         string
+		params         map[string]interface{}
+		expectedResult interface{}
+		expectedCalls  int
+	}{
+		{name: "true && true", script: `main() { result = yes() && yes() }`, expectedResult: true, expectedCalls: 2},
+		{name: "true && false", script: `main() { result = yes() && no() }`, expectedResult: false, expectedCalls: 2},
+		{name: "false && true", script: `main() { result = no() && yes() }`, expectedResult: false, expectedCalls: 1},
+		{name: "false && false", script: `main() { result = no() && no() }`, expectedResult: false, expectedCalls: 1},
+		{name: "true || true", script: `main() { result = yes() || yes() }`, expectedResult: true, expectedCalls: 1},
+		{name: "true || false", script: `main() { result = yes() || no() }`, expectedResult: true, expectedCalls: 1},
+		{name: "false || true", script: `main() { result = no() || yes() }`, expectedResult: true, expectedCalls: 2},
+		{name: "false || false", script: `main() { result = no() || no() }`, expectedResult: false, expectedCalls: 2},
+		{name: "chain &&", script: `main() { result = yes() && no() && yes() && yes() }`, expectedResult: false, expectedCalls: 2},
+		{name: "chain ||", script: `main() { result = no() || yes() || no() || no() }`, expectedResult: true, expectedCalls: 2},
+		{name: "mixed", script: `main() { result = no() && yes() || yes() && no() }`, expectedResult: false, expectedCalls: 3},
+		{
+			// The right hand side would fail if evaluated as x is null
+			name:           "null guard",
+			script:         `main() { result = notNull(x) && x.Name == "a" }`,
+			params:         map[string]interface{}{"x": nil},
+			expectedResult: false,
+		},
+		{
+			name:           "null guard set",
+			script:         `main() { result = notNull(x) && x.Name == "a" }`,
+			params:         map[string]interface{}{"x": map[string]interface{}{"Name": "a"}},
+			expectedResult: true,
+		},
+		{
+			name:           "null guard or",
+			script:         `main() { result = isNull(x) || x.Name == "a" }`,
+			params:         map[string]interface{}{"x": nil},
+			expectedResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := parser.New().ParseString(test.name, functions+test.script)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			globals := exec.GlobalScope()
+			globals.Declare("result")
+			globals.Declare("calls")
+			globals.Set("calls", 0)
+
+			for k, v := range test.params {
+				globals.Declare(k)
+				globals.Set(k, v)
+			}
+
+			err = exec.Run()
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			result, _ := globals.Get("result")
+			if result != test.expectedResult {
+				t.Errorf("expected %v %T got %v %T", test.expectedResult, test.expectedResult, result, result)
+			}
+
+			calls, _ := globals.Get("calls")
+			if calls != test.expectedCalls {
+				t.Errorf("expected %d calls got %v", test.expectedCalls, calls)
+			}
+		})
+	}
+}
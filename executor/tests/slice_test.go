@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_slice tests slice expressions and indexed assignment
+func Test_slice(t *testing.T) {
+	tests := []struct {
+		name           string
+		script         string
+		negative       bool                   // Enable negative indices
+		expectedResult interface{}            // expected result
+		expected       map[string]interface{} // expected value of globals after the script
+		wantErr        string
+	}{
+		// Strings
+		{name: "string [low:high]", script: `main() { result = s[1:3] }`, expectedResult: "el"},
+		{name: "string [:high]", script: `main() { result = s[:2] }`, expectedResult: "he"},
+		{name: "string [low:]", script: `main() { result = s[3:] }`, expectedResult: "lo"},
+		{name: "string [:]", script: `main() { result = s[:] }`, expectedResult: "hello"},
+		{name: "string empty", script: `main() { result = s[2:2] }`, expectedResult: ""},
+		{name: "string expr", script: `main() { i:=1 result = s[i+1:len(s)-1] }`, expectedResult: "ll"},
+		{name: "string 3-index", script: `main() { result = s[1:2:3] }`, wantErr: "3-index slice of string"},
+		{name: "string out of range", script: `main() { result = s[1:10] }`, wantErr: "slice bounds out of range [1:10]"},
+		{name: "string inverted", script: `main() { result = s[3:1] }`, wantErr: "slice bounds out of range [3:1]"},
+
+		// Slices
+		{name: "slice [low:high]", script: `main() { result = a[1:3] }`, expectedResult: []int{2, 3}},
+		{name: "slice [:high]", script: `main() { result = a[:2] }`, expectedResult: []int{1, 2}},
+		{name: "slice [low:]", script: `main() { result = a[3:] }`, expectedResult: []int{4, 5}},
+		{name: "slice 3-index", script: `main() { result = a[1:3:4] }`, expectedResult: []int{2, 3}},
+		{name: "slice of slice", script: `main() { result = a[1:][1:][0] }`, expectedResult: 3},
+		{name: "slice middle required", script: `main() { result = a[1::3] }`, wantErr: "middle index required"},
+		{name: "slice out of range", script: `main() { result = a[:6] }`, wantErr: "slice bounds out of range"},
+		{name: "slice max out of range", script: `main() { result = a[1:2:6] }`, wantErr: "slice bounds out of range [1:2:6]"},
+		{name: "slice map", script: `main() { result = m["list"][1:] }`, expectedResult: []interface{}{"b", "c"}},
+		{name: "optional slice", script: `main() { result = m?.["none"]?.[1:] }`, expectedResult: nil},
+
+		// Arrays
+		{name: "array [low:high]", script: `main() { result = arr[1:3] }`, expectedResult: []int{20, 30}},
+		{name: "array [:]", script: `main() { result = arr[:] }`, expectedResult: []int{10, 20, 30}},
+
+		// Negative indices
+		{name: "negative disabled", script: `main() { result = a[-1] }`, wantErr: "Index out of bounds -1"},
+		{name: "negative slice disabled", script: `main() { result = s[-3:] }`, wantErr: "slice bounds out of range"},
+		{name: "negative index", script: `main() { result = a[-1] }`, negative: true, expectedResult: 5},
+		{name: "negative string", script: `main() { result = s[-3:] }`, negative: true, expectedResult: "llo"},
+		{name: "negative high", script: `main() { result = a[:-1] }`, negative: true, expectedResult: []int{1, 2, 3, 4}},
+		{name: "negative out of range", script: `main() { result = a[-6] }`, negative: true, wantErr: "Index out of bounds -6"},
+
+		// Assignment
+		{name: "set index", script: `main() { a[0] = 10 }`, expected: map[string]interface{}{"a": []int{10, 2, 3, 4, 5}}},
+		{name: "set index expr", script: `main() { i:=1 a[i*2] = a[i]+a[i+1] }`, expected: map[string]interface{}{"a": []int{1, 2, 5, 4, 5}}},
+		{name: "set index augmented", script: `main() { a[4] += 5 }`, expected: map[string]interface{}{"a": []int{1, 2, 3, 4, 10}}},
+		{name: "set index float", script: `main() { a[1] = 7.0 }`, expected: map[string]interface{}{"a": []int{1, 7, 3, 4, 5}}},
+		{name: "set negative", script: `main() { a[-1] = 0 }`, negative: true, expected: map[string]interface{}{"a": []int{1, 2, 3, 4, 0}}},
+		{name: "set out of range", script: `main() { a[5] = 0 }`, wantErr: "Index out of bounds 5"},
+		{name: "set range", script: `main() { a[1:3] = b }`, expected: map[string]interface{}{"a": []int{1, 7, 8, 4, 5}}},
+		{name: "set range slice", script: `main() { a[3:] = a[:2] }`, expected: map[string]interface{}{"a": []int{1, 2, 3, 1, 2}}},
+		{name: "set range length", script: `main() { a[1:4] = b }`, wantErr: "Cannot assign 2 elements to slice of length 3"},
+		{name: "set range string", script: `main() { s[1:3] = b }`, wantErr: "Cannot assign to slice of string"},
+		{name: "set map", script: `main() { m["key"] = "value" }`, expected: map[string]interface{}{"m": map[string]interface{}{"key": "value", "list": []interface{}{"a", "b", "c"}}}},
+		{name: "set nested", script: `main() { m["list"][1] = "x" }`, expected: map[string]interface{}{"m": map[string]interface{}{"list": []interface{}{"a", "x", "c"}}}},
+		{name: "set nested ref", script: `main() { m.list[2] = "y" }`, expected: map[string]interface{}{"m": map[string]interface{}{"list": []interface{}{"a", "b", "y"}}}},
+		{name: "set string index", script: `main() { s[1] = "a" }`, wantErr: "Cannot set index of string"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			var opts []executor.Option
+			if test.negative {
+				opts = append(opts, executor.WithNegativeIndices())
+			}
+
+			exec, err := executor.New(p, opts...)
+			if err != nil {
+				t.Fatal(err)
+				return
+			}
+
+			globals := exec.GlobalScope()
+			for k, v := range map[string]interface{}{
+				"result": nil,
+				"s":      "hello",
+				"a":      []int{1, 2, 3, 4, 5},
+				"b":      []int{7, 8},
+				"arr":    [3]int{10, 20, 30},
+				"m":      map[string]interface{}{"list": []interface{}{"a", "b", "c"}},
+			} {
+				globals.Declare(k)
+				globals.Set(k, v)
+			}
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				// Errors must include the position in the script
+				if !strings.HasPrefix(err.Error(), test.name+":1:") {
+					t.Errorf("error without position %q", err.Error())
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			if test.expected == nil {
+				result, _ := globals.Get("result")
+				if !reflect.DeepEqual(result, test.expectedResult) {
+					t.Errorf("expected %T %v got %T %v", test.expectedResult, test.expectedResult, result, result)
+				}
+			}
+
+			for k, want := range test.expected {
+				got, _ := globals.Get(k)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("expected %s %v got %v", k, want, got)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"github.com/peter-mount/go-script/executor"
 	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
 	"testing"
 )
 
@@ -43,6 +44,34 @@ func Test_ternary(t *testing.T) {
 			},
 			expectedResult: 20,
 		},
+		// ===============
+		// Nested ternaries without parentheses
+		// ===============
+		{
+			name:           "nested false",
+			script:         `main() { result = a==1 ? "one" : a==2 ? "two" : a==3 ? "three" : "other" }`,
+			params:         map[string]interface{}{"a": 3},
+			expectedResult: "three",
+		},
+		{
+			name:           "nested default",
+			script:         `main() { result = a==1 ? "one" : a==2 ? "two" : a==3 ? "three" : "other" }`,
+			params:         map[string]interface{}{"a": 4},
+			expectedResult: "other",
+		},
+		{
+			name:           "nested true",
+			script:         `main() { result = a>0 ? a>5 ? "big" : "small" : "negative" }`,
+			params:         map[string]interface{}{"a": 3},
+			expectedResult: "small",
+		},
+		{
+			// Only the selected expression is evaluated, so this must not fail on the null
+			name:           "unselected not evaluated",
+			script:         `main() { result = isNull(a) ? 0 : a.Value }`,
+			params:         map[string]interface{}{"a": nil},
+			expectedResult: 0,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type typesEmbedded struct {
+	Id int
+}
+
+type typesStruct struct {
+	typesEmbedded
+	Name    string
+	Value   float64
+	private bool
+}
+
+func (t typesStruct) Describe() string { return t.Name }
+
+func (t *typesStruct) SetName(n string) { t.Name = n }
+
+// Test_types tests the type conversion and introspection builtins
+func Test_types(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    interface{}
+		wantErr string
+	}{
+		// Conversion
+		{name: "int float", script: `main() { result = int(2.7) }`, want: 2},
+		{name: "int string", script: `main() { result = int("42") }`, want: 42},
+		{name: "int bool", script: `main() { result = int(true) }`, want: 1},
+		{name: "int invalid", script: `main() { result = int("x") }`, wantErr: "invalid syntax"},
+		{name: "int args", script: `main() { result = int(1, 2) }`, wantErr: "int(v)"},
+		{name: "float int", script: `main() { result = float(2) }`, want: 2.0},
+		{name: "float string", script: `main() { result = float("1.5") }`, want: 1.5},
+		{name: "float division", script: `main() { result = float(1) / 2 }`, want: 0.5},
+		{name: "string int", script: `main() { result = string(42) }`, want: "42"},
+		{name: "string bool", script: `main() { result = string(true) }`, want: "true"},
+		{name: "bool string", script: `main() { result = bool("yes") }`, want: true},
+		{name: "bool int", script: `main() { result = bool(0) }`, want: false},
+		{name: "bool invalid", script: `main() { result = bool("maybe") }`, wantErr: "not a bool"},
+		{name: "bytes string", script: `main() { result = bytes("hi") }`, want: []byte("hi")},
+		{name: "bytes int", script: `main() { result = bytes(12) }`, want: []byte("12")},
+		{name: "bytes bytes", script: `main() { result = bytes(bytes("hi")) }`, want: []byte("hi")},
+
+		// typeof
+		{name: "typeof int", script: `main() { result = typeof(1) }`, want: "int"},
+		{name: "typeof float", script: `main() { result = typeof(1.5) }`, want: "float64"},
+		{name: "typeof string", script: `main() { result = typeof("a") }`, want: "string"},
+		{name: "typeof bool", script: `main() { result = typeof(true) }`, want: "bool"},
+		{name: "typeof null", script: `main() { result = typeof(null) }`, want: "null"},
+		{name: "typeof slice", script: `main() { result = typeof(list) }`, want: "[]int"},
+		{name: "typeof struct", script: `main() { result = typeof(s) }`, want: "tests.typesStruct"},
+		{name: "typeof pointer", script: `main() { result = typeof(p) }`, want: "*tests.typesStruct"},
+		{name: "typeof duration", script: `main() { result = typeof(d) }`, want: "time.Duration"},
+
+		// Predicates
+		{name: "isArray slice", script: `main() { result = isArray(list) }`, want: true},
+		{name: "isArray array", script: `main() { result = isArray(arr) }`, want: true},
+		{name: "isArray string", script: `main() { result = isArray("abc") }`, want: false},
+		{name: "isArray null", script: `main() { result = isArray(null) }`, want: false},
+		{name: "isMap", script: `main() { result = isMap(m) }`, want: true},
+		{name: "isMap struct", script: `main() { result = isMap(s) }`, want: false},
+		{name: "isString", script: `main() { result = isString("abc") }`, want: true},
+		{name: "isString int", script: `main() { result = isString(1) }`, want: false},
+		{name: "isNumber int", script: `main() { result = isNumber(1) }`, want: true},
+		{name: "isNumber float", script: `main() { result = isNumber(1.5) }`, want: true},
+		{name: "isNumber duration", script: `main() { result = isNumber(d) }`, want: true},
+		{name: "isNumber string", script: `main() { result = isNumber("1") }`, want: false},
+		{name: "isNumber null", script: `main() { result = isNumber(null) }`, want: false},
+
+		// Reflection
+		{name: "fields struct", script: `main() { result = fields(s) }`, want: []string{"Id", "Name", "Value"}},
+		{name: "fields pointer", script: `main() { result = fields(p) }`, want: []string{"Id", "Name", "Value"}},
+		{name: "fields map", script: `main() { result = fields(m) }`, want: []string{"a", "b"}},
+		{name: "fields int", script: `main() { result = fields(1) }`, wantErr: "fields(v) requires a struct or map, got int"},
+		{name: "methods struct", script: `main() { result = methods(s) }`, want: []string{"Describe"}},
+		{name: "methods pointer", script: `main() { result = methods(p) }`, want: []string{"Describe", "SetName"}},
+		{name: "methods duration", script: `main() { result = len(methods(d)) > 0 }`, want: true},
+		{name: "methods null", script: `main() { result = methods(null) }`, wantErr: "got null"},
+
+		// Functions declared in the script take precedence over builtins
+		{name: "script fields", script: `fields(v) { return "script" } main() { result = fields(s) }`, want: "script"},
+		{name: "script string", script: `string(v) { return v * 2 } main() { result = string(21) }`, want: 42},
+		{name: "script go", script: `fields(ch) { ch <- "script" } main() { ch := chan(1) go fields(ch) result = <-ch }`, want: "script"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New().ParseString(test.name, test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec, err := executor.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			globals := exec.GlobalScope()
+			for k, v := range map[string]interface{}{
+				"result": nil,
+				"list":   []int{1, 2},
+				"arr":    [2]string{"a", "b"},
+				"m":      map[string]int{"b": 2, "a": 1},
+				"s":      typesStruct{Name: "s"},
+				"p":      &typesStruct{Name: "p"},
+				"d":      time.Second,
+			} {
+				globals.Declare(k)
+				globals.Set(k, v)
+			}
+
+			err = exec.Run()
+			if err != nil {
+				if test.wantErr == "" || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatal(err)
+				}
+				return
+			} else if test.wantErr != "" {
+				t.Fatalf("Expected error %q got none", test.wantErr)
+			}
+
+			result, _ := globals.Get("result")
+			if !reflect.DeepEqual(result, test.want) {
+				t.Errorf("expected %T %v got %T %v", test.want, test.want, result, result)
+			}
+		})
+	}
+}
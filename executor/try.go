@@ -8,43 +8,38 @@ import (
 	"io"
 )
 
-func (e *executor) try(op *script.Try) (err error) {
+func (e *executor) try(op *script.Try) (c Completion) {
 	e.state.NewScope()
 	defer e.state.EndScope()
 
 	// Any panics get resolved to errors
 	defer func() {
 		if err1 := recover(); err1 != nil {
-			err = errors.Errorf(op.Pos, "%v", err1)
+			c = errorCompletion(op.Pos, errors.Errorf(op.Pos, "%v", err1))
 		}
 	}()
 
+	// finally always runs, and if it does not complete normally
+	// then it takes precedence over the body or catch blocks, unless the script is exiting
 	if op.Finally != nil {
 		defer func() {
-			err1 := e.Statement(op.Finally.Statement)
-			if err1 != nil {
-				err = err1
+			if c1 := e.Statement(op.Finally.Statement); !c1.IsNormal() && !c.isExit() {
+				c = c1
 			}
 		}()
 	}
 
-	err = e.tryBody(op)
-	if err != nil {
-		if errors.IsReturn(err) {
-			return err
-		}
-
-		err = errors.Error(op.Pos, err)
+	c = e.tryBody(op).WithPos(op.Pos)
 
-		// If catch then consume the error and pass it to the catch block
-		if op.Catch != nil {
-			// Set var unless "_" - always declared so always local
-			if op.Catch.CatchIdent != "_" {
-				e.state.Declare(op.Catch.CatchIdent)
-				e.state.Set(op.Catch.CatchIdent, err.Error())
-			}
-			err = errors.Error(op.Pos, e.Statement(op.Catch.Statement))
+	// If catch then consume the error and pass it to the catch block.
+	// Note: only errors are caught, break, continue, return & exit pass through.
+	if c.IsError() && !c.isExit() && op.Catch != nil {
+		// Set var unless "_" - always declared so always local
+		if op.Catch.CatchIdent != "_" {
+			e.state.Declare(op.Catch.CatchIdent)
+			e.state.Set(op.Catch.CatchIdent, c.Err.Error())
 		}
+		c = e.Statement(op.Catch.Statement).WithPos(op.Pos)
 	}
 
 	return
@@ -52,7 +47,7 @@ func (e *executor) try(op *script.Try) (err error) {
 
 // tryBody runs any resources then the body.
 // Note resources will be closed before any catch/finally blocks
-func (e *executor) tryBody(op *script.Try) error {
+func (e *executor) tryBody(op *script.Try) Completion {
 	// Scope for resources & body
 	e.state.NewScope()
 	defer e.state.EndScope()
@@ -60,16 +55,13 @@ func (e *executor) tryBody(op *script.Try) error {
 	// The deferable tasks to perform when we exit.
 	//
 	// we defer it here so that this task is always executed even if we don't get to
-	// execute the action - e.g. creating a resource fails whilst building the list
+	// execute the body - e.g. creating a resource fails whilst building the list
 	// means we still close any preceding resource in the resourceList
 	var deferables task.Task
 	defer func() {
 		_ = deferables.Do(nil)
 	}()
 
-	// The action to perform
-	var action task.Task
-
 	// Configure any try-with-resources
 	if op.Init != nil {
 		for _, init := range op.Init.Resources {
@@ -78,7 +70,7 @@ func (e *executor) tryBody(op *script.Try) error {
 				return errors.Error(init.Pos, e.Expression(init))
 			})
 			if err != nil {
-				return err
+				return errorCompletion(init.Pos, err)
 			}
 
 			if ok {
@@ -88,7 +80,7 @@ func (e *executor) tryBody(op *script.Try) error {
 				// -----------------------------------------------------------------
 				if cl, ok := val.(CreateCloser); ok {
 					if err := cl.Create(); err != nil {
-						return errors.Error(init.Pos, err)
+						return errorCompletion(init.Pos, err)
 					}
 				}
 
@@ -103,15 +95,8 @@ func (e *executor) tryBody(op *script.Try) error {
 		}
 	}
 
-	// Finally add the body to the action task
-	if op.Body != nil {
-		action = action.Then(func(_ context.Context) error {
-			return errors.Error(op.Pos, e.Statement(op.Body))
-		})
-	}
-
-	// Now execute the action. No need to add deferables as we have deferred its execution earlier
-	return action.Do(nil)
+	// Now execute the body. No need to add deferables as we have deferred its execution earlier
+	return e.Statement(op.Body).WithPos(op.Pos)
 }
 
 // CreateCloser interface implemented by types that can be used as resources
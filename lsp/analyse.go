@@ -0,0 +1,63 @@
+package lsp
+
+import (
+	"github.com/alecthomas/participle/v2"
+	"github.com/peter-mount/go-script/parser"
+	"os"
+	"path/filepath"
+)
+
+// analyse parses a document, returning the problems found by the parser and initialiser.
+// If it parses then the script is kept for the other requests, otherwise the last one which parsed is used.
+func (s *Server) analyse(d *document) []Diagnostic {
+	p := parser.New()
+	for _, dir := range append(append([]string{}, s.includePath...), filepath.Dir(d.fileName)) {
+		// Directories which do not exist are ignored, as they would be by goscript
+		_ = p.IncludePath(dir)
+	}
+
+	diagnostics := []Diagnostic{}
+
+	sc, err := p.ParseString(d.fileName, d.text)
+	if err != nil {
+		return append(diagnostics, d.diagnostic(err, SeverityError))
+	}
+	d.script = sc
+
+	for _, w := range sc.Warnings {
+		diagnostics = append(diagnostics, d.diagnostic(w, SeverityWarning))
+	}
+	return diagnostics
+}
+
+// diagnostic converts an error to a Diagnostic.
+//
+// Errors from the parser and those created by errors.Errorf carry their position so are shown there.
+// Errors without one, or in an included script, are shown at the start of the document with their position
+// left in the message.
+func (d *document) diagnostic(err error, severity int) Diagnostic {
+	diagnostic := Diagnostic{Severity: severity, Source: "goscript", Message: err.Error()}
+
+	if pe, ok := err.(participle.Error); ok {
+		if pos := pe.Position(); pos.Line > 0 && d.isFile(pos.Filename) {
+			diagnostic.Message = pe.Message()
+			diagnostic.Range = d.identRange(pos)
+		}
+	}
+
+	return diagnostic
+}
+
+// isFile returns true if fileName refers to the document
+func (d *document) isFile(fileName string) bool {
+	if fileName == d.fileName {
+		return true
+	}
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return false
+	}
+	fi1, err1 := os.Stat(abs)
+	fi2, err2 := os.Stat(d.fileName)
+	return err1 == nil && err2 == nil && os.SameFile(fi1, fi2)
+}
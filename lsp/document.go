@@ -0,0 +1,181 @@
+package lsp
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/script"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a script being edited in the client
+type document struct {
+	uri      string
+	fileName string         // Absolute path of the script, used as the file name when parsing
+	text     string         // The current content
+	lines    []int          // Offset of the start of each line in text
+	script   *script.Script // The last version of the script which parsed, nil if it never has
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, fileName: uriToPath(uri)}
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+}
+
+// position converts a byte offset within the text to an LSP Position
+func (d *document) position(offset int) Position {
+	offset = clamp(offset, 0, len(d.text))
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	return Position{Line: line, Character: utf16Len(d.text[d.lines[line]:offset])}
+}
+
+// offset converts an LSP Position to a byte offset within the text
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+
+	offset, end := d.lines[p.Line], len(d.text)
+	if p.Line+1 < len(d.lines) {
+		end = d.lines[p.Line+1] - 1
+	}
+
+	for n := 0; n < p.Character && offset < end; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		offset += size
+		n += utf16RuneLen(r)
+	}
+	return offset
+}
+
+// identRange returns the range of the identifier starting at pos, or of the single character there if it is not one
+func (d *document) identRange(pos lexer.Position) Range {
+	start := clamp(pos.Offset, 0, len(d.text))
+	end := start
+	for end < len(d.text) && isIdentChar(d.text[end]) {
+		end++
+	}
+	if end == start && end < len(d.text) && d.text[end] != '\n' {
+		_, size := utf8.DecodeRuneInString(d.text[end:])
+		end += size
+	}
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// nameRange returns the range of a name within a declaration starting at pos.
+// If after is not empty then the name is looked for after it, e.g. ")" to skip the receiver of a method
+// or "{" for the members of an enum.
+func (d *document) nameRange(pos lexer.Position, name, after string) Range {
+	start := clamp(pos.Offset, 0, len(d.text))
+	if after != "" {
+		if i := strings.Index(d.text[start:], after); i >= 0 {
+			start += i + len(after)
+		}
+	}
+
+	for i := start; i+len(name) <= len(d.text); i++ {
+		if d.text[i:i+len(name)] == name &&
+			(i == 0 || !isIdentChar(d.text[i-1])) &&
+			(i+len(name) == len(d.text) || !isIdentChar(d.text[i+len(name)])) {
+			start = i
+			break
+		}
+	}
+	return Range{Start: d.position(start), End: d.position(start + len(name))}
+}
+
+// identAt returns the identifier at a position, the offset it starts at, and the identifier qualifying it
+// if it follows a '.', e.g. "math" for math.Sqrt.
+// The identifier is empty if the position is not at one.
+func (d *document) identAt(p Position) (string, int, string) {
+	offset := d.offset(p)
+
+	start, end := offset, offset
+	for start > 0 && isIdentChar(d.text[start-1]) {
+		start--
+	}
+	for end < len(d.text) && isIdentChar(d.text[end]) {
+		end++
+	}
+
+	qualifier := ""
+	if start > 0 && d.text[start-1] == '.' {
+		qs := start - 1
+		for qs > 0 && isIdentChar(d.text[qs-1]) {
+			qs--
+		}
+		qualifier = d.text[qs : start-1]
+	}
+
+	return d.text[start:end], start, qualifier
+}
+
+// prefixAt returns the part of the identifier before a position, used when completing it,
+// and the identifier qualifying it as with identAt.
+func (d *document) prefixAt(p Position) (string, string) {
+	offset := d.offset(p)
+	ident, start, qualifier := d.identAt(p)
+	return ident[:offset-start], qualifier
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// uriToPath returns the file name of a file: URI, or the URI itself if it is not one
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the file: URI for a file name
+func pathToURI(fileName string) string {
+	if abs, err := filepath.Abs(fileName); err == nil {
+		fileName = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(fileName)}).String()
+}
@@ -0,0 +1,410 @@
+package lsp
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/packages"
+	"github.com/peter-mount/go-script/script"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// keywords offered by completion
+var keywords = []string{
+	"break", "case", "catch", "continue", "default", "defer", "do", "else", "enum", "false", "finally", "for",
+	"go", "if", "import", "in", "include", "nil", "null", "range", "repeat", "return", "select", "struct",
+	"switch", "true", "try", "type", "until", "while", "yield",
+}
+
+// definition returns the declaration of the function, method, type, enum or enum member at a position
+func (s *Server) definition(d *document, p Position) []Location {
+	name, _, qualifier := d.identAt(p)
+	sc := d.script
+	if name == "" || sc == nil {
+		return nil
+	}
+
+	// Members of packages are not declared in a script
+	if qualifier != "" {
+		if _, ok := lookupPackage(sc, qualifier); ok {
+			return nil
+		}
+	}
+
+	var locations []Location
+
+	if qualifier == "" {
+		for _, f := range sc.FunDec {
+			if f.Receiver == nil && f.Name == name {
+				locations = append(locations, s.location(f.Pos, name, ""))
+			}
+		}
+		for _, t := range sc.TypeDec {
+			if t.Name == name {
+				locations = append(locations, s.location(t.Pos, name, ""))
+			}
+		}
+	}
+
+	for _, e := range sc.EnumDec {
+		switch {
+		case qualifier == "" && e.Name == name:
+			locations = append(locations, s.location(e.Pos, name, ""))
+		case qualifier == "" || qualifier == e.Name:
+			if _, ok := e.Value(name); ok {
+				locations = append(locations, s.location(e.Pos, name, "{"))
+			}
+		}
+	}
+
+	// Without types we cannot tell which method is being called so offer all those with the name
+	if qualifier != "" && len(locations) == 0 {
+		for _, f := range sc.FunDec {
+			if f.Receiver != nil && f.Name == name {
+				locations = append(locations, s.location(f.Pos, name, ")"))
+			}
+		}
+	}
+
+	return locations
+}
+
+// location returns the Location of a name within a declaration, which may be in an included script.
+// If after is not empty then the name is looked for after the first occurrence of it,
+// e.g. ")" to skip the receiver of a method.
+func (s *Server) location(pos lexer.Position, name, after string) Location {
+	d := s.documentFor(pos.Filename)
+	return Location{URI: d.uri, Range: d.nameRange(pos, name, after)}
+}
+
+// documentFor returns the open document for a file, reading it if it is not open in the client
+func (s *Server) documentFor(fileName string) *document {
+	for _, d := range s.documents {
+		if d.fileName == fileName {
+			return d
+		}
+	}
+
+	b, _ := os.ReadFile(fileName)
+	return newDocument(pathToURI(fileName), string(b))
+}
+
+// completion returns the candidates for the identifier being typed at a position.
+//
+// After a '.' these are the members of a package or enum, or the methods and fields of the script's types.
+// Otherwise they are the script's functions, types and enums, the builtin functions, packages and keywords.
+func (s *Server) completion(d *document, p Position) []CompletionItem {
+	prefix, qualifier := d.prefixAt(p)
+
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+	add := func(label string, kind int, detail string) {
+		if strings.HasPrefix(label, prefix) && !seen[label] {
+			seen[label] = true
+			items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+		}
+	}
+
+	sc := d.script
+	if sc == nil {
+		sc = &script.Script{}
+	}
+
+	if qualifier != "" {
+		if pkg, ok := lookupPackage(sc, qualifier); ok {
+			for _, m := range packageMembers(pkg) {
+				add(m.name, m.kind, m.detail)
+			}
+			return items
+		}
+
+		for _, e := range sc.EnumDec {
+			if e.Name == qualifier {
+				for _, m := range e.Members {
+					add(m, CompletionEnum, e.Name)
+				}
+				return items
+			}
+		}
+
+		for _, f := range sc.FunDec {
+			if f.Receiver != nil {
+				add(f.Name, CompletionMethod, funcSignature(f))
+			}
+		}
+		for _, t := range sc.TypeDec {
+			for _, n := range t.Fields {
+				add(n, CompletionField, t.Name+"."+n)
+			}
+		}
+		return items
+	}
+
+	for _, f := range sc.FunDec {
+		if f.Receiver == nil {
+			add(f.Name, CompletionFunction, funcSignature(f))
+		}
+	}
+	for _, t := range sc.TypeDec {
+		add(t.Name, CompletionStruct, typeSignature(t))
+	}
+	for _, e := range sc.EnumDec {
+		add(e.Name, CompletionEnum, enumSignature(e))
+		for _, m := range e.Members {
+			add(m, CompletionEnum, e.Name+"."+m)
+		}
+	}
+	for _, n := range executor.Names() {
+		add(n, CompletionFunction, "builtin")
+	}
+	for _, imp := range sc.Import {
+		for _, ip := range imp.Packages {
+			add(importName(ip), CompletionModule, ip.Name)
+		}
+	}
+	for _, n := range packages.Names() {
+		if !strings.ContainsAny(n, "./") {
+			add(n, CompletionModule, n)
+		}
+	}
+	for _, k := range keywords {
+		add(k, CompletionKeyword, "")
+	}
+
+	return items
+}
+
+// hover describes the identifier at a position
+func (s *Server) hover(d *document, p Position) *Hover {
+	name, start, qualifier := d.identAt(p)
+	if name == "" {
+		return nil
+	}
+
+	text := describe(d.script, name, qualifier)
+	if text == "" {
+		return nil
+	}
+
+	r := Range{Start: d.position(start), End: d.position(start + len(name))}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```goscript\n" + text + "\n```"},
+		Range:    &r,
+	}
+}
+
+// describe returns the signatures of the declarations an identifier can refer to, or "" if it is not known
+func describe(sc *script.Script, name, qualifier string) string {
+	if sc == nil {
+		sc = &script.Script{}
+	}
+
+	var lines []string
+
+	if qualifier != "" {
+		if pkg, ok := lookupPackage(sc, qualifier); ok {
+			for _, m := range packageMembers(pkg) {
+				if m.name == name {
+					if m.kind == CompletionMethod {
+						return "func " + qualifier + "." + name + strings.TrimPrefix(m.detail, "func")
+					}
+					return qualifier + "." + name + " " + m.detail
+				}
+			}
+			return ""
+		}
+
+		for _, e := range sc.EnumDec {
+			if _, ok := e.Value(name); ok && e.Name == qualifier {
+				return e.Name + "." + name
+			}
+		}
+
+		for _, f := range sc.FunDec {
+			if f.Receiver != nil && f.Name == name {
+				lines = append(lines, funcSignature(f))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for _, f := range sc.FunDec {
+		if f.Receiver == nil && f.Name == name {
+			lines = append(lines, funcSignature(f))
+		}
+	}
+	for _, t := range sc.TypeDec {
+		if t.Name == name {
+			lines = append(lines, typeSignature(t))
+		}
+	}
+	for _, e := range sc.EnumDec {
+		if e.Name == name {
+			lines = append(lines, enumSignature(e))
+		} else if _, ok := e.Value(name); ok {
+			lines = append(lines, e.Name+"."+name)
+		}
+	}
+
+	if len(lines) == 0 {
+		if _, ok := executor.Lookup(name); ok {
+			lines = append(lines, "builtin "+name+"()")
+		} else if _, ok := lookupPackage(sc, name); ok {
+			lines = append(lines, "package "+name)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// symbols returns the functions, methods, types and enums declared in a document, excluding those it includes
+func (s *Server) symbols(d *document) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	sc := d.script
+	if sc == nil {
+		return symbols
+	}
+
+	for _, f := range sc.FunDec {
+		if !d.isFile(f.Pos.Filename) {
+			continue
+		}
+
+		symbol := DocumentSymbol{Name: f.Name, Kind: SymbolFunction, Detail: funcSignature(f)}
+		if f.Receiver != nil {
+			symbol.Name = "(" + f.Receiver.Type + ")." + f.Name
+			symbol.Kind = SymbolMethod
+			symbol.SelectionRange = d.nameRange(f.Pos, f.Name, ")")
+		} else {
+			symbol.SelectionRange = d.nameRange(f.Pos, f.Name, "")
+		}
+
+		// EndPos is the token after the body so find the closing brace before it
+		end := symbol.SelectionRange.End
+		if body := f.FunBody; body != nil && body.EndPos.Line > 0 {
+			if i := strings.LastIndex(d.text[:clamp(body.EndPos.Offset, 0, len(d.text))], "}"); i >= f.Pos.Offset {
+				end = d.position(i + 1)
+			}
+		}
+		symbol.Range = Range{Start: d.position(f.Pos.Offset), End: end}
+		symbols = append(symbols, symbol)
+	}
+
+	for _, t := range sc.TypeDec {
+		if d.isFile(t.Pos.Filename) {
+			symbols = append(symbols, d.declSymbol(t.Pos, t.Name, SymbolStruct, typeSignature(t)))
+		}
+	}
+
+	for _, e := range sc.EnumDec {
+		if d.isFile(e.Pos.Filename) {
+			symbols = append(symbols, d.declSymbol(e.Pos, e.Name, SymbolEnum, enumSignature(e)))
+		}
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		a, b := symbols[i].Range.Start, symbols[j].Range.Start
+		return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+	})
+
+	return symbols
+}
+
+// declSymbol returns the symbol for a type or enum declaration, which ends with the first closing brace
+func (d *document) declSymbol(pos lexer.Position, name string, kind int, detail string) DocumentSymbol {
+	start := clamp(pos.Offset, 0, len(d.text))
+	end := start
+	if i := strings.Index(d.text[start:], "}"); i >= 0 {
+		end = start + i + 1
+	}
+
+	return DocumentSymbol{
+		Name:           name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          Range{Start: d.position(start), End: d.position(end)},
+		SelectionRange: d.nameRange(pos, name, ""),
+	}
+}
+
+// member of a package found by reflection
+type member struct {
+	name   string
+	kind   int    // CompletionMethod or CompletionField
+	detail string // The type of the member
+}
+
+// packageMembers returns the exported methods and fields of a registered package in name order
+func packageMembers(pkg interface{}) []member {
+	var members []member
+
+	v := reflect.ValueOf(pkg)
+	t := v.Type()
+	for i := 0; i < t.NumMethod(); i++ {
+		members = append(members, member{name: t.Method(i).Name, kind: CompletionMethod, detail: v.Method(i).Type().String()})
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && !f.Anonymous {
+				members = append(members, member{name: f.Name, kind: CompletionField, detail: f.Type.String()})
+			}
+		}
+	}
+
+	sort.SliceStable(members, func(i, j int) bool { return members[i].name < members[j].name })
+	return members
+}
+
+// lookupPackage returns the package a name refers to, either one imported by the script or a global package
+func lookupPackage(sc *script.Script, name string) (interface{}, bool) {
+	for _, imp := range sc.Import {
+		for _, ip := range imp.Packages {
+			if importName(ip) == name {
+				return packages.Lookup(ip.Name)
+			}
+		}
+	}
+
+	if strings.ContainsAny(name, "./") {
+		return nil, false
+	}
+	return packages.Lookup(name)
+}
+
+// importName returns the name an imported package is referred to by within the script
+func importName(ip *script.ImportPackage) string {
+	if ip.As != "" {
+		return ip.As
+	}
+	return path.Base(ip.Name)
+}
+
+func funcSignature(f *script.FuncDec) string {
+	var sb strings.Builder
+	if f.Receiver != nil {
+		sb.WriteString("(" + f.Receiver.Name + " " + f.Receiver.Type + ") ")
+	}
+	sb.WriteString(f.Name + "(" + strings.Join(f.Parameters, ", ") + ")")
+	return sb.String()
+}
+
+func typeSignature(t *script.TypeDec) string {
+	if len(t.Fields) == 0 {
+		return "type " + t.Name + " struct {}"
+	}
+	return "type " + t.Name + " struct { " + strings.Join(t.Fields, ", ") + " }"
+}
+
+func enumSignature(e *script.EnumDec) string {
+	if len(e.Members) == 0 {
+		return "enum " + e.Name + " {}"
+	}
+	return "enum " + e.Name + " { " + strings.Join(e.Members, ", ") + " }"
+}
@@ -0,0 +1,220 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Server is a Language Server Protocol server for scripts, e.g. for use by an editor via "goscript lsp".
+//
+// It provides diagnostics from the parser, go to definition of functions, methods, types and enums,
+// including those in included scripts, completion, hover and document symbols.
+//
+// Only the builtin functions and packages registered when the server runs are known to it,
+// so the program running it should import the same libraries as the one running the scripts.
+type Server struct {
+	conn        *conn
+	includePath []string             // Directories searched for included scripts
+	documents   map[string]*document // The open documents by URI
+}
+
+// New returns a Server which searches the supplied directories, as well as the directory containing each script,
+// for included scripts.
+func New(includePath ...string) *Server {
+	return &Server{
+		includePath: includePath,
+		documents:   make(map[string]*document),
+	}
+}
+
+// Serve handles messages from r, writing responses to w, until the client sends exit or r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		b, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(&req)
+		rerr, ok := err.(*responseError)
+		if err != nil && !ok {
+			return err
+		}
+
+		// Notifications have no reply
+		if req.ID == nil {
+			continue
+		}
+
+		if err := s.reply(req.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes a request or notification, returning its result.
+// A *responseError is returned to the client, any other error ends the server.
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       TextDocumentSyncOptions{OpenClose: true, Change: 1, Save: true},
+				DefinitionProvider:     true,
+				HoverProvider:          true,
+				DocumentSymbolProvider: true,
+				CompletionProvider:     CompletionOptions{TriggerCharacters: []string{"."}},
+			},
+			ServerInfo: ServerInfo{Name: "goscript"},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		d := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.documents[d.uri] = d
+		return nil, s.publish(d)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		d, ok := s.documents[params.TextDocument.URI]
+		if !ok || len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// We only support full text synchronisation, so the last change is the complete text
+		d.setText(params.ContentChanges[len(params.ContentChanges)-1].Text)
+		return nil, s.publish(d)
+
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		d, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+		if params.Text != nil {
+			d.setText(*params.Text)
+		}
+		// Any included scripts may have changed so check it again
+		return nil, s.publish(d)
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		if d, ok := s.documents[params.TextDocument.URI]; ok {
+			return s.definition(d, params.Position), nil
+		}
+		return nil, nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		if d, ok := s.documents[params.TextDocument.URI]; ok {
+			return s.completion(d, params.Position), nil
+		}
+		return nil, nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		if d, ok := s.documents[params.TextDocument.URI]; ok {
+			if h := s.hover(d, params.Position); h != nil {
+				return h, nil
+			}
+		}
+		return nil, nil
+
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		if d, ok := s.documents[params.TextDocument.URI]; ok {
+			return s.symbols(d), nil
+		}
+		return nil, nil
+	}
+
+	// Unsupported notifications, e.g. initialized or $/cancelRequest, are ignored
+	if req.ID == nil {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not supported", req.Method)}
+}
+
+// publish checks a document and sends its diagnostics to the client
+func (s *Server) publish(d *document) error {
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         d.uri,
+		Diagnostics: s.analyse(d),
+	})
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		raw := json.RawMessage(b)
+		resp.Result = &raw
+	}
+	return s.conn.write(resp)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.conn.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func decode(req *request, v interface{}) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package lsp
+
+import (
+	"encoding/json"
+	_ "github.com/peter-mount/go-script/stdlib"
+	_ "github.com/peter-mount/go-script/stdlib/math"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// client talks to a Server over pipes as an editor would
+type client struct {
+	t      *testing.T
+	conn   *conn
+	nextID int
+	done   chan error
+}
+
+func newClient(t *testing.T, s *Server) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	c := &client{t: t, conn: newConn(outR, inW), done: make(chan error, 1)}
+	go func() {
+		err := s.Serve(inR, outW)
+		_ = outW.Close()
+		c.done <- err
+	}()
+
+	t.Cleanup(func() {
+		c.notify("exit", nil)
+		if err := <-c.done; err != nil {
+			t.Error(err)
+		}
+	})
+	return c
+}
+
+func (c *client) notify(method string, params interface{}) {
+	if err := c.conn.write(notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends a request and decodes its result into v, skipping any notifications received first
+func (c *client) call(method string, params, v interface{}) {
+	c.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.nextID))))
+	if err := c.conn.write(struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Method  string           `json:"method"`
+		Params  interface{}      `json:"params"`
+	}{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	for {
+		var resp response
+		c.read(&resp)
+		if resp.ID == nil {
+			continue
+		}
+		if resp.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, resp.Error.Message)
+		}
+		result := []byte("null")
+		if resp.Result != nil {
+			result = *resp.Result
+		}
+		if err := json.Unmarshal(result, v); err != nil {
+			c.t.Fatal(err)
+		}
+		return
+	}
+}
+
+// diagnostics returns the diagnostics published after a document was opened or changed
+func (c *client) diagnostics() []Diagnostic {
+	var n struct {
+		Method string                   `json:"method"`
+		Params PublishDiagnosticsParams `json:"params"`
+	}
+	c.read(&n)
+	if n.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("expected diagnostics got %q", n.Method)
+	}
+	return n.Params.Diagnostics
+}
+
+func (c *client) read(v interface{}) {
+	b, err := c.conn.read()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func position(uri string, line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+// Test_Server tests the requests an editor makes whilst a script is edited
+func Test_Server(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib.c")
+	if err := os.WriteFile(lib, []byte("// Library\nhelper(a, b) { return a + b }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	uri := pathToURI(filepath.Join(dir, "main.c"))
+	c := newClient(t, New())
+
+	var init InitializeResult
+	c.call("initialize", map[string]interface{}{}, &init)
+	if !init.Capabilities.DefinitionProvider || !init.Capabilities.HoverProvider {
+		t.Errorf("expected definition and hover to be supported got %+v", init.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	// A syntax error is reported where it occurs
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "goscript", Version: 1, Text: "main() {\n    x := 1 +\n}\n"},
+	})
+	diags := c.diagnostics()
+	if len(diags) != 1 || diags[0].Severity != SeverityError || diags[0].Range.Start != (Position{Line: 1, Character: 11}) {
+		t.Fatalf("expected an error at the + got %+v", diags)
+	}
+
+	// Fixing it clears the error, leaving the warning from the initialiser
+	src := `include "lib.c"
+
+type Pair struct { A, B }
+enum Status { Running, Stopped }
+
+(p Pair) Sum() { return helper(p.A, p.B) }
+
+main() {
+    p := Pair(1, math.Sqrt(4))
+    switch Running {
+        case Running: println(p.Sum())
+    }
+}
+`
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		ContentChanges: []struct {
+			Text string `json:"text"`
+		}{{Text: src}},
+	})
+	diags = c.diagnostics()
+	if len(diags) != 1 || diags[0].Severity != SeverityWarning || !strings.Contains(diags[0].Message, "missing Stopped") {
+		t.Fatalf("expected the switch warning got %+v", diags)
+	}
+	if diags[0].Range.Start.Line != 9 {
+		t.Errorf("expected the warning on line 9 got %+v", diags[0].Range)
+	}
+
+	t.Run("definition", func(t *testing.T) {
+		var locations []Location
+
+		// helper is in the included script
+		c.call("textDocument/definition", position(uri, 5, 27), &locations)
+		if len(locations) != 1 || locations[0].URI != pathToURI(lib) || locations[0].Range.Start != (Position{Line: 1}) {
+			t.Errorf("expected helper in lib.c got %+v", locations)
+		}
+
+		// The method name, not the receiver
+		c.call("textDocument/definition", position(uri, 10, 33), &locations)
+		if len(locations) != 1 || locations[0].Range.Start != (Position{Line: 5, Character: 9}) {
+			t.Errorf("expected Sum got %+v", locations)
+		}
+
+		c.call("textDocument/definition", position(uri, 9, 12), &locations)
+		if len(locations) != 1 || locations[0].Range.Start != (Position{Line: 3, Character: 14}) {
+			t.Errorf("expected Running got %+v", locations)
+		}
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		var items []CompletionItem
+		c.call("textDocument/completion", position(uri, 8, 22), &items)
+		if !hasItem(items, "Sqrt") || !hasItem(items, "Pi") || hasItem(items, "helper") {
+			t.Errorf("expected math members got %+v", items)
+		}
+
+		c.call("textDocument/completion", position(uri, 5, 26), &items)
+		if !hasItem(items, "helper") || hasItem(items, "Pair") {
+			t.Errorf("expected helper got %+v", items)
+		}
+
+		c.call("textDocument/completion", position(uri, 10, 25), &items)
+		if !hasItem(items, "println") || !hasItem(items, "print") || hasItem(items, "len") {
+			t.Errorf("expected builtins got %+v", items)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		tests := []struct {
+			line, character int
+			want            string
+		}{
+			{line: 5, character: 25, want: "helper(a, b)"},
+			{line: 10, character: 33, want: "(p Pair) Sum()"},
+			{line: 8, character: 10, want: "type Pair struct { A, B }"},
+			{line: 8, character: 23, want: "func math.Sqrt(float64) float64"},
+			{line: 8, character: 18, want: "package math"},
+			{line: 10, character: 24, want: "builtin println()"},
+		}
+		for _, test := range tests {
+			var h Hover
+			c.call("textDocument/hover", position(uri, test.line, test.character), &h)
+			if !strings.Contains(h.Contents.Value, test.want) {
+				t.Errorf("%d:%d expected %q got %q", test.line, test.character, test.want, h.Contents.Value)
+			}
+		}
+	})
+
+	t.Run("symbols", func(t *testing.T) {
+		var symbols []DocumentSymbol
+		c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+		var names []string
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+		if got, want := strings.Join(names, " "), "Pair Status (Pair).Sum main"; got != want {
+			t.Errorf("expected %q got %q", want, got)
+		}
+		if last := symbols[len(symbols)-1]; last.Range.End != (Position{Line: 12, Character: 1}) {
+			t.Errorf("expected main to end at its closing brace got %+v", last.Range)
+		}
+	})
+}
+
+func hasItem(items []CompletionItem, label string) bool {
+	for _, item := range items {
+		if item.Label == label {
+			return true
+		}
+	}
+	return false
+}
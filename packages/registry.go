@@ -3,6 +3,7 @@ package packages
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 )
 
@@ -57,3 +58,16 @@ func Lookup(name string) (any, bool) {
 
 	return nil, false
 }
+
+// Names returns the names of all registered packages in sorted order
+func Names() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var names []string
+	for n := range packages {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
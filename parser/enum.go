@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+	"strings"
+)
+
+// scanEnums records the members of the enums declared in a script.
+// Duplicate declarations are ignored here, they are reported when the script is executed.
+func (p *initialiser) scanEnums(s *script.Script) {
+	p.enums = make(map[string]*script.EnumDec)
+	p.enumMembers = make(map[string]script.EnumValue)
+
+	for _, en := range s.EnumDec {
+		if _, exists := p.enums[en.Name]; !exists {
+			p.enums[en.Name] = en
+		}
+		for _, m := range en.Members {
+			if _, exists := p.enumMembers[m]; !exists {
+				p.enumMembers[m], _ = en.Value(m)
+			}
+		}
+	}
+}
+
+// checkEnumSwitch adds a warning if all the cases of a switch are members of an enum,
+// but it does not handle every member and has no default.
+func (p *initialiser) checkEnumSwitch(op *script.Switch) {
+	if op.Expression == nil || op.Default != nil {
+		return
+	}
+
+	var en *script.EnumDec
+	handled := make(map[string]bool)
+	for _, c := range op.Case {
+		for _, expr := range c.Expression {
+			v, ok := p.enumMember(expr.Expression)
+			if !ok || (en != nil && v.Enum() != en) {
+				// Not possible to tell which values are being switched over
+				return
+			}
+			en = v.Enum()
+			handled[v.String()] = true
+		}
+	}
+
+	var missing []string
+	for _, m := range en.Members {
+		if !handled[m] {
+			missing = append(missing, m)
+		}
+	}
+
+	if len(missing) > 0 {
+		p.warnings = append(p.warnings, errors.Errorf(op.Pos, "switch over %s is missing %s and has no default", en.Name, strings.Join(missing, ", ")))
+	}
+}
+
+// enumMember returns the enum member an expression refers to, e.g. Running or Status.Running.
+// Returns false if the expression is anything else.
+func (p *initialiser) enumMember(expr *script.Expression) (script.EnumValue, bool) {
+	if expr == nil || expr.Right == nil || expr.Right.Right != nil {
+		return script.EnumValue{}, false
+	}
+
+	primary := expr.Right.Left.Primary()
+	if primary == nil || !isPlainIdent(primary) {
+		return script.EnumValue{}, false
+	}
+
+	switch {
+	case primary.Pointer == nil:
+		v, ok := p.enumMembers[primary.Ident.Ident]
+		return v, ok
+
+	case primary.Pointer.Pointer == nil && isPlainIdent(primary.Pointer) && !primary.IsOptional():
+		if en, ok := p.enums[primary.Ident.Ident]; ok {
+			return en.Value(primary.Pointer.Ident.Ident)
+		}
+	}
+
+	return script.EnumValue{}, false
+}
+
+// isPlainIdent returns true if p is an identifier without any indices or increment/decrement
+func isPlainIdent(p *script.Primary) bool {
+	return p.Ident != nil && p.Ident.PreIncDec == nil && p.Ident.PostIncDec == nil && len(p.Ident.Index) == 0
+}
@@ -0,0 +1,873 @@
+package parser
+
+import (
+	"bytes"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/script"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatIndent is the indentation for each level of nesting
+const formatIndent = "    "
+
+// Format returns the source of a script in its canonical format, with consistent indentation and spacing.
+//
+// The script is regenerated from its syntax tree, so formatting never changes how it runs.
+// As the parser drops comments, the source is also lexed to find them, and each one is written
+// before the statement or declaration that follows it, or at the end of the line if it followed code.
+// A /* block */ comment within an expression is kept before the operand that follows it, but as the
+// expression is written on one line, a // line comment within it is moved to the end of that line.
+// A type or enum with comments among its members is written with one member per line, and a comment
+// following the '}' before an else stays on that line with else starting the next one.
+// Single blank lines between statements are kept, and literals are written as they are in the source.
+//
+// Included scripts are not read, so only the declarations in src are formatted.
+func Format(fileName string, src []byte) ([]byte, error) {
+	s, err := scriptParser.ParseBytes(fileName, src)
+	if err != nil {
+		return nil, err
+	}
+
+	lex, err := commentLexer.LexString(fileName, string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := lexer.ConsumeAll(lex)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formatter{}
+	symbols := commentLexer.Symbols()
+	for _, t := range all {
+		switch t.Type {
+		case symbols["NewLine"], lexer.EOF:
+		case symbols["Comment"], symbols["HashComment"], symbols["SheBang"]:
+			f.tokens = append(f.tokens, t)
+			f.comments = append(f.comments, t)
+		default:
+			f.tokens = append(f.tokens, t)
+		}
+	}
+	f.symbols = symbols
+
+	f.script(s)
+	return f.buf.Bytes(), nil
+}
+
+// formatter writes the source of a script
+type formatter struct {
+	buf      bytes.Buffer
+	indent   int
+	tokens   []lexer.Token              // The tokens in the source, excluding whitespace
+	comments []lexer.Token              // Comments not yet written
+	symbols  map[string]lexer.TokenType // The token types by name
+}
+
+func (f *formatter) write(s ...string) {
+	for _, e := range s {
+		f.buf.WriteString(e)
+	}
+}
+
+func (f *formatter) newline() {
+	f.buf.WriteByte('\n')
+}
+
+func (f *formatter) writeIndent() {
+	f.write(strings.Repeat(formatIndent, f.indent))
+}
+
+// prevToken returns the last token in the source before an offset
+func (f *formatter) prevToken(offset int) (lexer.Token, bool) {
+	i := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= offset })
+	if i == 0 {
+		return lexer.Token{}, false
+	}
+	return f.tokens[i-1], true
+}
+
+// codeFollows returns true if there is code after a comment on the same line
+func (f *formatter) codeFollows(c lexer.Token) bool {
+	i := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset > c.Pos.Offset })
+	for ; i < len(f.tokens) && f.tokens[i].Pos.Line == c.Pos.Line; i++ {
+		if !strings.HasPrefix(f.tokens[i].Value, "/*") {
+			return true
+		}
+	}
+	return false
+}
+
+// endLine returns the line a token ends on, as strings can span lines
+func endLine(t lexer.Token) int {
+	return t.Pos.Line + strings.Count(t.Value, "\n")
+}
+
+// blankLine writes a blank line if there is one before pos in the source,
+// unless it would be the first line of a block
+func (f *formatter) blankLine(pos lexer.Position) {
+	prev, ok := f.prevToken(pos.Offset)
+	if !ok || pos.Line <= endLine(prev)+1 {
+		return
+	}
+
+	b := f.buf.Bytes()
+	if len(b) == 0 || bytes.HasSuffix(b, []byte("\n\n")) ||
+		bytes.HasSuffix(b, []byte("{\n")) || bytes.HasSuffix(b, []byte("(\n")) {
+		return
+	}
+	f.newline()
+}
+
+// flushComments writes any comments before an offset in the source.
+//
+// A comment which followed code on the same line is appended to the line already written,
+// unless it's a block comment with more code after it, otherwise it's written on its own line.
+func (f *formatter) flushComments(offset int) {
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < offset {
+		c := f.comments[0]
+		f.comments = f.comments[1:]
+		value := strings.TrimRight(c.Value, " \t\r")
+
+		if prev, ok := f.prevToken(c.Pos.Offset); ok && endLine(prev) == c.Pos.Line && !f.codeFollows(c) && bytes.HasSuffix(f.buf.Bytes(), []byte("\n")) {
+			f.buf.Truncate(f.buf.Len() - 1)
+			f.write(" ", value)
+			f.newline()
+			continue
+		}
+
+		f.blankLine(c.Pos)
+		f.writeIndent()
+		f.write(value)
+		f.newline()
+	}
+}
+
+// flushTrailing writes any comments before an offset which follow code at the end of the line already written
+func (f *formatter) flushTrailing(offset int) {
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < offset {
+		c := f.comments[0]
+		if prev, ok := f.prevToken(c.Pos.Offset); !ok || endLine(prev) != c.Pos.Line {
+			return
+		}
+		f.flushComments(c.Pos.Offset + 1)
+	}
+}
+
+// startLine starts a new line for a node, writing any comments before it
+func (f *formatter) startLine(pos lexer.Position) {
+	f.flushComments(pos.Offset)
+	f.blankLine(pos)
+	f.writeIndent()
+}
+
+// closingBrace returns the offset of the '}' which ends a node, given the position of the token following it
+func (f *formatter) closingBrace(end lexer.Position) int {
+	return f.lastToken(end, "}")
+}
+
+// lastToken returns the offset of the last token with a value before a position
+func (f *formatter) lastToken(end lexer.Position, value string) int {
+	i := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= end.Offset })
+	for i--; i >= 0; i-- {
+		// Comments and strings include their delimiters so cannot match punctuation or a keyword
+		if f.tokens[i].Value == value {
+			return f.tokens[i].Pos.Offset
+		}
+	}
+	return end.Offset
+}
+
+// nextToken returns the position of the first token with a value at or after an offset
+func (f *formatter) nextToken(offset int, value string) lexer.Position {
+	i := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= offset })
+	for ; i < len(f.tokens); i++ {
+		if f.tokens[i].Value == value {
+			return f.tokens[i].Pos
+		}
+	}
+	return lexer.Position{Offset: offset}
+}
+
+// closeBlock writes the '}' ending a block, after any comments within the block
+func (f *formatter) closeBlock(end lexer.Position) {
+	f.indent++
+	f.flushComments(f.closingBrace(end))
+	f.indent--
+	f.writeIndent()
+	f.write("}")
+}
+
+// inlineComments writes any block comments before an offset on the current line, e.g. the comment
+// in 1 + /* two */ 2, so they stay before the operand that follows them
+func (f *formatter) inlineComments(offset int) {
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < offset && strings.HasPrefix(f.comments[0].Value, "/*") {
+		f.write(f.comments[0].Value, " ")
+		f.comments = f.comments[1:]
+	}
+}
+
+// literal returns a literal as it is written in the source, e.g. a string with its original quotes and escapes.
+// It's the n'th token of the named type at or after pos, or def if there isn't one.
+func (f *formatter) literal(pos lexer.Position, name string, n int, def string) string {
+	typ := f.symbols[name]
+	i := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= pos.Offset })
+	for ; i < len(f.tokens); i++ {
+		if t := f.tokens[i]; t.Type == typ {
+			if n == 0 {
+				return t.Value
+			}
+			n--
+		}
+	}
+	return def
+}
+
+func (f *formatter) script(s *script.Script) {
+	// The declarations are held by type, so sort them back into the order they were declared
+	type topDec struct {
+		pos   lexer.Position
+		write func()
+	}
+	var decs []topDec
+	for _, d := range s.Import {
+		d := d
+		decs = append(decs, topDec{pos: d.Pos, write: func() { f.importDec(d) }})
+	}
+	for _, d := range s.Include {
+		d := d
+		decs = append(decs, topDec{pos: d.Pos, write: func() { f.includeDec(d) }})
+	}
+	for _, d := range s.TypeDec {
+		d := d
+		decs = append(decs, topDec{pos: d.Pos, write: func() { f.typeDec(d) }})
+	}
+	for _, d := range s.EnumDec {
+		d := d
+		decs = append(decs, topDec{pos: d.Pos, write: func() { f.enumDec(d) }})
+	}
+	for _, d := range s.FunDec {
+		d := d
+		decs = append(decs, topDec{pos: d.Pos, write: func() { f.funcDec(d) }})
+	}
+	sort.SliceStable(decs, func(i, j int) bool { return decs[i].pos.Offset < decs[j].pos.Offset })
+
+	for i, d := range decs {
+		// Comments on their own lines belong to the declaration which follows them
+		f.flushTrailing(d.pos.Offset)
+		// Imports and includes can be grouped, everything else is separated by a blank line
+		if i > 0 && !(isImport(s, decs[i-1].pos) && isImport(s, d.pos)) && !bytes.HasSuffix(f.buf.Bytes(), []byte("\n\n")) {
+			f.newline()
+		}
+		f.startLine(d.pos)
+		d.write()
+		f.newline()
+	}
+
+	f.flushComments(int(^uint(0) >> 1))
+}
+
+// isImport returns true if the declaration at pos is an import or include
+func isImport(s *script.Script, pos lexer.Position) bool {
+	for _, d := range s.Import {
+		if d.Pos == pos {
+			return true
+		}
+	}
+	for _, d := range s.Include {
+		if d.Pos == pos {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *formatter) importDec(d *script.Import) {
+	f.write("import (")
+	f.newline()
+	f.indent++
+	for _, p := range d.Packages {
+		f.startLine(p.Pos)
+		if p.As != "" {
+			f.write(p.As, " ")
+		}
+		f.write(f.literal(p.Pos, "String", 0, strconv.Quote(p.Name)))
+		f.newline()
+	}
+	f.indent--
+	f.writeIndent()
+	f.write(")")
+}
+
+func (f *formatter) includeDec(d *script.Include) {
+	var paths []string
+	for i, p := range d.Path {
+		paths = append(paths, f.literal(d.Pos, "String", i, strconv.Quote(p)))
+	}
+	f.write("include ", strings.Join(paths, ", "))
+}
+
+func (f *formatter) typeDec(d *script.TypeDec) {
+	f.write("type ", d.Name, " struct ")
+	f.names(d.Pos, d.Fields)
+}
+
+func (f *formatter) enumDec(d *script.EnumDec) {
+	f.write("enum ", d.Name, " ")
+	f.names(d.Pos, d.Members)
+}
+
+// names writes the fields of a type or members of an enum declared at pos.
+//
+// They are written on one line, unless there are comments within the braces, when each name is
+// written on its own line so the comments stay with the names they are about.
+func (f *formatter) names(pos lexer.Position, n []string) {
+	open := f.nextToken(pos.Offset, "{")
+	end := f.nextToken(open.Offset, "}")
+
+	if len(f.comments) == 0 || f.comments[0].Pos.Offset > end.Offset {
+		if len(n) == 0 {
+			f.write("{}")
+			return
+		}
+		f.write("{ ", strings.Join(n, ", "), " }")
+		return
+	}
+
+	f.write("{")
+	f.newline()
+	f.indent++
+	next := open
+	for _, name := range n {
+		next = f.nextToken(next.Offset+1, name)
+		f.startLine(next)
+		f.write(name, ",")
+		f.newline()
+	}
+	f.flushComments(end.Offset)
+	f.indent--
+	f.writeIndent()
+	f.write("}")
+}
+
+func (f *formatter) funcDec(d *script.FuncDec) {
+	if d.Receiver != nil {
+		f.write("(", d.Receiver.Name, " ", d.Receiver.Type, ") ")
+	}
+	f.write(d.Name, "(", strings.Join(d.Parameters, ", "), ") ")
+	f.statements(d.FunBody)
+}
+
+// statements writes a block, the opening brace being on the current line
+func (f *formatter) statements(s *script.Statements) {
+	empty := true
+	for _, st := range s.Statements {
+		empty = empty && st.Empty
+	}
+	if empty && (len(f.comments) == 0 || f.comments[0].Pos.Offset > f.closingBrace(s.EndPos)) {
+		f.write("{}")
+		return
+	}
+
+	f.write("{")
+	f.newline()
+	f.indent++
+	for _, st := range s.Statements {
+		if st.Empty {
+			continue
+		}
+		f.startLine(st.Pos)
+		f.statement(st)
+		f.newline()
+	}
+	f.indent--
+	f.closeBlock(s.EndPos)
+}
+
+// body writes the body of a statement like if or for, which is usually a block
+func (f *formatter) body(s *script.Statement) {
+	f.write(" ")
+	f.statement(s)
+}
+
+func (f *formatter) statement(s *script.Statement) {
+	switch {
+	case s.Break:
+		f.write("break")
+
+	case s.Continue:
+		f.write("continue")
+
+	case s.Defer != nil:
+		f.write("defer ")
+		f.primary(s.Defer.Call)
+
+	case s.DoWhile != nil:
+		f.write("do")
+		f.body(s.DoWhile.Body)
+		f.write(" while ")
+		f.expression(s.DoWhile.Condition)
+
+	case s.IfStmt != nil:
+		f.ifStatement(s.IfStmt)
+
+	case s.ForRange != nil:
+		f.forRange(s.ForRange)
+
+	case s.For != nil:
+		f.forStatement(s.For)
+
+	case s.Go != nil:
+		f.write("go ")
+		f.primary(s.Go.Call)
+
+	case s.Repeat != nil:
+		f.write("repeat")
+		f.body(s.Repeat.Body)
+		f.write(" until ")
+		f.expression(s.Repeat.Condition)
+
+	case s.Return != nil:
+		f.write("return")
+		if s.Return.Result != nil {
+			f.write(" ")
+			f.expression(s.Return.Result)
+		}
+
+	case s.Select != nil:
+		f.selectStatement(s.Select)
+
+	case s.Switch != nil:
+		f.switchStatement(s.Switch)
+
+	case s.While != nil:
+		f.write("while ")
+		f.expression(s.While.Condition)
+		f.body(s.While.Body)
+
+	case s.Yield != nil:
+		f.write("yield ")
+		f.expression(s.Yield.Result)
+
+	case s.Try != nil:
+		f.try(s.Try)
+
+	case s.Block != nil:
+		f.statements(s.Block)
+
+	case s.Expression != nil:
+		f.expression(s.Expression)
+
+	case s.Empty:
+		f.write(";")
+	}
+}
+
+func (f *formatter) ifStatement(s *script.If) {
+	f.write("if ")
+	f.expression(s.Condition)
+	f.body(s.Body)
+	if s.Else != nil {
+		// A comment after the closing brace stays on that line, so else starts the next line
+		if elseOffset := f.lastToken(s.Else.Pos, "else"); len(f.comments) > 0 && f.comments[0].Pos.Offset < elseOffset {
+			f.newline()
+			f.flushComments(elseOffset)
+			f.writeIndent()
+			f.write("else")
+		} else {
+			f.write(" else")
+		}
+		f.body(s.Else)
+	}
+}
+
+func (f *formatter) forStatement(s *script.For) {
+	f.write("for ")
+	if s.Init != nil {
+		f.expression(s.Init)
+	}
+	f.write(";")
+	if s.Condition != nil {
+		f.write(" ")
+		f.expression(s.Condition)
+	}
+	f.write(";")
+	if s.Increment != nil {
+		f.write(" ")
+		f.expression(s.Increment)
+	}
+	f.body(s.Body)
+}
+
+func (f *formatter) forRange(s *script.ForRange) {
+	f.write("for ")
+	if s.Key != "" {
+		f.write(s.Key)
+		if s.Value != "" {
+			f.write(", ", s.Value)
+		}
+		if s.Declare {
+			f.write(" := ")
+		} else {
+			f.write(" = ")
+		}
+	}
+	f.write("range ")
+	f.expression(s.Expression)
+	f.body(s.Body)
+}
+
+func (f *formatter) switchStatement(s *script.Switch) {
+	f.write("switch ")
+	if s.Expression != nil {
+		f.expression(s.Expression)
+		f.write(" ")
+	}
+	f.write("{")
+	f.newline()
+
+	for _, c := range s.Case {
+		f.startLine(c.Pos)
+		f.write("case ")
+		for i, e := range c.Expression {
+			if i > 0 {
+				f.write(", ")
+			}
+			if e.String != nil {
+				f.write(f.literal(e.Pos, "String", 0, strconv.Quote(*e.String)))
+			} else {
+				f.expression(e.Expression)
+			}
+		}
+		f.write(":")
+		f.caseBody(c.Statement)
+		f.newline()
+	}
+
+	f.caseDefault(s.Default)
+	f.closeBlock(s.EndPos)
+}
+
+func (f *formatter) selectStatement(s *script.Select) {
+	f.write("select {")
+	f.newline()
+
+	for _, c := range s.Case {
+		f.startLine(c.Pos)
+		f.write("case ")
+		f.expression(c.Expression)
+		f.write(":")
+		f.caseBody(c.Statement)
+		f.newline()
+	}
+
+	f.caseDefault(s.Default)
+	f.closeBlock(s.EndPos)
+}
+
+// caseDefault writes the default case of a switch or select
+func (f *formatter) caseDefault(s *script.Statement) {
+	if s != nil {
+		f.startLine(s.Pos)
+		f.write("default:")
+		f.caseBody(s)
+		f.newline()
+	}
+}
+
+// caseBody writes the statement of a case, a block following on the same line
+func (f *formatter) caseBody(s *script.Statement) {
+	switch {
+	case s == nil || s.Empty:
+
+	case s.Block != nil:
+		f.body(s)
+
+	default:
+		f.newline()
+		f.indent++
+		f.startLine(s.Pos)
+		f.statement(s)
+		f.indent--
+	}
+}
+
+func (f *formatter) try(s *script.Try) {
+	f.write("try")
+	if s.Init != nil {
+		f.write(" (")
+		for i, r := range s.Init.Resources {
+			if i > 0 {
+				f.write("; ")
+			}
+			f.expression(r)
+		}
+		f.write(")")
+	}
+	f.body(s.Body)
+
+	if s.Catch != nil {
+		f.write(" catch (", s.Catch.CatchIdent, ")")
+		f.body(s.Catch.Statement)
+	}
+
+	if s.Finally != nil {
+		f.write(" finally")
+		f.body(s.Finally.Statement)
+	}
+}
+
+func (f *formatter) expression(e *script.Expression) {
+	if e.KeyValue != nil {
+		f.inlineComments(e.KeyValue.Pos.Offset)
+		f.write(f.literal(e.KeyValue.Pos, "String", 0, strconv.Quote(e.KeyValue.Key)), ": ")
+		f.expression(e.KeyValue.Value)
+		return
+	}
+	f.assignment(e.Right)
+}
+
+func (f *formatter) assignment(a *script.Assignment) {
+	f.ternary(a.Left)
+	if a.Op == "" {
+		return
+	}
+
+	f.write(" ")
+	if a.AugmentedOp != nil {
+		f.write(*a.AugmentedOp)
+	}
+	if a.Declare {
+		f.write(":")
+	}
+	f.write(a.Op, " ")
+	f.assignment(a.Right)
+}
+
+func (f *formatter) ternary(t *script.Ternary) {
+	f.coalesce(t.Left)
+	if t.True != nil {
+		f.write(" ? ")
+		f.ternary(t.True)
+		f.write(" : ")
+		f.ternary(t.False)
+	}
+}
+
+func (f *formatter) coalesce(e *script.Coalesce) {
+	f.level1(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.coalesce(e.Right)
+	}
+}
+
+func (f *formatter) level1(e *script.Level1) {
+	f.level2(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.level1(e.Right)
+	}
+}
+
+func (f *formatter) level2(e *script.Level2) {
+	f.level3(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.level2(e.Right)
+	}
+}
+
+func (f *formatter) level3(e *script.Level3) {
+	f.level4(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.level3(e.Right)
+	}
+}
+
+func (f *formatter) level4(e *script.Level4) {
+	f.level5(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.level4(e.Right)
+	}
+}
+
+func (f *formatter) level5(e *script.Level5) {
+	f.power(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.level5(e.Right)
+	}
+}
+
+func (f *formatter) power(e *script.Power) {
+	f.unary(e.Left)
+	if e.Right != nil {
+		f.write(" ", e.Op, " ")
+		f.power(e.Right)
+	}
+}
+
+func (f *formatter) unary(u *script.Unary) {
+	if u.Right != nil {
+		f.primary(u.Right)
+		return
+	}
+
+	f.write(u.Op)
+	if (strings.HasSuffix(u.Op, "-") || u.Op == "+") && startsWithSign(u.Left) {
+		f.write(" ")
+	}
+	f.unary(u.Left)
+}
+
+// startsWithSign returns true if a Unary would be written starting with + or -,
+// so following a - or + it's separated by a space to be readable, e.g. - -1 rather than --1
+func startsWithSign(u *script.Unary) bool {
+	if u.Right == nil {
+		return u.Op == "-" || u.Op == "+"
+	}
+	return u.Right.Ident != nil && u.Right.Ident.PreIncDec != nil
+}
+
+func (f *formatter) primary(p *script.Primary) {
+	f.inlineComments(p.Pos.Offset)
+
+	switch {
+	case p.Float != nil:
+		s := strconv.FormatFloat(*p.Float, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s = s + ".0"
+		}
+		f.write(f.literal(p.Pos, "Number", 0, s))
+
+	case p.Integer != nil:
+		f.write(f.literal(p.Pos, "Int", 0, strconv.Itoa(*p.Integer)))
+
+	case p.Imaginary != nil:
+		f.write(f.literal(p.Pos, "Imaginary", 0, strconv.FormatFloat(float64(*p.Imaginary), 'f', -1, 64)+"i"))
+
+	case p.String != nil:
+		f.write(f.literal(p.Pos, "String", 0, strconv.Quote(*p.String)))
+
+	case p.Null:
+		f.write("null")
+
+	case p.Nil:
+		f.write("nil")
+
+	case p.True:
+		f.write("true")
+
+	case p.False:
+		f.write("false")
+
+	case p.SubExpression != nil:
+		f.write("(")
+		f.expression(p.SubExpression)
+		f.write(")")
+
+	case p.CallFunc != nil:
+		f.callFunc(p.CallFunc)
+
+	case p.Ident != nil:
+		f.ident(p.Ident)
+	}
+
+	if p.Pointer != nil {
+		f.write(p.PointOp)
+		f.primary(p.Pointer)
+	}
+}
+
+// callFunc writes a function call.
+// If the arguments were on separate lines, e.g. a map(...) literal, then they are written one per line.
+func (f *formatter) callFunc(cf *script.CallFunc) {
+	f.write(cf.Name, "(")
+	if cf.Parameters == nil {
+		f.write(")")
+		return
+	}
+
+	args := cf.Parameters.Args
+	multiLine := false
+	for _, arg := range args {
+		if arg.Pos.Line != cf.Pos.Line {
+			multiLine = true
+		}
+	}
+
+	if multiLine {
+		f.newline()
+		f.indent++
+	}
+
+	for i, arg := range args {
+		if multiLine {
+			f.startLine(arg.Pos)
+		} else if i > 0 {
+			f.write(" ")
+		}
+
+		f.expression(arg)
+		if i < len(args)-1 {
+			f.write(",")
+		} else if cf.Parameters.Variadic {
+			f.write("...")
+		}
+
+		if multiLine {
+			f.newline()
+		}
+	}
+
+	if multiLine {
+		f.indent--
+		f.writeIndent()
+	}
+	f.write(")")
+}
+
+func (f *formatter) ident(i *script.Ident) {
+	if i.PreIncDec != nil {
+		f.incDec(i.PreIncDec)
+	}
+	f.write(i.Ident)
+	if i.PostIncDec != nil {
+		f.incDec(i.PostIncDec)
+	}
+
+	for _, idx := range i.Index {
+		if idx.Optional {
+			f.write("?.")
+		}
+		f.write("[")
+		if idx.Low != nil {
+			f.expression(idx.Low)
+		}
+		if idx.Slice {
+			f.write(":")
+			if idx.High != nil {
+				f.expression(idx.High)
+			}
+			if idx.Max != nil {
+				f.write(":")
+				f.expression(idx.Max)
+			}
+		}
+		f.write("]")
+	}
+}
+
+func (f *formatter) incDec(i *script.IncDec) {
+	if i.Increment {
+		f.write("++")
+	} else {
+		f.write("--")
+	}
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/peter-mount/go-script/executor"
+	_ "github.com/peter-mount/go-script/stdlib"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_Format tests the layout produced by Format
+func Test_Format(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "spacing",
+			src:  "main(){a:=1+2*b\nif a>1{a++}else{a--}}",
+			want: "main() {\n    a := 1 + 2 * b\n    if a > 1 {\n        a++\n    } else {\n        a--\n    }\n}\n",
+		},
+		{
+			name: "declarations",
+			src:  "import ( \"math\" m2 \"math\" )\ntype P struct { X\n Y }\nenum E { A , B }\n(p P)   Sum( ) { return p.X+p.Y }",
+			want: "import (\n    \"math\"\n    m2 \"math\"\n)\n\ntype P struct { X, Y }\n\nenum E { A, B }\n\n(p P) Sum() {\n    return p.X + p.Y\n}\n",
+		},
+		{
+			name: "comments",
+			src:  "// header\nmain() { // open\n  a := 1 // one\n\n\n  // before b\n  b := 2\n  /* end */ }\n// footer",
+			want: "// header\nmain() { // open\n    a := 1 // one\n\n    // before b\n    b := 2\n    /* end */\n}\n// footer\n",
+		},
+		{
+			name: "switch",
+			src:  `main() { switch a { case 1, "b": x = 1 case 2: { x = 2 } default: x = 3 } }`,
+			want: "main() {\n    switch a {\n    case 1, \"b\":\n        x = 1\n    case 2: {\n        x = 2\n    }\n    default:\n        x = 3\n    }\n}\n",
+		},
+		{
+			name: "loops",
+			src:  `main() { for ;; { break } for i:=0;i<3;i++ {} for k,v:=range m {} for range m {} do a++ while a<3 }`,
+			want: "main() {\n    for ;; {\n        break\n    }\n    for i := 0; i < 3; i++ {}\n    for k, v := range m {}\n    for range m {}\n    do a++ while a < 3\n}\n",
+		},
+		{
+			name: "try",
+			src:  `main() { try (f := os.Open("x")) { f.Read() } catch (e) { throw(e) } finally { println("done") } }`,
+			want: "main() {\n    try (f := os.Open(\"x\")) {\n        f.Read()\n    } catch (e) {\n        throw(e)\n    } finally {\n        println(\"done\")\n    }\n}\n",
+		},
+		{
+			name: "operators",
+			src:  `main() { a := - 1 b := - - 1 d := x ?? y ? 1.0 : -2.5 e := arr?.[1:2][i] ch <- <-in x += 3i }`,
+			want: "main() {\n    a := -1\n    b := - -1\n    d := x ?? y ? 1.0 : -2.5\n    e := arr?.[1:2][i]\n    ch <- <-in\n    x += 3i\n}\n",
+		},
+		{
+			name: "literals",
+			src:  "import ( m `math` )\nmain() { a := \"tab\\t\" b := \"q\\\"\" c := `back\\\\slash` d := map(`k`: 1.50) switch a { case `x`: e := 007 } }",
+			want: "import (\n    m `math`\n)\n\nmain() {\n    a := \"tab\\t\"\n    b := \"q\\\"\"\n    c := `back\\\\slash`\n    d := map(`k`: 1.50)\n    switch a {\n    case `x`:\n        e := 007\n    }\n}\n",
+		},
+		{
+			// Block comments stay within an expression but line comments move to the end of the line
+			name: "expression comments",
+			src:  "main() { a := 1 + /* two */ 2\nb := 1 + // one\n2\nc := f(/* x */ x, // first\ny) }",
+			want: "main() {\n    a := 1 + /* two */ 2\n    b := 1 + 2 // one\n    c := f(\n        /* x */\n        x, // first\n        y\n    )\n}\n",
+		},
+		{
+			name: "else comments",
+			src:  "main() { if a { b() } // c2\nelse { // c3\nc() } }",
+			want: "main() {\n    if a {\n        b()\n    } // c2\n    else { // c3\n        c()\n    }\n}\n",
+		},
+		{
+			// Member comments keep the declaration on multiple lines
+			name: "member comments",
+			src:  "type T struct { A // field a\n B }\nenum E { X, // x\n Y }\n// about U\ntype U struct { A }",
+			want: "type T struct {\n    A, // field a\n    B,\n}\n\nenum E {\n    X, // x\n    Y,\n}\n\n// about U\ntype U struct { A }\n",
+		},
+		{
+			name: "multi-line arguments",
+			src:  "main() { m := map(\n\"a\": 1, // one\n\"b\": map(\"c\": 2)) }",
+			want: "main() {\n    m := map(\n        \"a\": 1, // one\n        \"b\": map(\"c\": 2)\n    )\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Format(test.name, []byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.want, got)
+			}
+		})
+	}
+}
+
+// Test_Format_roundTrip tests that formatting does not change the parsed script, and that
+// formatting an already formatted script does not change it
+func Test_Format_roundTrip(t *testing.T) {
+	sources := map[string]string{
+		"generators":  `count(n) { for i := 0; i < n; i++ { yield i } } main() { for _, v := range count(3) { println(v) } }`,
+		"concurrency": `main() { ch := chan(1) go f(ch) select { case v := <-ch: println(v) case ch <- 1: x = 1 default: x = 2 } }`,
+		"control":     `main() { repeat { a-- } until a < 0 while a < 10 a++ if a { return } else if !b { defer close(c) } }`,
+		"comments":    "type T struct { A // a\n B }\nenum E { X, // x\n Y }\nmain() { if a { b() } // b\n else { c() } }",
+		"expressions": `main() { a := (1 + 2) * 3 ** 2 ** 2 % 5 << 1 | ^b &^ c b = a in arr && !(x != y) || z <= 2 c = f(a)?.b.c[-1] }`,
+	}
+
+	files, err := filepath.Glob("../examples/*.c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range files {
+		b, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[fileName] = string(b)
+	}
+
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			want, err := scriptParser.ParseString(name, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			formatted, err := Format(name, []byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := scriptParser.ParseString(name, string(formatted))
+			if err != nil {
+				t.Fatalf("%v\n%s", err, formatted)
+			}
+
+			clearPositions(reflect.ValueOf(want))
+			clearPositions(reflect.ValueOf(got))
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("formatting changed the script:\n%s", formatted)
+			}
+
+			again, err := Format(name, formatted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(formatted) {
+				t.Errorf("formatting is not stable, expected:\n%s\ngot:\n%s", formatted, again)
+			}
+		})
+	}
+}
+
+// Test_Format_run tests a formatted script returns the same result as the original
+func Test_Format_run(t *testing.T) {
+	src := `fib(n){if n<2{return n}return fib(n-1)+fib(n-2)}
+main(){ // sum
+r:=0 for i:=0;i<10;i++{r+=fib(i)}
+s := "a\\b" result=string(r)+s+string(- -1)}`
+
+	formatted, err := Format("test", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []interface{}
+	for _, s := range []string{src, string(formatted)} {
+		p, err := New().ParseString("test", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exec, err := executor.New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exec.GlobalScope().Declare("result")
+		if err := exec.Run(); err != nil {
+			t.Fatal(err)
+		}
+		result, _ := exec.GlobalScope().Get("result")
+		results = append(results, result)
+	}
+
+	if results[0] != results[1] || !strings.HasPrefix(results[0].(string), "88a\\b") {
+		t.Errorf("expected the same result got %v and %v", results[0], results[1])
+	}
+}
+
+var positionType = reflect.TypeOf(lexer.Position{})
+
+// clearPositions clears the positions within a parsed script so scripts with different layouts can be compared
+func clearPositions(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			clearPositions(v.Elem())
+		}
+
+	case reflect.Struct:
+		if v.Type() == positionType {
+			v.Set(reflect.Zero(positionType))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				clearPositions(v.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			clearPositions(v.Index(i))
+		}
+	}
+}
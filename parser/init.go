@@ -18,12 +18,16 @@ func NewInitialiser() Initialiser {
 }
 
 type initialiser struct {
-	state initState
+	state       initState
+	enums       map[string]*script.EnumDec  // The declared enums
+	enumMembers map[string]script.EnumValue // The members of all enums by name
+	warnings    []error                     // Problems which do not prevent the script from running
 }
 
 // initState holds various state during the init Scan
 type initState struct {
-	inLoop bool // true when parsing within a loop statement
+	inLoop   bool            // true when parsing within a loop statement
+	function *script.FuncDec // the function being parsed
 }
 
 func (p *defaultParser) init(s *script.Script, err error) (*script.Script, error) {
@@ -46,12 +50,45 @@ func (p *defaultParser) init(s *script.Script, err error) (*script.Script, error
 	return s, nil
 }
 
+// initInput initialises an entry in an interactive session.
+// Its declarations are handled as a script, and its statements as the body of a function.
+func (p *defaultParser) initInput(in *script.Input, err error) (*script.Input, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	in.Script = &script.Script{
+		Pos:     in.Pos,
+		Import:  in.Import,
+		Include: in.Include,
+		TypeDec: in.TypeDec,
+		EnumDec: in.EnumDec,
+		FunDec:  in.FunDec,
+	}
+
+	if _, err := p.init(in.Script, nil); err != nil {
+		return nil, err
+	}
+
+	init := NewInitialiser()
+	err = init.Statements(&script.Statements{Pos: in.Pos, Statements: in.Statements})
+	if err != nil {
+		return nil, errors.Error(in.Pos, err)
+	}
+
+	return in, nil
+}
+
 func (p *initialiser) Scan(s *script.Script) error {
+	p.scanEnums(s)
+
 	for _, f := range s.FunDec {
 		if err := p.funcDec(f); err != nil {
 			return errors.Error(s.Pos, err)
 		}
 	}
+
+	s.Warnings = p.warnings
 	return nil
 }
 
@@ -62,14 +99,20 @@ func (p *initialiser) Scan(s *script.Script) error {
 func (p *initialiser) funcDec(op *script.FuncDec) error {
 	old := p.state
 	defer func() { p.state = old }()
-	p.state = initState{}
+	p.state = initState{function: op}
 
 	return errors.Error(op.Pos, p.Statements(op.FunBody))
 }
 
 // Expression initialises an expression.
 func (p *initialiser) Expression(op *script.Expression) error {
-	// Currently do nothing, just here to make the API usable if we ever need to include it
+	p.checkSend(op, false)
+	return nil
+}
+
+// value initialises an expression whose value is used, e.g. the condition of an if statement
+func (p *initialiser) value(op *script.Expression) error {
+	p.checkSend(op, true)
 	return nil
 }
 
@@ -121,7 +164,19 @@ func (p *initialiser) Statement(op *script.Statement) error {
 		err = p.initRepeat(op.Repeat)
 
 	case op.Return != nil:
-		err = p.Expression(op.Return.Result)
+		err = p.value(op.Return.Result)
+
+	case op.Yield != nil:
+		err = p.initYield(op.Yield)
+
+	case op.Defer != nil:
+		err = p.initDefer(op.Defer)
+
+	case op.Go != nil:
+		err = p.initGo(op.Go)
+
+	case op.Select != nil:
+		err = p.initSelect(op.Select)
 
 	case op.Switch != nil:
 		err = p.initSwitch(op.Switch)
@@ -151,7 +206,7 @@ func (p *initialiser) Statement(op *script.Statement) error {
 }
 
 func (p *initialiser) initIf(op *script.If) error {
-	err := p.Expression(op.Condition)
+	err := p.value(op.Condition)
 
 	if err == nil {
 		err = p.Statement(op.Body)
@@ -165,11 +220,13 @@ func (p *initialiser) initIf(op *script.If) error {
 }
 
 func (p *initialiser) initSwitch(op *script.Switch) error {
+	p.checkEnumSwitch(op)
+
 	var err error
 
 	for _, c := range op.Case {
 		for _, ex := range c.Expression {
-			err = p.Expression(ex.Expression)
+			err = p.value(ex.Expression)
 			if err != nil {
 				break
 			}
@@ -189,8 +246,40 @@ func (p *initialiser) initSwitch(op *script.Switch) error {
 	return errors.Error(op.Pos, err)
 }
 
+func (p *initialiser) initSelect(op *script.Select) error {
+	var err error
+
+	for _, c := range op.Case {
+		if recv, _, _ := c.Receive(); recv == nil && c.Send() == nil {
+			return errors.Errorf(c.Pos, "select case must be a channel send or receive")
+		}
+
+		err = p.Expression(c.Expression)
+		if err == nil {
+			err = p.Statement(c.Statement)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = p.Statement(op.Default)
+	}
+
+	return errors.Error(op.Pos, err)
+}
+
+// initGo ensures a go statement calls a function, e.g. go f() or go a.f() and not go a.b
+func (p *initialiser) initGo(op *script.Go) error {
+	if op.Call.Last().CallFunc == nil {
+		return errors.Errorf(op.Pos, "go requires a function call")
+	}
+	return nil
+}
+
 func (p *initialiser) initDoWhile(op *script.DoWhile) error {
-	err := p.Expression(op.Condition)
+	err := p.value(op.Condition)
 	if err == nil {
 		err = p.initLoop(op.Pos, op.Body)
 	}
@@ -198,7 +287,7 @@ func (p *initialiser) initDoWhile(op *script.DoWhile) error {
 }
 
 func (p *initialiser) initRepeat(op *script.Repeat) error {
-	err := p.Expression(op.Condition)
+	err := p.value(op.Condition)
 	if err == nil {
 		err = p.initLoop(op.Pos, op.Body)
 	}
@@ -206,7 +295,7 @@ func (p *initialiser) initRepeat(op *script.Repeat) error {
 }
 
 func (p *initialiser) initWhile(op *script.While) error {
-	err := p.Expression(op.Condition)
+	err := p.value(op.Condition)
 	if err == nil {
 		err = p.initLoop(op.Pos, op.Body)
 	}
@@ -216,7 +305,7 @@ func (p *initialiser) initWhile(op *script.While) error {
 func (p *initialiser) initFor(op *script.For) error {
 	err := p.Expression(op.Init)
 	if err == nil {
-		err = p.Expression(op.Condition)
+		err = p.value(op.Condition)
 	}
 	if err == nil {
 		err = p.Expression(op.Increment)
@@ -228,7 +317,7 @@ func (p *initialiser) initFor(op *script.For) error {
 }
 
 func (p *initialiser) initForRange(op *script.ForRange) error {
-	err := p.Expression(op.Expression)
+	err := p.value(op.Expression)
 	if err == nil {
 		err = p.initLoop(op.Pos, op.Body)
 	}
@@ -251,6 +340,24 @@ func (p *initialiser) initLoop(pos lexer.Position, body *script.Statement) error
 	return errors.Error(pos, err)
 }
 
+// initDefer ensures a defer statement calls a function, e.g. defer f() or defer a.f() and not defer a.b
+func (p *initialiser) initDefer(op *script.Defer) error {
+	if op.Call.Last().CallFunc == nil {
+		return errors.Errorf(op.Pos, "defer requires a function call")
+	}
+	return nil
+}
+
+// initYield marks the function containing yield as a generator
+func (p *initialiser) initYield(op *script.Yield) error {
+	if p.state.function == nil {
+		return errors.Errorf(op.Pos, "yield outside of a function")
+	}
+	p.state.function.Generator = true
+
+	return errors.Error(op.Pos, p.value(op.Result))
+}
+
 func (p *initialiser) initTry(op *script.Try) error {
 
 	// try-resources ensure only assignments and enforce declare mode
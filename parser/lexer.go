@@ -4,10 +4,11 @@ import (
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/peter-mount/go-script/script"
+	"strings"
 )
 
 var (
-	scriptLexer = lexer.MustSimple([]lexer.SimpleRule{
+	scriptRules = []lexer.SimpleRule{
 		{"hashComment", `#.*`},
 		{"sheBang", `#\!.*`},
 		{"comment", `//.*|/\*.*?\*/`},
@@ -15,7 +16,13 @@ var (
 		//{"Ident", `([a-zA-Z_][a-zA-Z0-9_]*)`},
 		{"Ident", `\b([a-zA-Z_][a-zA-Z0-9_]*)\b`},
 		//{"Ident", `\b(([a-zA-Z_][a-zA-Z0-9_]*)(\.([a-zA-Z_][a-zA-Z0-9_]*))*)\b`},
+		// Operator must be before Punct so multi-character operators are a single token.
+		// ++ and -- are not included so that 2--1 is still parsed as 2 - -1
+		// As in go, a<-1 is a channel send and not a < -1
+		{"Operator", `\*\*|<<|>>|<-|&\^|&&|\|\||==|!=|<=|>=|\?\?|\?\.`},
 		{"Punct", `[-,()*/+%{};&!=:<>\|]|\[|\]|\^`},
+		// Imaginary must be before Number and Int, so 4i is a single token
+		{"Imaginary", `[-+]?(\d+\.\d+|\d+)i\b`},
 		{"Number", `[-+]?(\d+\.\d+)`},
 		//{"Number", `[-+]?((\d*)?\.\d+|\d+\.(\d*)?)`},
 		{"Int", `[-+]?\d+`},
@@ -24,11 +31,66 @@ var (
 		{"NewLine", `[\n\r]+`},
 		{"Comma", `,`},
 		{"Query", `\?`},
-	})
+	}
+
+	scriptLexer = lexer.MustSimple(scriptRules)
+
+	// commentLexer is scriptLexer except that comments are returned, so Format can keep them
+	commentLexer = lexer.MustSimple(withComments(scriptRules))
 
 	scriptParser = participle.MustBuild[script.Script](
 		participle.Lexer(scriptLexer),
 		participle.UseLookahead(2),
 		participle.Unquote("String"),
 	)
+
+	// inputParser parses entries in an interactive session
+	inputParser = participle.MustBuild[script.Input](
+		participle.Lexer(scriptLexer),
+		participle.UseLookahead(2),
+		participle.Unquote("String"),
+	)
 )
+
+// withComments returns the rules with the comment rules renamed, as a lexer ignores rules whose name is lower case
+func withComments(rules []lexer.SimpleRule) []lexer.SimpleRule {
+	var r []lexer.SimpleRule
+	for _, rule := range rules {
+		if strings.HasSuffix(rule.Name, "Comment") || rule.Name == "comment" || rule.Name == "sheBang" {
+			rule.Name = strings.ToUpper(rule.Name[:1]) + rule.Name[1:]
+		}
+		r = append(r, rule)
+	}
+	return r
+}
+
+// IsComplete returns false if src ends with brackets or braces still open, or within a raw string,
+// so it cannot be parsed until more input is received,
+// e.g. when a function is entered over several lines in an interactive session.
+func IsComplete(src string) bool {
+	punct := scriptLexer.Symbols()["Punct"]
+
+	lex, err := scriptLexer.LexString("", src)
+	if err != nil {
+		return true
+	}
+
+	tokens, err := lexer.ConsumeAll(lex)
+	if err != nil {
+		// An unterminated raw string is the only token which can span lines
+		return strings.Count(src, "`")%2 == 0
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		if token.Type == punct {
+			switch token.Value {
+			case "{", "(", "[":
+				depth++
+			case "}", ")", "]":
+				depth--
+			}
+		}
+	}
+	return depth <= 0
+}
@@ -16,6 +16,8 @@ type Parser interface {
 	ParseBytes(fileName string, b []byte, opts ...participle.ParseOption) (*script.Script, error)
 	ParseString(fileName, src string, opts ...participle.ParseOption) (*script.Script, error)
 	ParseFile(fileName string, opts ...participle.ParseOption) (*script.Script, error)
+	// ParseInput parses an entry in an interactive session, which can contain both declarations and statements
+	ParseInput(fileName, src string, opts ...participle.ParseOption) (*script.Input, error)
 	IncludePath(s string) error
 	EBNF() string
 }
@@ -71,6 +73,10 @@ func (p *defaultParser) ParseFile(fileName string, opts ...participle.ParseOptio
 	return p.init(p.parseFile(fileName, opts...))
 }
 
+func (p *defaultParser) ParseInput(fileName, src string, opts ...participle.ParseOption) (*script.Input, error) {
+	return p.initInput(inputParser.ParseString(fileName, src, opts...))
+}
+
 func (p *defaultParser) parseFile(fileName string, opts ...participle.ParseOption) (*script.Script, error) {
 	f, err := os.Open(fileName)
 	if err != nil {
@@ -118,11 +124,15 @@ func (p *defaultParser) include(s *script.Script, pos lexer.Position, paths []st
 
 	// Add any function definitions but do not include main()
 	for _, td := range s1.FunDec {
-		if td.Name != "main" {
+		if td.Name != "main" || td.Receiver != nil {
 			s.FunDec = append(s.FunDec, td)
 		}
 	}
 
+	// Add any type and enum declarations
+	s.TypeDec = append(s.TypeDec, s1.TypeDec...)
+	s.EnumDec = append(s.EnumDec, s1.EnumDec...)
+
 	// Add any imports for this script
 	s.Import = append(s.Import, s1.Import...)
 
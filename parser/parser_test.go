@@ -91,3 +91,28 @@ func Test_parser(t *testing.T) {
 	}
 
 }
+
+// Test_IsComplete tests detecting input which needs more lines before it can be parsed
+func Test_IsComplete(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{src: `x := 1`, want: true},
+		{src: `f(a) {`, want: false},
+		{src: "f(a) {\n return a\n}", want: true},
+		{src: `print(1,`, want: false},
+		{src: `a[1`, want: false},
+		{src: `a := "{"`, want: true},
+		{src: `x := 1 // {`, want: true},
+		{src: "a := `raw", want: false},
+		{src: "a := `raw\nstring`", want: true},
+		{src: `}`, want: true},
+	}
+
+	for _, test := range tests {
+		if got := IsComplete(test.src); got != test.want {
+			t.Errorf("%q expected %v got %v", test.src, test.want, got)
+		}
+	}
+}
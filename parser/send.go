@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+)
+
+// checkSend adds a warning for a channel send which is probably meant to be a comparison.
+//
+// Before channels were added a<-1 was the comparison a < -1, but is now a send of 1 to the channel a.
+// So warn if a send is used as a value, e.g. if a<-1 {}, or is to a value which cannot be a channel.
+func (p *initialiser) checkSend(op *script.Expression, value bool) {
+	if op == nil {
+		return
+	}
+
+	// The right hand side of an assignment is a value, e.g. b = a<-1
+	for a := op.Right; a != nil; a, value = a.Right, true {
+		if !a.IsSend() {
+			continue
+		}
+
+		switch {
+		case value:
+			p.warnings = append(p.warnings, errors.Errorf(a.Pos, "channel send has no value, for a comparison write a < -1"))
+
+		case !canBeChannel(a.Left):
+			p.warnings = append(p.warnings, errors.Errorf(a.Pos, "send to a value which cannot be a channel, for a comparison write a < -1"))
+		}
+	}
+}
+
+// canBeChannel returns false if the target of a send is a literal, e.g. 1<-2, or the result of an operator
+// other than a receive, e.g. a+b<-1, neither of which can be a channel.
+func canBeChannel(t *script.Ternary) bool {
+	if t.True != nil {
+		// Either result could be a channel
+		return true
+	}
+
+	unary := t.Unary()
+	switch {
+	case unary == nil:
+		return false
+
+	case unary.Op == "<-":
+		// Received from a channel of channels
+		return true
+
+	case unary.Op != "":
+		return false
+	}
+
+	primary := unary.Right
+	return primary.SubExpression != nil || primary.CallFunc != nil || primary.Ident != nil
+}
@@ -14,13 +14,20 @@ type For struct {
 }
 
 // ForRange emulates go's "for i,v:=range expr {...}"
+//
+// Both variables are optional, e.g. "for range expr", "for v := range expr" or "for k, v = range expr".
+// As in go, a single variable is the first value of each iteration, i.e. the index of a slice or string,
+// the key of a map, or the value received from a channel, iterator or iter.Seq.
+//
+// The lookahead is required as without it "for i := 0; ..." would have consumed too many tokens
+// for the parser to then try For.
 type ForRange struct {
 	Pos lexer.Position
 
-	Key        string      `parser:"'for' @Ident ','"` // index in range, _ to ignore
-	Value      string      `parser:"@Ident"`           // value in range, _ to ignore
-	Declare    bool        `parser:"@(':')?"`          // := to declare in local scope
-	Expression *Expression `parser:" '=' 'range' @@"`
+	Key        string      `parser:"'for' (?= ( Ident ( ',' Ident )? ':'? '=' )? 'range' ) ( @Ident"` // index in range, _ to ignore
+	Value      string      `parser:"  ( ',' @Ident )?"`                                               // value in range, _ to ignore
+	Declare    bool        `parser:"  @(':')? '=' )?"`                                                // := to declare in local scope
+	Expression *Expression `parser:"'range' @@"`
 	Body       *Statement  `parser:"@@"`
 }
 
@@ -54,7 +61,8 @@ type If struct {
 }
 
 type Switch struct {
-	Pos lexer.Position
+	Pos    lexer.Position
+	EndPos lexer.Position // Position of the token following the closing brace
 
 	Expression *Expression   `parser:"'switch' (@@)? '{'"`
 	Case       []*SwitchCase `parser:"(@@)+ "`
@@ -68,6 +76,66 @@ type SwitchCase struct {
 	Statement  *Statement              `parser:"@@"`
 }
 
+// Select emulates go's select statement, waiting until one of the channel operations
+// in its cases can proceed, e.g.
+//
+//	select {
+//	  case v = <-in: out <- v
+//	  case <-done: return
+//	  default: idle()
+//	}
+//
+// Unlike switch, each case must be a send, ch <- v, or a receive, <-ch, optionally assigned
+// to a variable with either = or :=
+type Select struct {
+	Pos    lexer.Position
+	EndPos lexer.Position // Position of the token following the closing brace
+
+	Case    []*SelectCase `parser:"'select' '{' @@*"`
+	Default *Statement    `parser:"('default' ':' @@ )? '}'"`
+}
+
+type SelectCase struct {
+	Pos lexer.Position
+
+	Expression *Expression `parser:"'case' @@ ':'"`
+	Statement  *Statement  `parser:"@@?"` // Optional, e.g. case <-done:
+}
+
+// Send returns the Assignment if this case sends a value to a channel, e.g. case ch <- v:
+func (c *SelectCase) Send() *Assignment {
+	if a := c.Expression.Right; a.IsSend() && a.AugmentedOp == nil && !a.Declare {
+		return a
+	}
+	return nil
+}
+
+// Receive returns the Unary if this case receives a value from a channel, e.g. case <-ch:
+//
+// If the value is assigned, e.g. case v = <-ch: or case v := <-ch:, then name is the variable
+// and declare is true if it is declared within the case.
+func (c *SelectCase) Receive() (recv *Unary, name string, declare bool) {
+	a := c.Expression.Right
+	if recv = a.Receive(); recv != nil {
+		return recv, "", false
+	}
+
+	if a == nil || a.Op != "=" || a.AugmentedOp != nil {
+		return nil, "", false
+	}
+
+	target := a.Left.Primary()
+	if target == nil || target.Ident == nil || target.Pointer != nil ||
+		target.Ident.PreIncDec != nil || target.Ident.PostIncDec != nil || target.Ident.IsIndexed() {
+		return nil, "", false
+	}
+
+	if recv = a.Right.Receive(); recv == nil {
+		return nil, "", false
+	}
+	return recv, target.Ident.Ident, a.Declare
+}
+
 type SwitchCaseExpression struct {
 	Pos lexer.Position
 
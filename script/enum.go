@@ -0,0 +1,76 @@
+package script
+
+import (
+	"fmt"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// EnumDec declares an enumeration, e.g. enum Status { Running, Cancelled, Delayed }
+//
+// Each member is a constant EnumValue which can be referred to by its name, e.g. Running,
+// or qualified with the name of the enum, e.g. Status.Running.
+// Members can be separated by commas or new lines.
+type EnumDec struct {
+	Pos lexer.Position
+
+	Name    string   `parser:"'enum' @Ident"`
+	Members []string `parser:"'{' ( @Ident ( ','? @Ident )* ','? )? '}'"`
+}
+
+// Value returns the member with the given name
+func (e *EnumDec) Value(name string) (EnumValue, bool) {
+	for i, m := range e.Members {
+		if m == name {
+			return EnumValue{enum: e, ordinal: i}, true
+		}
+	}
+	return EnumValue{}, false
+}
+
+// Values returns all members in the order they are declared
+func (e *EnumDec) Values() []EnumValue {
+	r := make([]EnumValue, len(e.Members))
+	for i := range e.Members {
+		r[i] = EnumValue{enum: e, ordinal: i}
+	}
+	return r
+}
+
+// Parse returns the member with the given name, e.g. to convert a string read from a file
+func (e *EnumDec) Parse(name string) (EnumValue, error) {
+	if v, ok := e.Value(name); ok {
+		return v, nil
+	}
+	return EnumValue{}, fmt.Errorf("%q is not a member of %s", name, e.Name)
+}
+
+// EnumValue is a member of an enum declared with EnumDec.
+//
+// It is only equal to the same member of the same enum, or a string containing its name.
+type EnumValue struct {
+	enum    *EnumDec
+	ordinal int
+}
+
+// Enum returns the EnumDec this value is a member of
+func (v EnumValue) Enum() *EnumDec {
+	return v.enum
+}
+
+// String returns the name of the member
+func (v EnumValue) String() string {
+	if v.enum == nil {
+		return ""
+	}
+	return v.enum.Members[v.ordinal]
+}
+
+// Ordinal returns the position of the member within the enum's declaration, starting at 0
+func (v EnumValue) Ordinal() int {
+	return v.ordinal
+}
+
+// Equal returns true if b is the same member of the same enum
+func (v EnumValue) Equal(b EnumValue) bool {
+	return v == b
+}
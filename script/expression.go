@@ -2,12 +2,17 @@ package script
 
 import (
 	"github.com/alecthomas/participle/v2/lexer"
+	"strconv"
+	"strings"
 )
 
 type Expression struct {
 	Pos lexer.Position
 
-	Right *Assignment `parser:"@@"`
+	// KeyValue is only valid here, so it cannot be confused with
+	// the ':' in a Ternary, e.g. a ? "b" : "c"
+	KeyValue *KeyValue   `parser:"( @@"`
+	Right    *Assignment `parser:"| @@ )"`
 }
 
 type Assignment struct {
@@ -16,64 +21,102 @@ type Assignment struct {
 	Left        *Ternary    `parser:"@@"`                        // Expression or ident/reference to value to set
 	AugmentedOp *string     `parser:"( @('+'|'-'|'*'|'/'|'%')?"` // Operation to perform on the result
 	Declare     bool        `parser:"  @(':')?"`                 // := to declare in local scope, unset to use outer if already defined
-	Op          string      `parser:"  @'='"`                    // assign value
+	Op          string      `parser:"  @('=' | '<-')"`           // assign value, or send it to the channel Left
 	Right       *Assignment `parser:"  @@ )?"`                   // Expression to define value
 }
 
+// Ternary is the conditional operator "condition ? true : false".
+//
+// Both True and False are themselves a Ternary, so they can be nested without parentheses,
+// e.g. "a ? b : c ? d : e" is the same as "a ? b : (c ? d : e)"
 type Ternary struct {
 	Pos lexer.Position
 
-	Left  *Level1 `parser:"@@"`
-	True  *Level1 `parser:"( '?' @@"`
-	False *Level1 `parser:"  ':' @@ )?"`
+	Left  *Coalesce `parser:"@@"`
+	True  *Ternary  `parser:"( '?' @@"`
+	False *Ternary  `parser:"  ':' @@ )?"`
 }
 
+// Coalesce is the null-coalescing operator "a ?? b" which returns the first value which is not null.
+//
+// The right hand side is only evaluated if the left hand side is null.
+type Coalesce struct {
+	Pos lexer.Position
+
+	Left  *Level1   `parser:"@@"`
+	Op    string    `parser:"[ @'??'"`
+	Right *Coalesce `parser:"  @@ ]"`
+}
+
+// Level1 is logical or, the lowest precedence binary operator
 type Level1 struct {
 	Pos lexer.Position
 
 	Left  *Level2 `parser:"@@"`
-	Op    string  `parser:"[ @( '|' '|' )"`
+	Op    string  `parser:"[ @'||'"`
 	Right *Level1 `parser:"  @@ ]"`
 }
 
+// Level2 is logical and
 type Level2 struct {
 	Pos lexer.Position
 
 	Left  *Level3 `parser:"@@"`
-	Op    string  `parser:"[ @( '&' '&' )"`
+	Op    string  `parser:"[ @'&&'"`
 	Right *Level2 `parser:"  @@ ]"`
 }
 
+// Level3 are the comparison operators which, like go, all have the same precedence.
+// This includes "a in b" which tests if a is an element of a slice, key of a map or a substring.
 type Level3 struct {
 	Pos lexer.Position
 
 	Left  *Level4 `parser:"@@"`
-	Op    string  `parser:"[ @( '=' '=' | '!' '=' | '<' '=' | '<' | '>' '=' | '>' )"`
+	Op    string  `parser:"[ @( '==' | '!=' | '<=' | '<' | '>=' | '>' | 'in' )"`
 	Right *Level3 `parser:"  @@ ]"`
 }
 
+// Level4 are the additive operators
 type Level4 struct {
 	Pos lexer.Position
 
 	Left  *Level5 `parser:"@@"`
-	Op    string  `parser:"[ @( '+' | '-' )"`
+	Op    string  `parser:"[ @( '+' | '-' | '|' | '^' )"`
 	Right *Level4 `parser:"  @@ ]"`
 }
 
+// Level5 are the multiplicative operators, the highest precedence binary operators
 type Level5 struct {
 	Pos lexer.Position
 
-	Left  *Unary  `parser:"@@"`
-	Op    string  `parser:"[ @( '*' '*' | '*' | '/' | '%' | '<' '<' | '>' '>' | '&' '^' | '&' | '|' | '^')"`
+	Left  *Power  `parser:"@@"`
+	Op    string  `parser:"[ @( '*' | '/' | '%' | '<<' | '>>' | '&^' | '&' )"`
 	Right *Level5 `parser:"  @@ ]"`
 }
 
+// Power is exponentiation, a ** b.
+//
+// This binds tighter than the multiplicative operators and is right associative,
+// so 2 ** 3 ** 2 is 2 ** (3 ** 2). As in go, the unary operators bind tighter still,
+// so -2 ** 2 is (-2) ** 2.
+type Power struct {
+	Pos lexer.Position
+
+	Left  *Unary `parser:"@@"`
+	Op    string `parser:"[ @'**'"`
+	Right *Power `parser:"  @@ ]"`
+}
+
+// Unary operators, which can be applied to any operand, e.g. !(a==b) or -^a.
+// This includes <-ch which receives a value from a channel.
+//
+// Primary is tried first so that ++a and --a are parsed as increment/decrement and not +(+a) or -(-a).
 type Unary struct {
 	Pos lexer.Position
 
-	Op    string   `parser:"  ( @( '!' | '-' )"`
-	Left  *Primary `parser:"    @@ )"`
-	Right *Primary `parser:"| @@"`
+	Right *Primary `parser:"  @@"`
+	Op    string   `parser:"| ( @( '!' | '-' | '+' | '^' | '<-' )"`
+	Left  *Unary   `parser:"    @@ )"`
 }
 
 type Primary struct {
@@ -81,7 +124,7 @@ type Primary struct {
 
 	Float         *float64    `parser:"( @Number"`
 	Integer       *int        `parser:"  | @Int"`
-	KeyValue      *KeyValue   `parser:"  | @@"`
+	Imaginary     *Imaginary  `parser:"  | @Imaginary"`
 	String        *string     `parser:"  | @String"`
 	Null          bool        `parser:"  | @'null'"`
 	Nil           bool        `parser:"  | @'nil'"`
@@ -90,17 +133,51 @@ type Primary struct {
 	SubExpression *Expression `parser:"  | '(' @@ ')' "`
 	CallFunc      *CallFunc   `parser:"  | ( @@"`
 	Ident         *Ident      `parser:"    | @@ "`
-	PointOp       string      `parser:"    ) [ @Period"`
+	PointOp       string      `parser:"    ) [ @( Period | '?.' )"`
 	Pointer       *Primary    `parser:"      @@] )"`
 }
 
+// Imaginary is an imaginary number literal, e.g. 4i or 1.5i.
+// A complex number is written as the sum of a real and imaginary number, e.g. 3+4i
+type Imaginary float64
+
+// Capture parses the literal, which has the "i" suffix
+func (i *Imaginary) Capture(values []string) error {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(values[0], "i"), 64)
+	if err != nil {
+		return err
+	}
+	*i = Imaginary(f)
+	return nil
+}
+
+// Complex returns the imaginary number as a complex128
+func (i Imaginary) Complex() complex128 {
+	return complex(0, float64(i))
+}
+
 type Ident struct {
 	Pos lexer.Position
 
-	PreIncDec  *IncDec       `parser:"(@@?)"`
-	Ident      string        `parser:"@Ident"`
-	PostIncDec *IncDec       `parser:"(@@?)"`
-	Index      []*Expression `parser:"[ ('[' @@ ']')+ ]"`
+	PreIncDec  *IncDec  `parser:"(@@?)"`
+	Ident      string   `parser:"@Ident"`
+	PostIncDec *IncDec  `parser:"(@@?)"`
+	Index      []*Index `parser:"[ @@+ ]"`
+}
+
+// Index is an index into an array, slice or map, e.g. [i], or a slice expression,
+// e.g. [low:high] or [low:high:max] as in go.
+//
+// If Optional, e.g. ?.[i], then the result is null rather than an error if the value
+// being indexed is null or the index is not present.
+type Index struct {
+	Pos lexer.Position
+
+	Optional bool        `parser:"@'?.'? '['"`
+	Low      *Expression `parser:"@@?"`
+	Slice    bool        `parser:"( @':'"`
+	High     *Expression `parser:"  @@?"`
+	Max      *Expression `parser:"  ( ':' @@ )? )? ']'"`
 }
 
 type IncDec struct {
@@ -110,6 +187,36 @@ type IncDec struct {
 	Increment bool `parser:"  | @('+' '+') )"`
 }
 
+// IsOptional returns true if the PointOp is the optional chaining operator "?."
+func (p *Primary) IsOptional() bool {
+	return p != nil && p.PointOp == "?."
+}
+
+// IsOptionalChain returns true if any part of this Primary uses optional chaining
+func (p *Primary) IsOptionalChain() bool {
+	for ; p != nil; p = p.Pointer {
+		if p.IsOptional() {
+			return true
+		}
+		if p.Ident != nil {
+			for _, idx := range p.Ident.Index {
+				if idx.Optional {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Last returns the last Primary in a chain, e.g. c in a.b.c
+func (p *Primary) Last() *Primary {
+	for p.Pointer != nil {
+		p = p.Pointer
+	}
+	return p
+}
+
 // IsPreIncDec returns true if --ident or ++ident but no array indices
 func (i *Ident) IsPreIncDec() bool {
 	return i != nil && i.PreIncDec != nil && len(i.Index) == 0
@@ -132,3 +239,78 @@ type KeyValue struct {
 	Key   string      `parser:"@String"`
 	Value *Expression `parser:"':' @@"`
 }
+
+// Unary returns the Unary if this Ternary consists of just a single Unary with no binary operators,
+// e.g. <-ch. Returns nil if it is not a single Unary.
+func (t *Ternary) Unary() *Unary {
+	if t == nil || t.True != nil {
+		return nil
+	}
+
+	c := t.Left
+	if c == nil || c.Right != nil {
+		return nil
+	}
+
+	l1 := c.Left
+	if l1 == nil || l1.Right != nil {
+		return nil
+	}
+
+	l2 := l1.Left
+	if l2 == nil || l2.Right != nil {
+		return nil
+	}
+
+	l3 := l2.Left
+	if l3 == nil || l3.Right != nil {
+		return nil
+	}
+
+	l4 := l3.Left
+	if l4 == nil || l4.Right != nil {
+		return nil
+	}
+
+	l5 := l4.Left
+	if l5 == nil || l5.Right != nil {
+		return nil
+	}
+
+	power := l5.Left
+	if power == nil || power.Right != nil {
+		return nil
+	}
+
+	return power.Left
+}
+
+// Primary returns the Primary if this Ternary consists of just a single Primary with no operators,
+// e.g. the target of an assignment. Returns nil if it is not a single Primary.
+func (t *Ternary) Primary() *Primary {
+	unary := t.Unary()
+	if unary == nil {
+		return nil
+	}
+
+	return unary.Right
+}
+
+// IsSend returns true if this is a channel send, e.g. ch <- v
+func (a *Assignment) IsSend() bool {
+	return a != nil && a.Op == "<-"
+}
+
+// Receive returns the Unary if this Assignment is just a channel receive, e.g. <-ch.
+// Returns nil if it is anything else.
+func (a *Assignment) Receive() *Unary {
+	if a == nil || a.Op != "" {
+		return nil
+	}
+
+	unary := a.Left.Unary()
+	if unary == nil || unary.Op != "<-" {
+		return nil
+	}
+	return unary
+}
@@ -7,9 +7,19 @@ import (
 type FuncDec struct {
 	Pos lexer.Position
 
+	Receiver   *Receiver   `parser:"@@?"`
 	Name       string      `parser:"@Ident"`
 	Parameters []string    `parser:"'(' (@Ident (',' @Ident)*)? ')'"`
 	FunBody    *Statements `parser:"@@"`
+	Generator  bool        // Set by the parser if the function contains yield
+}
+
+// Receiver declares a function as a method of a type declared with TypeDec, e.g. (p Point) Dist() {...}
+type Receiver struct {
+	Pos lexer.Position
+
+	Name string `parser:"'(' @Ident"`
+	Type string `parser:"@Ident ')'"`
 }
 
 type Return struct {
@@ -18,6 +28,39 @@ type Return struct {
 	Result *Expression `parser:"'return' @@?"`
 }
 
+// Yield produces the next value of a generator, e.g. yield i
+//
+// A function containing yield is a generator. Calling it returns an iterator and the function only runs
+// as values are requested from that iterator, pausing at each yield until the next value is required.
+type Yield struct {
+	Pos lexer.Position
+
+	Result *Expression `parser:"'yield' @@"`
+}
+
+// Defer defers a function call until the function it is in returns, e.g. defer f.Close()
+//
+// As in go, the function and its arguments are evaluated when the defer statement is run,
+// and deferred calls are made in the reverse order they were deferred, whether the function returns
+// normally, fails with an error or panics.
+// Values returned by deferred calls are ignored. A deferred call can instead use recover() to clear an error
+// and setReturn(value) to replace the function's result.
+type Defer struct {
+	Pos lexer.Position
+
+	Call *Primary `parser:"'defer' @@"`
+}
+
+// Go calls a function in a new goroutine, e.g. go worker(ch, 1)
+//
+// The arguments are evaluated before the goroutine starts. The function runs with its own
+// calculator and scope chain, so only global variables are shared with the caller.
+type Go struct {
+	Pos lexer.Position
+
+	Call *Primary `parser:"'go' @@"`
+}
+
 type CallFunc struct {
 	Pos lexer.Position
 
@@ -9,8 +9,11 @@ type Script struct {
 
 	Import   []*Import  `parser:"( @@"`
 	Include  []*Include `parser:"| @@"`
+	TypeDec  []*TypeDec `parser:"| @@"`
+	EnumDec  []*EnumDec `parser:"| @@"`
 	FunDec   []*FuncDec `parser:"| @@)+"`
 	Includes map[string]interface{}
+	Warnings []error // Problems found when the script was parsed which do not prevent it from running
 }
 
 type Import struct {
@@ -31,3 +34,55 @@ type Include struct {
 
 	Path []string `parser:"'include' ( '(' @String+ ')' | ( @String (',' @String)* ) )"`
 }
+
+// TypeDec declares a record type with named fields, e.g. type Point struct { X, Y }
+//
+// Fields are untyped so can hold any value. They can be separated by commas or new lines.
+type TypeDec struct {
+	Pos lexer.Position
+
+	Name   string   `parser:"'type' @Ident 'struct'"`
+	Fields []string `parser:"'{' ( @Ident ( ','? @Ident )* ','? )? '}'"`
+}
+
+// HasField returns true if the type has the named field
+func (t *TypeDec) HasField(name string) bool {
+	for _, f := range t.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Input is a single entry in an interactive session, e.g. the goscript REPL.
+//
+// Unlike a Script it can contain statements, which are run as soon as the entry is complete,
+// as well as declarations, which are added to those already made in the session.
+//
+// The lookaheads prevent a function call such as f(x) or a statement like if (a) {...}
+// from being parsed as a function declaration.
+type Input struct {
+	Pos lexer.Position
+
+	Import     []*Import    `parser:"( @@"`
+	Include    []*Include   `parser:"| @@"`
+	TypeDec    []*TypeDec   `parser:"| @@"`
+	EnumDec    []*EnumDec   `parser:"| @@"`
+	FunDec     []*FuncDec   `parser:"| (?! 'if' | 'for' | 'switch' | 'while' | 'until' | 'try' | 'catch' | 'return' | 'yield' | 'go' | 'defer' ) (?= ( '(' Ident Ident ')' )? Ident '(' ( Ident ( ',' Ident )* )? ')' '{' ) @@"`
+	Statements []*Statement `parser:"| @@ )*"`
+	Script     *Script      // The declarations including any included scripts, set by the parser
+}
+
+// Main returns the declarations in the entry as a Script whose main(args) function runs its statements,
+// e.g. for a script given on the command line.
+func (in *Input) Main() *Script {
+	s := *in.Script
+	s.FunDec = append([]*FuncDec{{
+		Pos:        in.Pos,
+		Name:       "main",
+		Parameters: []string{"args"},
+		FunBody:    &Statements{Pos: in.Pos, Statements: in.Statements},
+	}}, s.FunDec...)
+	return &s
+}
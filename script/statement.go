@@ -5,7 +5,8 @@ import (
 )
 
 type Statements struct {
-	Pos lexer.Position
+	Pos    lexer.Position
+	EndPos lexer.Position // Position of the token following the closing brace
 
 	Statements []*Statement `parser:"'{' @@* '}'"`
 }
@@ -16,14 +17,18 @@ type Statement struct {
 
 	Break    bool      `parser:"  @'break'"`
 	Continue bool      `parser:"| @'continue'"`
+	Defer    *Defer    `parser:"| @@"`
 	DoWhile  *DoWhile  `parser:"| @@"`
 	IfStmt   *If       `parser:"| @@"`
+	ForRange *ForRange `parser:"| @@"` // Must be before For
 	For      *For      `parser:"| @@"`
-	ForRange *ForRange `parser:"| @@"`
+	Go       *Go       `parser:"| @@"`
 	Repeat   *Repeat   `parser:"| @@"`
 	Return   *Return   `parser:"| @@"`
+	Select   *Select   `parser:"| @@"`
 	Switch   *Switch   `parser:"| @@"`
 	While    *While    `parser:"| @@"`
+	Yield    *Yield    `parser:"| @@"`
 
 	// Try is after the main block as it's a bit more complex,
 	// so it's better to place it here after the statements
@@ -0,0 +1,25 @@
+package state
+
+import (
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/script"
+)
+
+func (s *state) declareEnum(en *script.EnumDec) error {
+	if e, exists := s.enums[en.Name]; exists {
+		return errors.Errorf(en.Pos, "enum %q already defined at %s", en.Name, e.Pos.String())
+	}
+	if t, exists := s.types[en.Name]; exists {
+		return errors.Errorf(en.Pos, "enum %q already defined as a type at %s", en.Name, t.Pos.String())
+	}
+
+	for _, m := range en.Members {
+		if e, exists := s.enumMembers[m]; exists {
+			return errors.Errorf(en.Pos, "enum member %q already defined in %s", m, e.Enum().Name)
+		}
+		s.enumMembers[m], _ = en.Value(m)
+	}
+
+	s.enums[en.Name] = en
+	return nil
+}
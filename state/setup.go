@@ -10,16 +10,38 @@ import (
 )
 
 func (s *state) setup() error {
-	for _, i := range s.script.Import {
+	return s.declare(s.script, false)
+}
+
+func (s *state) Load(sc *script.Script) error {
+	return s.declare(sc, true)
+}
+
+// declare adds the declarations in a script.
+// If replace is true then functions, methods and imports replace any existing ones with the same name.
+func (s *state) declare(sc *script.Script, replace bool) error {
+	for _, i := range sc.Import {
 		for _, p := range i.Packages {
-			if err := s.importPackage(p); err != nil {
+			if err := s.importPackage(p, replace); err != nil {
 				return err
 			}
 		}
 	}
 
-	for _, f := range s.script.FunDec {
-		if err := s.declareFunction(f); err != nil {
+	for _, t := range sc.TypeDec {
+		if err := s.declareType(t); err != nil {
+			return err
+		}
+	}
+
+	for _, en := range sc.EnumDec {
+		if err := s.declareEnum(en); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range sc.FunDec {
+		if err := s.declareFunction(f, replace); err != nil {
 			return err
 		}
 	}
@@ -27,7 +49,53 @@ func (s *state) setup() error {
 	return nil
 }
 
-func (s *state) declareFunction(f *script.FuncDec) error {
+func (s *state) declareType(t *script.TypeDec) error {
+	if e, exists := s.types[t.Name]; exists {
+		return errors.Errorf(t.Pos, "type %q already defined at %s", t.Name, e.Pos.String())
+	}
+
+	for i, f := range t.Fields {
+		for _, f1 := range t.Fields[:i] {
+			if f == f1 {
+				return errors.Errorf(t.Pos, "duplicate field %q in type %q", f, t.Name)
+			}
+		}
+	}
+
+	s.types[t.Name] = t
+	s.methods[t.Name] = make(map[string]*script.FuncDec)
+	return nil
+}
+
+// declareMethod adds a function with a receiver to the type it is declared against
+func (s *state) declareMethod(f *script.FuncDec, replace bool) error {
+	t, exists := s.types[f.Receiver.Type]
+	if !exists {
+		return errors.Errorf(f.Receiver.Pos, "type %q not defined", f.Receiver.Type)
+	}
+
+	if t.HasField(f.Name) {
+		return errors.Errorf(f.Pos, "type %q has both field and method named %q", t.Name, f.Name)
+	}
+
+	methods := s.methods[t.Name]
+	if e, exists := methods[f.Name]; exists && !replace {
+		return errors.Errorf(f.Pos, "method %s.%s already defined at %s", t.Name, f.Name, e.Pos.String())
+	}
+
+	methods[f.Name] = f
+	return nil
+}
+
+func (s *state) declareFunction(f *script.FuncDec, replace bool) error {
+	if f.Receiver != nil {
+		return s.declareMethod(f, replace)
+	}
+
+	if t, exists := s.types[f.Name]; exists {
+		return errors.Errorf(f.Pos, "function %q already defined as a type at %s", f.Name, t.Pos.String())
+	}
+
 	// If function name starts with _ then it's local so prefix with the filename
 	// so that it's not accessible from outside its own file.
 	//
@@ -43,29 +111,30 @@ func (s *state) declareFunction(f *script.FuncDec) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if e, exists := s.functions[name]; exists {
+	if e, exists := s.functions[name]; exists && !replace {
 		return fmt.Errorf("%s function %q already defined at %s", f.Pos.String(), f.Name, e.Pos.String())
 	}
 	s.functions[name] = f
 	return nil
 }
 
-func (s *state) importPackage(p *script.ImportPackage) error {
+func (s *state) importPackage(p *script.ImportPackage, replace bool) error {
 	pkg, exists := packages.Lookup(p.Name)
 	if !exists {
 		return errors.Errorf(p.Pos, "package %q is not available", p.Name)
 	}
 
-	if p.As == "" {
-		p.As = path.Base(p.Name)
-		if strings.ContainsAny(p.As, " /.") {
-			return errors.Errorf(p.Pos, "package %q contains invalid characters", p.As)
+	as := p.As
+	if as == "" {
+		as = path.Base(p.Name)
+		if strings.ContainsAny(as, " /.") {
+			return errors.Errorf(p.Pos, "package %q contains invalid characters", as)
 		}
 	}
 
-	key := p.Pos.Filename + "!" + p.As
-	if _, ok := s.packages[key]; ok {
-		return errors.Errorf(p.Pos, "package %q %q has already been imported", p.As, p.Name)
+	key := p.Pos.Filename + "!" + as
+	if _, ok := s.packages[key]; ok && !replace {
+		return errors.Errorf(p.Pos, "package %q %q has already been imported", as, p.Name)
 	}
 
 	s.packages[key] = pkg
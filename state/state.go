@@ -19,25 +19,56 @@ type State interface {
 	// GetFunctions returns a list of declared functions
 	GetFunctions() []string
 
+	// GetType returns a type declared within the script by name
+	GetType(n string) (*script.TypeDec, bool)
+
+	// GetMethods returns the methods declared with a type as their receiver, by name
+	GetMethods(typeName string) map[string]*script.FuncDec
+
+	// Scope returns the current variable scope
+	Scope() Variables
+
+	// SetScope replaces the current variable scope, returning the previous one
+	SetScope(scope Variables) Variables
+
 	// SetFunction sets the current FuncDec in use, returning the previous one
 	SetFunction(currentFunction *script.FuncDec) *script.FuncDec
+
+	// Load adds the declarations in another script, e.g. an entry in an interactive session.
+	// Functions, methods and imports replace any already declared with the same name,
+	// but types and enums cannot be redeclared.
+	Load(s *script.Script) error
+
+	// Fork returns a State for use by another goroutine.
+	// It shares the declarations and global variables of this State but has its own scope chain,
+	// starting at the global scope.
+	Fork() State
 }
 
 type state struct {
-	mutex           sync.Mutex
-	script          *script.Script             // The script being executed, with included scripts
-	functions       map[string]*script.FuncDec // The declared functions in all scripts
-	variables       Variables                  // The current variable scope
-	packages        map[string]any             // Imported packages
-	currentFunction *script.FuncDec            // The function currently being executed
+	mutex           *sync.Mutex                           // Shared with any forks
+	script          *script.Script                        // The script being executed, with included scripts
+	functions       map[string]*script.FuncDec            // The declared functions in all scripts
+	types           map[string]*script.TypeDec            // The declared types in all scripts
+	methods         map[string]map[string]*script.FuncDec // The methods of each declared type
+	enums           map[string]*script.EnumDec            // The declared enums in all scripts
+	enumMembers     map[string]script.EnumValue           // The members of all enums by name
+	variables       Variables                             // The current variable scope
+	packages        map[string]any                        // Imported packages
+	currentFunction *script.FuncDec                       // The function currently being executed
 }
 
 func New(s *script.Script) (State, error) {
 	state := &state{
-		script:    s,
-		functions: make(map[string]*script.FuncDec),
-		variables: NewVariables(),
-		packages:  make(map[string]any),
+		mutex:       &sync.Mutex{},
+		script:      s,
+		functions:   make(map[string]*script.FuncDec),
+		types:       make(map[string]*script.TypeDec),
+		methods:     make(map[string]map[string]*script.FuncDec),
+		enums:       make(map[string]*script.EnumDec),
+		enumMembers: make(map[string]script.EnumValue),
+		variables:   NewVariables(),
+		packages:    make(map[string]any),
 	}
 	return state, state.setup()
 }
@@ -62,6 +93,21 @@ func (s *state) GetFunction(pos lexer.Position, n string) (*script.FuncDec, bool
 	return e, exists
 }
 
+func (s *state) GetType(n string) (*script.TypeDec, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, exists := s.types[n]
+	return t, exists
+}
+
+func (s *state) GetMethods(typeName string) map[string]*script.FuncDec {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.methods[typeName]
+}
+
 func (s *state) getFunctions() []string {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -96,6 +142,16 @@ func (s *state) EndScope() Variables {
 	return s
 }
 
+func (s *state) Scope() Variables {
+	return s.variables
+}
+
+func (s *state) SetScope(scope Variables) Variables {
+	old := s.variables
+	s.variables = scope
+	return old
+}
+
 func (s *state) GlobalScope() Variables {
 	return s.variables.GlobalScope()
 }
@@ -105,6 +161,14 @@ func (s *state) Get(n string) (interface{}, bool) {
 		return v, true
 	}
 
+	// Enums and their members
+	if en, exists := s.enums[n]; exists {
+		return en, true
+	}
+	if m, exists := s.enumMembers[n]; exists {
+		return m, true
+	}
+
 	// Lookup locally imported packages
 	localName := s.currentFunction.Pos.Filename + "!" + n
 	if pkg, exists := s.packages[localName]; exists {
@@ -127,8 +191,22 @@ func (s *state) Set(n string, v interface{}) bool {
 	return s.variables.Set(n, v)
 }
 
+func (s *state) Names() []string {
+	return s.variables.Names()
+}
+
+func (s *state) Parent() Variables {
+	return s.variables.Parent()
+}
+
 func (s *state) SetFunction(currentFunction *script.FuncDec) *script.FuncDec {
 	old := s.currentFunction
 	s.currentFunction = currentFunction
 	return old
 }
+
+func (s *state) Fork() State {
+	f := *s
+	f.variables = s.variables.GlobalScope()
+	return &f
+}
@@ -1,5 +1,10 @@
 package state
 
+import (
+	"sort"
+	"sync"
+)
+
 type Variables interface {
 	// NewScope creates a new Variables scope
 	NewScope() Variables
@@ -20,12 +25,23 @@ type Variables interface {
 	Set(n string, val interface{}) bool
 	// Get returns the variable, checking parent scopes until it finds it.
 	Get(string) (interface{}, bool)
+	// Names returns the names of the variables declared in this scope, in sorted order.
+	// Parent scopes are not included.
+	Names() []string
+	// Parent returns the scope variables are looked up in when they are not declared in this one,
+	// nil for a global scope. For the root scope of a function this is the global scope.
+	Parent() Variables
 }
 
+// variables is a single scope.
+//
+// Access is guarded by a mutex as the global scope is shared by any goroutines started by the script.
 type variables struct {
+	mutex       sync.RWMutex
 	parent      *variables // If not nil then parent scope for variable resolving
 	trueParent  *variables // Actual parent when ending a scope. nil for global
 	globalScope *variables // Pointer to the root global scope
+	shared      *variables // If not nil, read-only variables visible from this global scope
 	vars        map[string]interface{}
 }
 
@@ -33,6 +49,16 @@ func NewVariables() Variables {
 	return newVariables(nil, nil)
 }
 
+// NewSharedVariables returns a new global scope which can see the variables in shared but cannot change them.
+// Setting a variable declared in shared instead declares it within the new scope, leaving shared unchanged.
+//
+// This allows the same globals to be used by more than one script at the same time.
+func NewSharedVariables(shared Variables) Variables {
+	v := newVariables(nil, nil).(*variables)
+	v.shared = shared.(*variables)
+	return v
+}
+
 func newVariables(parent, trueParent *variables) Variables {
 	v := &variables{
 		parent:     parent,
@@ -70,19 +96,31 @@ func (v *variables) GlobalScope() Variables {
 
 func (v *variables) Get(n string) (interface{}, bool) {
 	if IsValidVariable(n) {
-		if r, exists := v.vars[n]; exists {
+		v.mutex.RLock()
+		r, exists := v.vars[n]
+		v.mutex.RUnlock()
+		if exists {
 			return r, true
 		}
 		if v.parent != nil {
 			return v.parent.Get(n)
 		}
+		if v.shared != nil {
+			return v.shared.Get(n)
+		}
 	}
 	return nil, false
 }
 
 func (v *variables) Set(n string, val interface{}) bool {
-	if _, exists := v.vars[n]; exists {
+	v.mutex.Lock()
+	_, exists := v.vars[n]
+	if exists {
 		v.vars[n] = val
+	}
+	v.mutex.Unlock()
+
+	if exists {
 		return true
 	}
 
@@ -91,13 +129,43 @@ func (v *variables) Set(n string, val interface{}) bool {
 		return v.parent.Set(n, val)
 	}
 
+	// Shared variables are copied into this scope rather than changed
+	if v.shared != nil {
+		if _, exists := v.shared.Get(n); exists {
+			v.Declare(n)
+			return v.Set(n, val)
+		}
+	}
+
 	return false
 }
 
 func (v *variables) Declare(n string) {
 	if IsValidVariable(n) {
+		v.mutex.Lock()
 		v.vars[n] = nil
+		v.mutex.Unlock()
+	}
+}
+
+func (v *variables) Names() []string {
+	v.mutex.RLock()
+	var r []string
+	for n := range v.vars {
+		r = append(r, n)
+	}
+	v.mutex.RUnlock()
+
+	sort.Strings(r)
+	return r
+}
+
+func (v *variables) Parent() Variables {
+	// Return an untyped nil rather than a nil *variables
+	if v.parent == nil {
+		return nil
 	}
+	return v.parent
 }
 
 func IsValidVariable(n string) bool {
@@ -0,0 +1,62 @@
+package stdlib
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/script"
+	"reflect"
+)
+
+// _chan implements chan() which returns an unbuffered channel, or chan(n) for one with a buffer of n values
+func _chan(e executor.Executor, call *script.CallFunc) error {
+	arg, err := executor.Args(e, call)
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	switch len(arg) {
+	case 0:
+	case 1:
+		size, err = calculator.GetInt(arg[0])
+		if err != nil {
+			return errors.Error(call.Pos, err)
+		}
+		if size < 0 {
+			return errors.Errorf(call.Pos, "negative buffer size %d", size)
+		}
+	default:
+		return fmt.Errorf("chan([size])")
+	}
+
+	e.Calculator().Push(make(chan interface{}, size))
+	return nil
+}
+
+// _close implements close(ch) which closes a channel
+func _close(e executor.Executor, call *script.CallFunc) (err error) {
+	arg, err := executor.Args(e, call)
+	if err != nil {
+		return err
+	}
+	if len(arg) != 1 {
+		return fmt.Errorf("close(channel)")
+	}
+
+	cv := reflect.ValueOf(arg[0])
+	if cv.Kind() != reflect.Chan {
+		return errors.Errorf(call.Pos, "%T is not a channel", arg[0])
+	}
+
+	// Closing a closed channel panics so convert that to a normal error
+	defer func() {
+		if err1 := recover(); err1 != nil {
+			err = errors.Errorf(call.Pos, "%v", err1)
+		}
+	}()
+
+	cv.Close()
+	return nil
+}
@@ -93,3 +93,28 @@ func _notNull(e executor.Executor, call *script.CallFunc) error {
 
 	return errors.Error(call.Pos, err)
 }
+
+// _exit implements exit([code]) which ends the script with a status code, 0 if not provided.
+// This cannot be caught by try.
+func _exit(e executor.Executor, call *script.CallFunc) error {
+	a, err := executor.Args(e, call)
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+
+	code := 0
+	switch len(a) {
+	case 0:
+
+	case 1:
+		code, err = calculator.GetInt(a[0])
+		if err != nil {
+			return errors.Error(call.Pos, err)
+		}
+
+	default:
+		return fmt.Errorf("exit([code])")
+	}
+
+	return errors.Exit(code)
+}
@@ -4,13 +4,28 @@ import "github.com/peter-mount/go-script/executor"
 
 func init() {
 	executor.Register("append", executor.FuncDelegate(_append))
+	executor.Register("bool", _bool)
+	executor.Register("bytes", _bytes)
+	executor.Register("chan", _chan)
+	executor.Register("close", _close)
+	executor.Register("exit", _exit)
+	executor.Register("fields", _fields)
+	executor.Register("float", _float)
+	executor.Register("int", _int)
+	executor.Register("isArray", _isArray)
+	executor.Register("isMap", _isMap)
 	executor.Register("isNull", _isNull)
+	executor.Register("isNumber", _isNumber)
+	executor.Register("isString", _isString)
 	executor.Register("len", _len)
 	executor.Register("map", _map)
 	executor.Register("mapContains", _mapContains)
+	executor.Register("methods", _methods)
 	executor.Register("newArray", _newArray)
 	executor.Register("notNull", _notNull)
 	executor.Register("print", _print)
 	executor.Register("println", _println)
+	executor.Register("string", _string)
 	executor.Register("throw", _throw)
+	executor.Register("typeof", _typeof)
 }
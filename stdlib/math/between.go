@@ -39,10 +39,11 @@ func _between(e executor.Executor, call *script.CallFunc) error {
 	if err != nil {
 		return errors.Error(call.Pos, err)
 	}
-	if ok {
-		return errors.NewReturn(result)
+	if !ok {
+		// No result so return false
+		result = false
 	}
 
-	// No result so return false
-	return errors.NewReturn(false)
+	calc.Push(result)
+	return nil
 }
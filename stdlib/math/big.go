@@ -0,0 +1,38 @@
+package math
+
+import (
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/packages"
+	"math/big"
+)
+
+func init() {
+	packages.Register("big", &Big{})
+}
+
+// Big exposes the arbitrary precision numbers of the standard math/big package to scripts.
+//
+// These work with all the operators, e.g. big.Int("123456789012345678901234567890") * 2,
+// and mixing a big number with any other number returns a big number.
+type Big struct{}
+
+// Int returns v as a big.Int. If v is a string it can have a prefix, e.g. "0x" for hex.
+func (_ Big) Int(v any) (*big.Int, error) { return calculator.GetBigInt(v) }
+
+// Rat returns v as a big.Rat. If v is a string it can be a fraction, e.g. "1/3", or a decimal, e.g. "0.1".
+func (_ Big) Rat(v any) (*big.Rat, error) { return calculator.GetBigRat(v) }
+
+// NewRat returns the fraction a/b as a big.Rat
+func (_ Big) NewRat(a, b int64) *big.Rat { return big.NewRat(a, b) }
+
+// Float returns v as a big.Float
+func (_ Big) Float(v any) (*big.Float, error) { return calculator.GetBigFloat(v) }
+
+// FloatPrec returns v as a big.Float with prec bits of precision in the mantissa
+func (_ Big) FloatPrec(v any, prec uint) (*big.Float, error) {
+	f, err := calculator.GetBigFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	return f.SetPrec(prec), nil
+}
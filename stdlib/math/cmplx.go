@@ -0,0 +1,101 @@
+package math
+
+import (
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/packages"
+	"github.com/peter-mount/go-script/script"
+	"math/cmplx"
+)
+
+func init() {
+	packages.Register("cmplx", &Cmplx{})
+
+	executor.Register("complex", _complex)
+	executor.Register("real", _real)
+	executor.Register("imag", _imag)
+}
+
+// Cmplx exposes functions from the standard math/cmplx package to scripts
+type Cmplx struct{}
+
+func (_ Cmplx) Abs(a complex128) float64              { return cmplx.Abs(a) }
+func (_ Cmplx) Acos(a complex128) complex128          { return cmplx.Acos(a) }
+func (_ Cmplx) Acosh(a complex128) complex128         { return cmplx.Acosh(a) }
+func (_ Cmplx) Asin(a complex128) complex128          { return cmplx.Asin(a) }
+func (_ Cmplx) Asinh(a complex128) complex128         { return cmplx.Asinh(a) }
+func (_ Cmplx) Atan(a complex128) complex128          { return cmplx.Atan(a) }
+func (_ Cmplx) Atanh(a complex128) complex128         { return cmplx.Atanh(a) }
+func (_ Cmplx) Conj(a complex128) complex128          { return cmplx.Conj(a) }
+func (_ Cmplx) Cos(a complex128) complex128           { return cmplx.Cos(a) }
+func (_ Cmplx) Cosh(a complex128) complex128          { return cmplx.Cosh(a) }
+func (_ Cmplx) Cot(a complex128) complex128           { return cmplx.Cot(a) }
+func (_ Cmplx) Exp(a complex128) complex128           { return cmplx.Exp(a) }
+func (_ Cmplx) Inf() complex128                       { return cmplx.Inf() }
+func (_ Cmplx) IsInf(a complex128) bool               { return cmplx.IsInf(a) }
+func (_ Cmplx) IsNaN(a complex128) bool               { return cmplx.IsNaN(a) }
+func (_ Cmplx) Log(a complex128) complex128           { return cmplx.Log(a) }
+func (_ Cmplx) Log10(a complex128) complex128         { return cmplx.Log10(a) }
+func (_ Cmplx) NaN() complex128                       { return cmplx.NaN() }
+func (_ Cmplx) Phase(a complex128) float64            { return cmplx.Phase(a) }
+func (_ Cmplx) Polar(a complex128) (float64, float64) { return cmplx.Polar(a) }
+func (_ Cmplx) Pow(a, b complex128) complex128        { return cmplx.Pow(a, b) }
+func (_ Cmplx) Rect(r, theta float64) complex128      { return cmplx.Rect(r, theta) }
+func (_ Cmplx) Sin(a complex128) complex128           { return cmplx.Sin(a) }
+func (_ Cmplx) Sinh(a complex128) complex128          { return cmplx.Sinh(a) }
+func (_ Cmplx) Sqrt(a complex128) complex128          { return cmplx.Sqrt(a) }
+func (_ Cmplx) Tan(a complex128) complex128           { return cmplx.Tan(a) }
+func (_ Cmplx) Tanh(a complex128) complex128          { return cmplx.Tanh(a) }
+
+// _complex implements the go builtin complex(r, i)
+func _complex(e executor.Executor, call *script.CallFunc) error {
+	arg, err := executor.Args(e, call)
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+	if len(arg) != 2 {
+		return errors.Errorf(call.Pos, "complex(r, i)")
+	}
+
+	r, err := calculator.GetFloat(arg[0])
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+
+	i, err := calculator.GetFloat(arg[1])
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+
+	e.Calculator().Push(complex(r, i))
+	return nil
+}
+
+// _real implements the go builtin real(c)
+func _real(e executor.Executor, call *script.CallFunc) error {
+	return complexPart(e, call, "real(c)", func(c complex128) float64 { return real(c) })
+}
+
+// _imag implements the go builtin imag(c)
+func _imag(e executor.Executor, call *script.CallFunc) error {
+	return complexPart(e, call, "imag(c)", func(c complex128) float64 { return imag(c) })
+}
+
+func complexPart(e executor.Executor, call *script.CallFunc, usage string, f func(complex128) float64) error {
+	arg, err := executor.Args(e, call)
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+	if len(arg) != 1 {
+		return errors.Errorf(call.Pos, "%s", usage)
+	}
+
+	c, err := calculator.GetComplex(arg[0])
+	if err != nil {
+		return errors.Error(call.Pos, err)
+	}
+
+	e.Calculator().Push(f(c))
+	return nil
+}
@@ -28,5 +28,6 @@ func _len(e executor.Executor, call *script.CallFunc) (err error) {
 
 	tv := reflect.ValueOf(arg[0])
 	ti := reflect.Indirect(tv)
-	return errors.NewReturn(ti.Len())
+	e.Calculator().Push(ti.Len())
+	return nil
 }
@@ -0,0 +1,37 @@
+package sync
+
+import (
+	"github.com/peter-mount/go-script/packages"
+	"sync"
+)
+
+func init() {
+	packages.Register("sync", &Sync{})
+}
+
+// Sync provides the synchronisation primitives for scripts which use go statements, e.g.
+//
+//	wg := sync.WaitGroup()
+//	for i := 0; i < 3; i++ {
+//	  wg.Add(1)
+//	  go worker(wg, i)
+//	}
+//	wg.Wait()
+//
+// As these are pointers they can be passed to functions and stored in variables.
+type Sync struct{}
+
+// WaitGroup returns a new sync.WaitGroup, used to wait for a collection of goroutines to finish
+func (_ Sync) WaitGroup() *sync.WaitGroup {
+	return &sync.WaitGroup{}
+}
+
+// Mutex returns a new sync.Mutex, used to guard values shared between goroutines
+func (_ Sync) Mutex() *sync.Mutex {
+	return &sync.Mutex{}
+}
+
+// RWMutex returns a new sync.RWMutex, a Mutex which can be held by any number of readers or a single writer
+func (_ Sync) RWMutex() *sync.RWMutex {
+	return &sync.RWMutex{}
+}
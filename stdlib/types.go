@@ -0,0 +1,145 @@
+package stdlib
+
+import (
+	"fmt"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/script"
+	"reflect"
+	"sort"
+)
+
+// Type conversion builtins, which use the same conversions as when calling go functions
+var (
+	_int    = oneArg("int(v)", func(v interface{}) (interface{}, error) { return calculator.GetInt(v) })
+	_float  = oneArg("float(v)", func(v interface{}) (interface{}, error) { return calculator.GetFloat(v) })
+	_string = oneArg("string(v)", func(v interface{}) (interface{}, error) { return calculator.GetString(v) })
+	_bool   = oneArg("bool(v)", func(v interface{}) (interface{}, error) { return calculator.GetBool(v) })
+	_bytes  = oneArg("bytes(v)", bytes)
+)
+
+// Type introspection builtins
+var (
+	_typeof   = oneArg("typeof(v)", typeOf)
+	_isArray  = isKind("isArray(v)", reflect.Array, reflect.Slice)
+	_isMap    = isKind("isMap(v)", reflect.Map)
+	_isString = isKind("isString(v)", reflect.String)
+	_isNumber = oneArg("isNumber(v)", func(v interface{}) (interface{}, error) { return calculator.IsNumber(v), nil })
+	_fields   = oneArg("fields(v)", fields)
+	_methods  = oneArg("methods(v)", methods)
+)
+
+// oneArg returns a Function which calls f with its single argument and returns the result.
+// usage is returned as the error if not called with one argument.
+func oneArg(usage string, f func(v interface{}) (interface{}, error)) executor.Function {
+	return func(e executor.Executor, call *script.CallFunc) error {
+		a, err := executor.Args(e, call)
+		if err == nil && len(a) != 1 {
+			err = errors.Errorf(call.Pos, "%s", usage)
+		}
+
+		var r interface{}
+		if err == nil {
+			r, err = f(a[0])
+		}
+		if err != nil {
+			return errors.Error(call.Pos, err)
+		}
+
+		e.Calculator().Push(r)
+		return nil
+	}
+}
+
+// isKind returns a Function which returns true if its argument, or what it points to, is one of kinds
+func isKind(usage string, kinds ...reflect.Kind) executor.Function {
+	return oneArg(usage, func(v interface{}) (interface{}, error) {
+		k := reflect.Indirect(reflect.ValueOf(v)).Kind()
+		for _, kind := range kinds {
+			if k == kind {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// typeOf implements typeof(v) which returns the go type of v, e.g. "int" or "[]string", or "null".
+// For a record or enum the name of the type declared in the script is returned.
+func typeOf(v interface{}) (interface{}, error) {
+	if v == nil {
+		return "null", nil
+	}
+	switch t := v.(type) {
+	case *executor.Record:
+		return t.TypeName(), nil
+	case script.EnumValue:
+		return t.Enum().Name, nil
+	}
+	return reflect.TypeOf(v).String(), nil
+}
+
+// bytes implements bytes(v) which returns v as a []byte.
+// A []byte is returned unchanged, anything else is converted to a string first.
+func bytes(v interface{}) (interface{}, error) {
+	if b, ok := v.([]byte); ok {
+		return b, nil
+	}
+
+	s, err := calculator.GetString(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// fields implements fields(v) which returns the names of the exported fields of a struct or record
+// in the order they are declared, or the sorted keys of a map.
+func fields(v interface{}) (interface{}, error) {
+	if r, ok := v.(*executor.Record); ok {
+		return append([]string(nil), r.Type().Fields...), nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	var names []string
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, f := range reflect.VisibleFields(rv.Type()) {
+			if f.IsExported() {
+				names = append(names, f.Name)
+			}
+		}
+
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			names = append(names, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(names)
+
+	default:
+		return nil, fmt.Errorf("fields(v) requires a struct or map, got %T", v)
+	}
+
+	return names, nil
+}
+
+// methods implements methods(v) which returns the sorted names of the exported methods of v,
+// or for a record the methods declared against its type.
+func methods(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, fmt.Errorf("methods(v) requires a value, got null")
+	}
+
+	if r, ok := v.(*executor.Record); ok {
+		return r.MethodNames(), nil
+	}
+
+	t := reflect.TypeOf(v)
+	names := make([]string, 0, t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		names = append(names, t.Method(i).Name)
+	}
+	return names, nil
+}
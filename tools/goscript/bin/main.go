@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"github.com/peter-mount/go-kernel/v2"
+	"github.com/peter-mount/go-script/errors"
 	_ "github.com/peter-mount/go-script/stdlib"
 	_ "github.com/peter-mount/go-script/stdlib/fmt"
 	_ "github.com/peter-mount/go-script/stdlib/io"
 	_ "github.com/peter-mount/go-script/stdlib/math"
+	_ "github.com/peter-mount/go-script/stdlib/sync"
 	_ "github.com/peter-mount/go-script/stdlib/time"
 	"github.com/peter-mount/go-script/tools/goscript"
 	"os"
@@ -16,6 +18,11 @@ func main() {
 	if err := kernel.Launch(
 		&goscript.Script{},
 	); err != nil {
+		// The script called exit() or returned a status from main()
+		if exit, ok := errors.GetExit(err); ok {
+			os.Exit(exit.Code())
+		}
+
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -0,0 +1,30 @@
+package goscript
+
+import (
+	"github.com/peter-mount/go-script/dap"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"os"
+)
+
+// debug implements "goscript debug", running a Debug Adapter Protocol server on stdin and stdout.
+//
+// The script is named by the client's launch request. Anything it prints is sent to the client
+// as output events, so it does not get mixed up with the protocol.
+func debug(p parser.Parser, opts []executor.Option) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = out
+		_ = w.Close()
+	}()
+
+	s := dap.New(p, opts...)
+	s.Output("stdout", r)
+	return s.Serve(os.Stdin, out)
+}
@@ -0,0 +1,78 @@
+package goscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"github.com/peter-mount/go-script/script"
+)
+
+// eval runs the statements passed with -e as the body of an implicit main(), e.g.
+//
+//	goscript -e 'println(math.Sqrt(2))'
+//
+// Functions, types and imports can also be declared before the statements.
+func (b *Script) eval(p parser.Parser, opts []executor.Option) error {
+	in, err := p.ParseInput("-e", *b.Eval)
+	if err != nil {
+		return err
+	}
+
+	return run(in.Main(), opts)
+}
+
+// expr evaluates the expression passed with -expr, printing its result in the format set by -format, e.g.
+//
+//	goscript -expr '2 ** 10'
+//	goscript -format json -expr '"a" + "b"'
+func (b *Script) expr(p parser.Parser, opts []executor.Option) error {
+	if *b.Format != "text" && *b.Format != "json" {
+		return fmt.Errorf("unsupported format %q", *b.Format)
+	}
+
+	in, err := p.ParseInput("-expr", *b.Expr)
+	if err != nil {
+		return err
+	}
+
+	if !isExpression(in) {
+		return fmt.Errorf("-expr requires a single expression")
+	}
+
+	exec, err := executor.New(&script.Script{}, opts...)
+	if err != nil {
+		return err
+	}
+
+	v, _, err := exec.Evaluate(in)
+	if err != nil {
+		return err
+	}
+
+	if *b.Format == "json" {
+		out, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Println(string(out))
+		return err
+	}
+
+	if v == nil {
+		v = "null"
+	}
+	_, err = fmt.Println(v)
+	return err
+}
+
+// isExpression returns true if the input is a single expression which is not an assignment
+func isExpression(in *script.Input) bool {
+	s := in.Script
+	if len(s.Import) > 0 || len(s.TypeDec) > 0 || len(s.EnumDec) > 0 || len(s.FunDec) > 0 || len(in.Statements) != 1 {
+		return false
+	}
+
+	e := in.Statements[0].Expression
+	return e != nil && e.Right != nil && e.Right.Op == ""
+}
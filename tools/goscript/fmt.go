@@ -0,0 +1,69 @@
+package goscript
+
+import (
+	"bytes"
+	"flag"
+	"github.com/peter-mount/go-script/parser"
+	"io"
+	"os"
+)
+
+// format implements "goscript fmt [-w] [files]", writing each script in its canonical format to stdout
+// or, with -w, back to the file if it has changed. With no files the script is read from stdin.
+func format(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := fs.Bool("w", false, "Write the result to the file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		out, err := parser.Format("stdin", src)
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	for _, fileName := range fs.Args() {
+		if err := formatFile(fileName, *write); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatFile(fileName string, write bool) error {
+	src, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	out, err := parser.Format(fileName, src)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	if bytes.Equal(src, out) {
+		return nil
+	}
+
+	fi, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, out, fi.Mode().Perm())
+}
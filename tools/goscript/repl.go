@@ -0,0 +1,174 @@
+package goscript
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/peter-mount/go-script/errors"
+	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/parser"
+	"io"
+	"strings"
+)
+
+const (
+	replFileName = "repl" // File name used for entries, so positions show they came from the session
+	replPrompt   = "> "
+	replContinue = "... "
+	replHelp     = `Enter statements, expressions, function, type and enum declarations or imports.
+Input continues over multiple lines until all brackets and braces are closed.
+
+Commands:
+  :funcs        list the declared functions
+  :vars         list the global variables
+  :load file    load the declarations in a script
+  :help         show this help
+  :quit         end the session
+`
+)
+
+// repl is an interactive session.
+//
+// Each entry is run as soon as it is complete, and the value of a final expression is printed.
+// Declarations and global variables are kept between entries.
+type repl struct {
+	parser parser.Parser
+	exec   executor.Executor
+	out    io.Writer
+}
+
+func newRepl(p parser.Parser, exec executor.Executor, out io.Writer) *repl {
+	return &repl{parser: p, exec: exec, out: out}
+}
+
+// run reads entries from in until it ends or :quit is entered
+func (r *repl) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	var entry []string
+
+	r.printf(replPrompt)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if len(entry) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if !r.command(strings.Fields(line)) {
+				return nil
+			}
+			r.printf(replPrompt)
+			continue
+		}
+
+		entry = append(entry, line)
+		src := strings.Join(entry, "\n")
+		if !parser.IsComplete(src) {
+			r.printf(replContinue)
+			continue
+		}
+
+		entry = nil
+		if err := r.evaluate(src); err != nil {
+			return err
+		}
+		r.printf(replPrompt)
+	}
+
+	r.printf("\n")
+	return scanner.Err()
+}
+
+// evaluate runs a complete entry, printing its value or any error.
+// The error returned is the errors.ExitError if the entry called exit(), ending the session.
+func (r *repl) evaluate(src string) error {
+	if strings.TrimSpace(src) == "" {
+		return nil
+	}
+
+	in, err := r.parser.ParseInput(replFileName, src)
+	if err != nil {
+		r.printf("error: %v\n", err)
+		return nil
+	}
+
+	v, ok, err := r.exec.Evaluate(in)
+	switch {
+	case errors.IsExit(err):
+		return err
+	case err != nil:
+		r.printf("error: %v\n", err)
+	case ok:
+		r.printf("%s\n", formatValue(v))
+	}
+	return nil
+}
+
+// command runs a command, returning false if the session should end
+func (r *repl) command(args []string) bool {
+	switch args[0] {
+	case ":quit", ":q":
+		return false
+
+	case ":help":
+		r.printf(replHelp)
+
+	case ":funcs":
+		for _, n := range r.exec.Functions() {
+			// Local functions are stored prefixed with the file they were declared in
+			if i := strings.LastIndex(n, "!"); i >= 0 {
+				n = n[i+1:]
+			}
+			r.printf("%s\n", n)
+		}
+
+	case ":vars":
+		globals := r.exec.GlobalScope()
+		for _, n := range globals.Names() {
+			v, _ := globals.Get(n)
+			r.printf("%s = %s\n", n, formatValue(v))
+		}
+
+	case ":load":
+		if len(args) < 2 {
+			r.printf("error: :load requires a file name\n")
+		}
+		for _, fileName := range args[1:] {
+			if err := r.load(fileName); err != nil {
+				r.printf("error: %v\n", err)
+				break
+			}
+		}
+
+	default:
+		r.printf("unknown command %q, :help lists the commands\n", args[0])
+	}
+
+	return true
+}
+
+// load adds the declarations in a script to the session
+func (r *repl) load(fileName string) error {
+	s, err := r.parser.ParseFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range s.Warnings {
+		r.printf("warning: %v\n", w)
+	}
+
+	return r.exec.Load(s)
+}
+
+func (r *repl) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(r.out, format, args...)
+}
+
+// formatValue returns a value as it would be written in a script
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
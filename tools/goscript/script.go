@@ -1,17 +1,28 @@
 package goscript
 
 import (
-	"errors"
 	"flag"
+	"fmt"
 	"github.com/peter-mount/go-build/application"
 	"github.com/peter-mount/go-build/version"
 	"github.com/peter-mount/go-kernel/v2/log"
+	"github.com/peter-mount/go-script/calculator"
+	"github.com/peter-mount/go-script/errors"
 	"github.com/peter-mount/go-script/executor"
+	"github.com/peter-mount/go-script/lsp"
 	"github.com/peter-mount/go-script/parser"
+	"github.com/peter-mount/go-script/script"
 	"os"
 )
 
 type Script struct {
+	NegativeIndices *bool   `kernel:"flag,negative-index,Allow negative indices to index from the end"`
+	CheckOverflow   *bool   `kernel:"flag,overflow,Fail on integer overflow instead of wrapping around"`
+	StrictFloat     *bool   `kernel:"flag,strict-float,Fail on float division by zero or NaN results"`
+	Interactive     *bool   `kernel:"flag,i,Start an interactive session with the declarations in any scripts provided"`
+	Eval            *string `kernel:"flag,e,Run statements as the body of main() instead of a script"`
+	Expr            *string `kernel:"flag,expr,Evaluate an expression and print its result"`
+	Format          *string `kernel:"flag,format,Format used by -expr to print its result: text or json,text"`
 }
 
 func (b *Script) Run() error {
@@ -22,36 +33,122 @@ func (b *Script) Run() error {
 	p := parser.New()
 
 	// if ../include exists then add it to the path
-	if err := p.IncludePath(application.FileName(application.STATIC, "include")); err != nil && !os.IsNotExist(err) {
-		return err
+	includePath := []string{application.FileName(application.STATIC, "include"), "."}
+	for _, dir := range includePath {
+		if err := p.IncludePath(dir); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
-	if err := p.IncludePath("."); err != nil && !os.IsNotExist(err) {
-		return err
+	// Arguments after -- are passed to the script
+	args, scriptArgs := splitArgs(os.Args, flag.Args())
+
+	opts := []executor.Option{executor.WithArgs(scriptArgs...)}
+	if *b.NegativeIndices {
+		opts = append(opts, executor.WithNegativeIndices())
 	}
+	if *b.CheckOverflow {
+		opts = append(opts, executor.WithCalculatorMode(calculator.CheckOverflow))
+	}
+	if *b.StrictFloat {
+		opts = append(opts, executor.WithCalculatorMode(calculator.StrictFloat))
+	}
+
+	switch {
+	case len(args) > 0 && args[0] == "fmt":
+		return format(args[1:])
+
+	case len(args) > 0 && args[0] == "lsp":
+		return lsp.New(includePath...).Serve(os.Stdin, os.Stdout)
+
+	case len(args) > 0 && args[0] == "debug":
+		return debug(p, opts)
 
-	args := flag.Args()
+	case *b.Eval != "" && *b.Expr != "":
+		return fmt.Errorf("-e and -expr cannot be used together")
 
-	if len(args) == 0 {
-		return errors.New("no scripts provided")
+	case *b.Eval != "":
+		return b.eval(p, opts)
+
+	case *b.Expr != "":
+		return b.expr(p, opts)
+
+	// With no scripts, start an interactive session
+	case *b.Interactive || len(args) == 0:
+		return b.interactive(p, args, opts)
 	}
 
 	for _, fileName := range args {
-		s, err := p.ParseFile(fileName)
+		s, err := parseFile(p, fileName)
 		if err != nil {
 			return err
 		}
 
-		exec, err := executor.New(s)
-		if err != nil {
+		if err := run(s, opts); err != nil {
 			return err
 		}
+	}
 
-		err = exec.Run()
-		if err != nil {
+	return nil
+}
+
+// splitArgs splits the command line at the first "--" into the scripts to run and the arguments to pass to them.
+//
+// If "--" is before any scripts, e.g. goscript -e 'println(args)' -- a b, then the flag package
+// has already removed it, so it is found just before args in the full command line.
+func splitArgs(cmdLine, args []string) ([]string, []string) {
+	if n := len(cmdLine) - len(args); n > 0 && cmdLine[n-1] == "--" {
+		return nil, args
+	}
+
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// parseFile parses a script, reading it from stdin if the file name is "-"
+func parseFile(p parser.Parser, fileName string) (*script.Script, error) {
+	if fileName == "-" {
+		return p.Parse("stdin", os.Stdin)
+	}
+	return p.ParseFile(fileName)
+}
+
+// run executes a parsed script, reporting any warnings to stderr.
+// A non-zero exit status from the script is returned as an errors.ExitError.
+func run(s *script.Script, opts []executor.Option) error {
+	exec, err := executor.New(s, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range exec.Warnings() {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", w)
+	}
+
+	code, err := exec.RunStatus()
+	if err == nil && code != 0 {
+		err = errors.Exit(code)
+	}
+	return err
+}
+
+// interactive runs a REPL on the console, with the declarations in any scripts already loaded
+func (b *Script) interactive(p parser.Parser, args []string, opts []executor.Option) error {
+	exec, err := executor.New(&script.Script{}, opts...)
+	if err != nil {
+		return err
+	}
+
+	r := newRepl(p, exec, os.Stdout)
+	for _, fileName := range args {
+		if err := r.load(fileName); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return r.run(os.Stdin)
 }